@@ -19,6 +19,7 @@ package accountmanager
 import (
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/common"
@@ -34,6 +35,7 @@ type AssetBalance struct {
 
 type recoverActionResult struct {
 	acctAuthors map[common.Name]*accountAuthor
+	scopes      []*signScope
 }
 
 type accountAuthor struct {
@@ -69,6 +71,7 @@ type Account struct {
 	Suicide               bool             //code Suicide
 	Destroy               bool             //account destroy
 	Description           string
+	AuthorScopes          []*AuthorScope `rlp:"tail"` //sort by owner asc
 	//ChargeRatio           uint64
 }
 
@@ -171,11 +174,15 @@ func (a *Account) GetAuthorVersion() common.Hash {
 
 //SetAuthorVersion set author version
 func (a *Account) SetAuthorVersion() {
-	a.AuthorVersion = types.RlpHash([]interface{}{
+	version := []interface{}{
 		a.Authors,
 		a.Threshold,
 		a.UpdateAuthorThreshold,
-	})
+	}
+	if len(a.AuthorScopes) != 0 {
+		version = append(version, a.AuthorScopes)
+	}
+	a.AuthorVersion = types.RlpHash(version)
 }
 
 //GetCode get code
@@ -242,12 +249,58 @@ func (a *Account) DeleteAuthor(author *common.Author) error {
 	for i, auth := range a.Authors {
 		if author.Owner.String() == auth.Owner.String() {
 			a.Authors = append(a.Authors[:i], a.Authors[i+1:]...)
+			a.DeleteAuthorScope(auth.Owner.String())
 			break
 		}
 	}
 	return nil
 }
 
+// GetAuthorScope returns the scope of the author, nil if the author is unrestricted.
+func (a *Account) GetAuthorScope(owner string) *AuthorScope {
+	for _, scope := range a.AuthorScopes {
+		if scope.Owner == owner {
+			return scope
+		}
+	}
+	return nil
+}
+
+// SetAuthorScope sets the scope of an author, an empty scope removes the restriction.
+func (a *Account) SetAuthorScope(scope *AuthorScope) error {
+	found := false
+	for _, auth := range a.Authors {
+		if auth.Owner.String() == scope.Owner {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("author %s not exist", scope.Owner)
+	}
+	a.DeleteAuthorScope(scope.Owner)
+	if scope.IsEmpty() {
+		return nil
+	}
+	idx := sort.Search(len(a.AuthorScopes), func(i int) bool {
+		return a.AuthorScopes[i].Owner > scope.Owner
+	})
+	a.AuthorScopes = append(a.AuthorScopes, nil)
+	copy(a.AuthorScopes[idx+1:], a.AuthorScopes[idx:])
+	a.AuthorScopes[idx] = scope
+	return nil
+}
+
+// DeleteAuthorScope removes the scope of the author.
+func (a *Account) DeleteAuthorScope(owner string) {
+	for i, scope := range a.AuthorScopes {
+		if scope.Owner == owner {
+			a.AuthorScopes = append(a.AuthorScopes[:i], a.AuthorScopes[i+1:]...)
+			return
+		}
+	}
+}
+
 // GetCodeHash get code hash
 func (a *Account) GetCodeHash() (common.Hash, error) {
 	if len(a.CodeHash) == 0 {
@@ -73,6 +73,7 @@ type AccountAuthorAction struct {
 	Threshold             uint64          `json:"threshold,omitempty"`
 	UpdateAuthorThreshold uint64          `json:"updateAuthorThreshold,omitempty"`
 	AuthorActions         []*AuthorAction `json:"authorActions,omitempty"`
+	AuthorScopes          []*AuthorScope  `json:"authorScopes,omitempty" rlp:"tail"`
 }
 
 type IssueAsset struct {
@@ -108,6 +109,50 @@ type UpdateAssetContract struct {
 	Contract common.Name `json:"contract"`
 }
 
+type FreezeAsset struct {
+	AssetID uint64 `json:"assetId,omitempty"`
+	Frozen  bool   `json:"frozen"`
+}
+
+type FreezeAccountAsset struct {
+	AssetID uint64      `json:"assetId,omitempty"`
+	Account common.Name `json:"account"`
+	Frozen  bool        `json:"frozen"`
+}
+
+type IssueNFTCollection struct {
+	Name        string      `json:"name"`
+	Symbol      string      `json:"symbol"`
+	Owner       common.Name `json:"owner"`
+	Description string      `json:"description"`
+}
+
+type MintNFT struct {
+	CollectionID uint64      `json:"collectionId"`
+	To           common.Name `json:"to"`
+	URI          string      `json:"uri"`
+}
+
+type TransferNFT struct {
+	CollectionID uint64 `json:"collectionId"`
+	TokenID      uint64 `json:"tokenId"`
+}
+
+type BurnNFT struct {
+	CollectionID uint64 `json:"collectionId"`
+	TokenID      uint64 `json:"tokenId"`
+}
+
+type SetAssetMetadata struct {
+	AssetID  uint64            `json:"assetId"`
+	Metadata []*asset.Metadata `json:"metadata"`
+}
+
+type SetSubAssetPolicy struct {
+	AssetID uint64 `json:"assetId"`
+	Policy  uint64 `json:"policy"`
+}
+
 //AccountManager represents account management model.
 type AccountManager struct {
 	sdb *state.StateDB
@@ -417,7 +462,7 @@ func (am *AccountManager) UpdateAccount(accountName common.Name, accountAction *
 	return am.SetAccount(acct)
 }
 
-func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth *AccountAuthorAction) error {
+func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth *AccountAuthorAction, curForkID uint64) error {
 	acct, err := am.GetAccountByName(accountName)
 	if acct == nil {
 		return ErrAccountNotExist
@@ -425,6 +470,14 @@ func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth
 	if err != nil {
 		return err
 	}
+	if err := updateAccountAuthor(acct, acctAuth, curForkID); err != nil {
+		return err
+	}
+	return am.SetAccount(acct)
+}
+
+// updateAccountAuthor applies the author changes to the account object.
+func updateAccountAuthor(acct *Account, acctAuth *AccountAuthorAction, curForkID uint64) error {
 	if acctAuth.Threshold != 0 {
 		acct.SetThreshold(acctAuth.Threshold)
 	}
@@ -444,11 +497,19 @@ func (am *AccountManager) UpdateAccountAuthor(accountName common.Name, acctAuth
 			return fmt.Errorf("invalid account author operation type %d", actionTy)
 		}
 	}
+	if len(acctAuth.AuthorScopes) != 0 && curForkID < params.ForkID5 {
+		return ErrScopeNotSupported
+	}
+	for _, scope := range acctAuth.AuthorScopes {
+		if err := acct.SetAuthorScope(scope); err != nil {
+			return err
+		}
+	}
 	if uint64(len(acct.Authors)) > params.MaxAuthorNum {
 		return fmt.Errorf("account author length can not exceed %d", params.MaxAuthorNum)
 	}
 	acct.SetAuthorVersion()
-	return am.SetAccount(acct)
+	return nil
 }
 
 //GetAccountByTime get account by name and time
@@ -636,7 +697,7 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 		if err != nil {
 			return err
 		}
-		recoverRes := &recoverActionResult{make(map[common.Name]*accountAuthor)}
+		recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor)}
 		for i, pub := range pubs {
 			index := action.GetSignIndex(uint64(i))
 			if uint64(len(index)) > params.MaxSignDepth {
@@ -654,14 +715,21 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 				count += weight
 			}
 			threshold := acctAuthor.threshold
-			if name.String() == signSender.String() && (action.Type() == types.UpdateAccountAuthor || signSender != action.Sender()) {
+			if name.String() == signSender.String() && (action.Type() == types.UpdateAccountAuthor || action.Type() == types.SetAccountAuthorDelay || action.Type() == types.CancelAccountAuthor || signSender != action.Sender()) {
 				threshold = acctAuthor.updateAuthorThreshold
 			}
+			if name.String() == signSender.String() && signSender == action.Sender() && isProposalActionType(action.Type()) {
+				// a single author can propose and approve
+				threshold = 1
+			}
 			if count < threshold {
 				return fmt.Errorf("account %s want threshold %d, but actual is %d", name, threshold, count)
 			}
 			authorVersion[name] = acctAuthor.version
 		}
+		if err := recoverRes.checkScopes(action); err != nil {
+			return err
+		}
 
 		types.StoreAuthorCache(action, authorVersion)
 	}
@@ -685,7 +753,7 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 			if err != nil {
 				return err
 			}
-			recoverRes := &recoverActionResult{make(map[common.Name]*accountAuthor)}
+			recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor)}
 			for i, pub := range pubs {
 				index := sig.SignData[uint64(i)].Index
 				if uint64(len(index)) > params.MaxSignDepth {
@@ -708,6 +776,9 @@ func (am *AccountManager) RecoverTx(signer types.Signer, tx *types.Transaction)
 				}
 				authorVersion[name] = acctAuthor.version
 			}
+			if err := recoverRes.checkScopes(action); err != nil {
+				return err
+			}
 
 			types.StoreAuthorCache(action, authorVersion)
 		}
@@ -730,6 +801,9 @@ func (am *AccountManager) IsValidSign(accountName common.Name, pub common.PubKey
 	//TODO action type verify
 
 	for _, author := range acct.Authors {
+		if acct.GetAuthorScope(author.String()) != nil {
+			continue
+		}
 		if author.String() == pub.String() && author.GetWeight() >= acct.GetThreshold() {
 			return nil
 		}
@@ -777,6 +851,7 @@ func (am *AccountManager) ValidSign(accountName common.Name, pub common.PubKey,
 			} else {
 				recoverRes.acctAuthors[acct.GetName()].indexWeight[idx] = acct.Authors[idx].GetWeight()
 			}
+			recoverRes.addScope(acct, idx)
 			acct = nextacct
 		default:
 			return ErrAccountNotExist
@@ -799,6 +874,7 @@ func (am *AccountManager) ValidOneSign(acct *Account, index uint64, pub common.P
 	default:
 		return fmt.Errorf("wrong sign type")
 	}
+	recoverRes.addScope(acct, index)
 	if recoverRes.acctAuthors[acct.GetName()] == nil {
 		a := &accountAuthor{version: acct.AuthorVersion, threshold: acct.Threshold, updateAuthorThreshold: acct.UpdateAuthorThreshold, indexWeight: map[uint64]uint64{index: acct.Authors[index].GetWeight()}}
 		recoverRes.acctAuthors[acct.GetName()] = a
@@ -814,12 +890,115 @@ func (am *AccountManager) GetAssetInfoByName(assetName string) (*asset.AssetObje
 	if err != nil {
 		return nil, err
 	}
-	return am.ast.GetAssetObjectByID(assetID)
+	return am.GetAssetInfoByID(assetID)
 }
 
 //GetAssetInfoByID get asset info by assetID
 func (am *AccountManager) GetAssetInfoByID(assetID uint64) (*asset.AssetObject, error) {
-	return am.ast.GetAssetObjectByID(assetID)
+	ao, err := am.ast.GetAssetObjectByID(assetID)
+	if err != nil {
+		return nil, err
+	}
+	if ao.Frozen, err = am.ast.IsAssetFrozen(assetID); err != nil {
+		return nil, err
+	}
+	if ao.Metadata, err = am.ast.GetAssetMetadata(assetID); err != nil {
+		return nil, err
+	}
+	return ao, nil
+}
+
+// GetAssetInfos returns at most limit assets accepted by the filter with ids
+// not less than the cursor and the cursor of the following assets, a page may
+// be short while the cursor is not zero.
+func (am *AccountManager) GetAssetInfos(cursor uint64, limit uint64, filter func(*asset.AssetObject) bool) ([]*asset.AssetObject, uint64, error) {
+	assets, next, err := am.ast.GetAssetObjects(cursor, limit, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, ao := range assets {
+		if ao.Frozen, err = am.ast.IsAssetFrozen(ao.AssetID); err != nil {
+			return nil, 0, err
+		}
+		if ao.Metadata, err = am.ast.GetAssetMetadata(ao.AssetID); err != nil {
+			return nil, 0, err
+		}
+	}
+	return assets, next, nil
+}
+
+// GetAssetMetadata returns the metadata of the asset.
+func (am *AccountManager) GetAssetMetadata(assetID uint64) ([]*asset.Metadata, error) {
+	return am.ast.GetAssetMetadata(assetID)
+}
+
+// GetSubAssetPolicy returns the sub-asset policy of the asset.
+func (am *AccountManager) GetSubAssetPolicy(assetID uint64) (uint64, error) {
+	return am.ast.GetSubAssetPolicy(assetID)
+}
+
+// MigrateSubAssetIndex indexes at most limit assets issued before the fork under their parents.
+func (am *AccountManager) MigrateSubAssetIndex(limit uint64) error {
+	return am.ast.MigrateSubAssetIndex(limit)
+}
+
+// InitSubAssetIndex indexes the sub-assets of every asset at once, it is only used for the genesis block.
+func (am *AccountManager) InitSubAssetIndex() error {
+	return am.ast.InitSubAssetIndex()
+}
+
+// GetSubAssets returns at most limit sub-assets of the asset starting at the cursor and the cursor of the following sub-assets.
+func (am *AccountManager) GetSubAssets(assetID uint64, cursor uint64, limit uint64) ([]*asset.AssetObject, uint64, error) {
+	return am.ast.GetSubAssets(assetID, cursor, limit)
+}
+
+// GetNFTCollection returns the non-fungible token collection by id.
+func (am *AccountManager) GetNFTCollection(collectionID uint64) (*asset.NFTCollection, error) {
+	return am.ast.GetNFTCollection(collectionID)
+}
+
+// GetNFTCollectionByName returns the non-fungible token collection by name.
+func (am *AccountManager) GetNFTCollectionByName(name string) (*asset.NFTCollection, error) {
+	return am.ast.GetNFTCollectionByName(name)
+}
+
+// GetNFToken returns the non-fungible token of the collection.
+func (am *AccountManager) GetNFToken(collectionID uint64, tokenID uint64) (*asset.NFToken, error) {
+	return am.ast.GetNFToken(collectionID, tokenID)
+}
+
+// GetNFTokensByOwner returns at most limit tokens of the owner starting at the cursor and the number of tokens of the owner.
+func (am *AccountManager) GetNFTokensByOwner(owner common.Name, cursor uint64, limit uint64) ([]*asset.NFToken, uint64, error) {
+	return am.ast.GetNFTokensByOwner(owner, cursor, limit)
+}
+
+// GetNFTokensByCollection returns at most limit tokens of the collection from the cursor and the cursor of the following tokens.
+func (am *AccountManager) GetNFTokensByCollection(collectionID uint64, cursor uint64, limit uint64) ([]*asset.NFToken, uint64, error) {
+	return am.ast.GetNFTokensByCollection(collectionID, cursor, limit)
+}
+
+// CheckAssetFrozen returns an error if the asset or its balance of any account is frozen.
+func (am *AccountManager) CheckAssetFrozen(assetID uint64, names ...common.Name) error {
+	return am.ast.CheckFrozen(assetID, names...)
+}
+
+// GetFrozenAssets returns the assets of the account balances which are frozen.
+func (am *AccountManager) GetFrozenAssets(acct *Account) ([]uint64, error) {
+	var frozen []uint64
+	for _, balance := range acct.Balances {
+		assetFrozen, err := am.ast.IsAssetFrozen(balance.AssetID)
+		if err != nil {
+			return nil, err
+		}
+		acctFrozen, err := am.ast.IsAccountFrozen(balance.AssetID, acct.GetName())
+		if err != nil {
+			return nil, err
+		}
+		if assetFrozen || acctFrozen {
+			frozen = append(frozen, balance.AssetID)
+		}
+	}
+	return frozen, nil
 }
 
 // GetAllAssetByAssetID get accout asset and subAsset information
@@ -927,6 +1106,22 @@ func (am *AccountManager) GetAccountBalanceByID(accountName common.Name, assetID
 	}
 }
 
+// addAssetEvent records the asset event in the asset history from ForkID5.
+func (am *AccountManager) addAssetEvent(curForkID uint64, assetID uint64, number uint64, actionType types.ActionType, account common.Name, amount *big.Int) error {
+	if curForkID < params.ForkID5 {
+		return nil
+	}
+	return am.ast.AddAssetEvent(assetID, number, actionType, account, amount)
+}
+
+// GetAssetEvents returns the events of the asset history.
+func (am *AccountManager) GetAssetEvents(assetID uint64, cursor uint64, limit uint64) ([]*asset.AssetEvent, uint64, error) {
+	if _, err := am.ast.GetAssetObjectByID(assetID); err != nil {
+		return nil, 0, err
+	}
+	return am.ast.GetAssetEvents(assetID, cursor, limit)
+}
+
 //GetAssetAmountByTime get asset amount by time
 func (am *AccountManager) GetAssetAmountByTime(assetID uint64, time uint64) (*big.Int, error) {
 	return am.ast.GetAssetAmountByTime(assetID, time)
@@ -1004,6 +1199,9 @@ func (am *AccountManager) SubAccountBalanceByID(accountName common.Name, assetID
 	if err != nil {
 		return err
 	}
+	if err := am.updateAssetHolder(acct, assetID); err != nil {
+		return err
+	}
 
 	return am.SetAccount(acct)
 }
@@ -1026,6 +1224,9 @@ func (am *AccountManager) AddAccountBalanceByID(accountName common.Name, assetID
 	if err != nil {
 		return err
 	}
+	if err := am.updateAssetHolder(acct, assetID); err != nil {
+		return err
+	}
 
 	return am.SetAccount(acct)
 }
@@ -1053,12 +1254,16 @@ func (am *AccountManager) AddAccountBalanceByName(accountName common.Name, asset
 	if err != nil {
 		return err
 	}
+	if err := am.updateAssetHolder(acct, assetID); err != nil {
+		return err
+	}
 
 	return am.SetAccount(acct)
 }
 
-//
-func (am *AccountManager) EnoughAccountBalance(accountName common.Name, assetID uint64, value *big.Int) error {
+// EnoughAccountBalance checks the balance of the account less its balance
+// locked by vesting schedules at the block number.
+func (am *AccountManager) EnoughAccountBalance(accountName common.Name, assetID uint64, value *big.Int, number uint64) error {
 	acct, err := am.GetAccountByName(accountName)
 	if err != nil {
 		return err
@@ -1069,7 +1274,7 @@ func (am *AccountManager) EnoughAccountBalance(accountName common.Name, assetID
 	if value.Cmp(big.NewInt(0)) < 0 {
 		return ErrAmountValueInvalid
 	}
-	return acct.EnoughAccountBalance(assetID, value)
+	return am.enoughUnlockedBalance(acct, assetID, value, number)
 }
 
 //
@@ -1147,26 +1352,30 @@ func (am *AccountManager) GetCodeSize(accountName common.Name) (uint64, error) {
 // 	return &acct, nil
 // }
 
-// CanTransfer check if can transfer.
-func (am *AccountManager) CanTransfer(accountName common.Name, assetID uint64, value *big.Int) (bool, error) {
+// CanTransfer check if can transfer at the block number.
+func (am *AccountManager) CanTransfer(accountName common.Name, assetID uint64, value *big.Int, number uint64) (bool, error) {
 	acct, err := am.GetAccountByName(accountName)
 	if err != nil {
 		return false, err
 	}
-	if err = acct.EnoughAccountBalance(assetID, value); err == nil {
+	if err = am.enoughUnlockedBalance(acct, assetID, value, number); err == nil {
 		return true, nil
 	}
 	return false, err
 }
 
-//TransferAsset transfer asset
-func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int, fromAccountExtra ...common.Name) error {
+//TransferAsset transfer asset at the block number
+func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int, number uint64, fromAccountExtra ...common.Name) error {
 	if sign := value.Sign(); sign == 0 {
 		return nil
 	} else if sign == -1 {
 		return ErrNegativeValue
 	}
 
+	if err := am.ast.CheckFrozen(assetID, fromAccount, toAccount); err != nil {
+		return err
+	}
+
 	fromAccountExtra = append(fromAccountExtra, fromAccount)
 	fromAccountExtra = append(fromAccountExtra, toAccount)
 	if !am.ast.HasAccess(assetID, fromAccountExtra...) {
@@ -1194,6 +1403,12 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 	if val.Cmp(big.NewInt(0)) < 0 || val.Cmp(value) < 0 {
 		return ErrInsufficientBalance
 	}
+	if err := am.enoughUnlockedBalance(fromAcct, assetID, value, number); err != nil {
+		return err
+	}
+	if err := am.pruneVestingSchedules(fromAcct.GetAccountID(), assetID, number); err != nil {
+		return err
+	}
 
 	if fromAccount == toAccount || value.Cmp(big.NewInt(0)) == 0 {
 		return nil
@@ -1222,6 +1437,12 @@ func (am *AccountManager) TransferAsset(fromAccount common.Name, toAccount commo
 			return err
 		}
 	}
+	if err := am.updateAssetHolder(fromAcct, assetID); err != nil {
+		return err
+	}
+	if err := am.updateAssetHolder(toAcct, assetID); err != nil {
+		return err
+	}
 	if err = am.SetAccount(fromAcct); err != nil {
 		return err
 	}
@@ -1238,7 +1459,7 @@ func (am *AccountManager) CheckAssetContract(contract common.Name, owner common.
 	return false
 }
 
-func (am *AccountManager) checkAssetNameAndOwner(fromName common.Name, assetInfo *IssueAsset) error {
+func (am *AccountManager) checkAssetNameAndOwner(fromName common.Name, assetInfo *IssueAsset, curForkID uint64) error {
 	var assetNames []string
 	var assetPre string
 
@@ -1269,6 +1490,23 @@ func (am *AccountManager) checkAssetNameAndOwner(fromName common.Name, assetInfo
 
 	//check sub asset owner
 	parentAssetID, isValid := am.ast.IsValidAssetOwner(fromName, assetPre, assetNames)
+	if curForkID >= params.ForkID5 {
+		// the policy of the nearest parent decides who can issue the sub asset
+		parentID, ok := am.ast.GetParentAssetID(assetInfo.AssetName)
+		if !ok {
+			return fmt.Errorf("parent asset not exist, name: %v", assetInfo.AssetName)
+		}
+		policy, err := am.ast.GetSubAssetPolicy(parentID)
+		if err != nil {
+			return err
+		}
+		if policy == asset.SubAssetPolicyClosed {
+			return asset.ErrSubAssetClosed
+		}
+		if !isValid && policy == asset.SubAssetPolicyOpen {
+			parentAssetID, isValid = parentID, true
+		}
+	}
 	if !isValid {
 		return fmt.Errorf("asset owner is invalid, name: %v", assetInfo.AssetName)
 	}
@@ -1278,7 +1516,7 @@ func (am *AccountManager) checkAssetNameAndOwner(fromName common.Name, assetInfo
 	return nil
 }
 
-func (am *AccountManager) checkAssetInfoValid(fromName common.Name, assetInfo *IssueAsset) error {
+func (am *AccountManager) checkAssetInfoValid(fromName common.Name, assetInfo *IssueAsset, curForkID uint64) error {
 	if assetInfo.Owner == "" {
 		return fmt.Errorf("asset owner invalid")
 	}
@@ -1293,7 +1531,7 @@ func (am *AccountManager) checkAssetInfoValid(fromName common.Name, assetInfo *I
 		}
 	}
 
-	err := am.checkAssetNameAndOwner(fromName, assetInfo)
+	err := am.checkAssetNameAndOwner(fromName, assetInfo, curForkID)
 	if err != nil {
 		return err
 	}
@@ -1313,7 +1551,7 @@ func (am *AccountManager) checkAssetInfoValid(fromName common.Name, assetInfo *I
 func (am *AccountManager) IssueAsset(fromName common.Name, asset IssueAsset, number uint64, curForkID uint64) (uint64, error) {
 	//check owner valid
 	if curForkID >= params.ForkID1 {
-		err := am.checkAssetInfoValid(fromName, &asset)
+		err := am.checkAssetInfoValid(fromName, &asset, curForkID)
 		if err != nil {
 			return 0, err
 		}
@@ -1379,6 +1617,13 @@ func (am *AccountManager) IssueAsset(fromName common.Name, asset IssueAsset, num
 	if err != nil {
 		return 0, err
 	}
+	if curForkID >= params.ForkID5 {
+		if parentID, ok := am.ast.GetParentAssetID(asset.AssetName); ok {
+			if err := am.ast.AddSubAsset(parentID, assetID); err != nil {
+				return 0, err
+			}
+		}
+	}
 
 	//add the asset to owner
 	return assetID, nil
@@ -1396,6 +1641,16 @@ func (am *AccountManager) IncAsset2Acct(fromName common.Name, toName common.Name
 	return nil
 }
 
+// isSystemAccount returns whether name is one of the chain system accounts,
+// fees and refunds of every transaction pass through them.
+func isSystemAccount(cfg *params.ChainConfig, name common.Name) bool {
+	switch name.String() {
+	case cfg.SysName, cfg.AccountName, cfg.AssetName, cfg.DposName, cfg.FeeName:
+		return true
+	}
+	return false
+}
+
 //Process account action
 func (am *AccountManager) Process(accountManagerContext *types.AccountManagerContext) ([]*types.InternalAction, error) {
 	snap := am.sdb.Snapshot()
@@ -1419,7 +1674,7 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 
 	var internalActions []*types.InternalAction
 	//transfer
-	if err := am.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), fromAccountExtra...); err != nil {
+	if err := am.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), number, fromAccountExtra...); err != nil {
 		return nil, err
 	}
 
@@ -1437,7 +1692,7 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		}
 
 		if action.Value().Cmp(big.NewInt(0)) > 0 {
-			if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), acct.AccountName, action.AssetID(), action.Value(), fromAccountExtra...); err != nil {
+			if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AccountName), acct.AccountName, action.AssetID(), action.Value(), number, fromAccountExtra...); err != nil {
 				return nil, err
 			}
 			actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AccountName), acct.AccountName, 0, action.AssetID(), 0, action.Value(), nil, nil)
@@ -1460,7 +1715,173 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err != nil {
 			return nil, err
 		}
-		if err := am.UpdateAccountAuthor(action.Sender(), &acctAuth); err != nil {
+		if curForkID >= params.ForkID5 {
+			queued, err := am.queueAuthorChange(action.Sender(), action.Type(), action.Data(), number, curForkID)
+			if err != nil {
+				return nil, err
+			}
+			if queued {
+				break
+			}
+		}
+		if err := am.UpdateAccountAuthor(action.Sender(), &acctAuth, curForkID); err != nil {
+			return nil, err
+		}
+	case types.SetAccountAuthorDelay:
+		var delay AuthorDelayAction
+		err := rlp.DecodeBytes(action.Data(), &delay)
+		if err != nil {
+			return nil, err
+		}
+		if delay.Delay > MaxAuthorDelay {
+			return nil, fmt.Errorf("account author delay can not exceed %d", MaxAuthorDelay)
+		}
+		queued, err := am.queueAuthorChange(action.Sender(), action.Type(), action.Data(), number, curForkID)
+		if err != nil {
+			return nil, err
+		}
+		if !queued {
+			if err := am.setAuthorDelay(action.Sender(), delay.Delay); err != nil {
+				return nil, err
+			}
+		}
+	case types.ApproveAllowance:
+		var approve ApproveAllowanceAction
+		if err := rlp.DecodeBytes(action.Data(), &approve); err != nil {
+			return nil, err
+		}
+		if err := am.ApproveAllowance(action.Sender(), approve.Spender, approve.AssetID, approve.Amount); err != nil {
+			return nil, err
+		}
+	case types.TransferFrom:
+		var transfer TransferFromAction
+		if err := rlp.DecodeBytes(action.Data(), &transfer); err != nil {
+			return nil, err
+		}
+		if err := am.TransferFrom(action.Sender(), transfer.Owner, transfer.To, transfer.AssetID, transfer.Amount, number); err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, transfer.Owner, transfer.To, 0, transfer.AssetID, 0, transfer.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "transferfrom", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.LockHTLC:
+		var lock LockHTLCAction
+		if err := rlp.DecodeBytes(action.Data(), &lock); err != nil {
+			return nil, err
+		}
+		if _, err := am.lockHTLC(action.Sender(), action.AssetID(), action.Value(), &lock, number); err != nil {
+			return nil, err
+		}
+	case types.ClaimHTLC:
+		var claim ClaimHTLCAction
+		if err := rlp.DecodeBytes(action.Data(), &claim); err != nil {
+			return nil, err
+		}
+		htlc, err := am.claimHTLC(action.Recipient(), &claim, number)
+		if err != nil {
+			return nil, err
+		}
+		// the payload of the internal action reveals the preimage to watchers
+		actionX := types.NewAction(types.Transfer, action.Recipient(), htlc.Recipient, 0, htlc.AssetID, 0, htlc.Amount, htlc.Preimage, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "htlcclaim", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.RefundHTLC:
+		var refund RefundHTLCAction
+		if err := rlp.DecodeBytes(action.Data(), &refund); err != nil {
+			return nil, err
+		}
+		htlc, err := am.refundHTLC(action.Recipient(), &refund, number)
+		if err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, action.Recipient(), htlc.Sender, 0, htlc.AssetID, 0, htlc.Amount, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "htlcrefund", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.DistributeDividend:
+		var distribute DistributeDividendAction
+		if err := rlp.DecodeBytes(action.Data(), &distribute); err != nil {
+			return nil, err
+		}
+		if _, err := am.distributeDividend(action.Sender(), action.AssetID(), action.Value(), &distribute, number); err != nil {
+			return nil, err
+		}
+	case types.ClaimDividend:
+		var claim ClaimDividendAction
+		if err := rlp.DecodeBytes(action.Data(), &claim); err != nil {
+			return nil, err
+		}
+		dividend, share, err := am.claimDividend(action.Recipient(), action.Sender(), claim.ID, number)
+		if err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, action.Recipient(), action.Sender(), 0, dividend.PayoutAssetID, 0, share, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "dividend", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.ReclaimDividend:
+		var reclaim ReclaimDividendAction
+		if err := rlp.DecodeBytes(action.Data(), &reclaim); err != nil {
+			return nil, err
+		}
+		dividend, outstanding, err := am.reclaimDividend(action.Recipient(), action.Sender(), reclaim.ID, number)
+		if err != nil {
+			return nil, err
+		}
+		actionX := types.NewAction(types.Transfer, action.Recipient(), action.Sender(), 0, dividend.PayoutAssetID, 0, outstanding, nil, nil)
+		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "dividendreclaim", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+		internalActions = append(internalActions, internalAction)
+	case types.AtomicSwap:
+		var swap AtomicSwapAction
+		if err := rlp.DecodeBytes(action.Data(), &swap); err != nil {
+			return nil, err
+		}
+		if err := am.AtomicSwap(action.Sender(), &swap, number, accountManagerContext.ChainConfig.ChainID); err != nil {
+			return nil, err
+		}
+		order := swap.Order
+		for _, actionX := range []*types.Action{
+			types.NewAction(types.Transfer, order.Maker, order.Taker, 0, order.MakerAssetID, 0, order.MakerAmount, nil, nil),
+			types.NewAction(types.Transfer, order.Taker, order.Maker, 0, order.TakerAssetID, 0, order.TakerAmount, nil, nil),
+		} {
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "swap", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
+	case types.CancelSwapOrder:
+		var cancel CancelSwapOrderAction
+		if err := rlp.DecodeBytes(action.Data(), &cancel); err != nil {
+			return nil, err
+		}
+		if err := am.CancelSwapOrder(action.Sender(), cancel.Nonce); err != nil {
+			return nil, err
+		}
+	case types.ProposeAccountAction:
+		var propose ProposeAction
+		err := rlp.DecodeBytes(action.Data(), &propose)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.Propose(action, &propose, accountManagerContext)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.ApproveAccountAction:
+		var approve ApproveAction
+		err := rlp.DecodeBytes(action.Data(), &approve)
+		if err != nil {
+			return nil, err
+		}
+		actions, err := am.Approve(action, approve.ID, accountManagerContext)
+		if err != nil {
+			return nil, err
+		}
+		internalActions = append(internalActions, actions...)
+	case types.CancelAccountAuthor:
+		var cancel CancelAuthorAction
+		err := rlp.DecodeBytes(action.Data(), &cancel)
+		if err != nil {
+			return nil, err
+		}
+		if err := am.CancelAuthorChange(action.Sender(), cancel.ID); err != nil {
 			return nil, err
 		}
 	case types.IssueAsset:
@@ -1481,6 +1902,9 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err != nil {
 			return nil, err
 		}
+		if err := am.addAssetEvent(curForkID, assetID, number, action.Type(), action.Sender(), issueAsset.Amount); err != nil {
+			return nil, err
+		}
 
 		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), assetID, issueAsset.Amount); err != nil {
 			return nil, err
@@ -1489,7 +1913,7 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
 		internalActions = append(internalActions, internalAction)
 
-		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, assetID, issueAsset.Amount, fromAccountExtra...); err != nil {
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, assetID, issueAsset.Amount, number, fromAccountExtra...); err != nil {
 			return nil, err
 		}
 		actionX = types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), issueAsset.Owner, 0, assetID, 0, issueAsset.Amount, nil, nil)
@@ -1509,6 +1933,9 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.IncAsset2Acct(action.Sender(), inc.To, inc.AssetID, inc.Amount, curForkID); err != nil {
 			return nil, err
 		}
+		if err := am.addAssetEvent(curForkID, inc.AssetID, number, action.Type(), action.Sender(), inc.Amount); err != nil {
+			return nil, err
+		}
 
 		if err := am.AddAccountBalanceByID(common.Name(accountManagerContext.ChainConfig.AssetName), inc.AssetID, inc.Amount); err != nil {
 			return nil, err
@@ -1518,7 +1945,7 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		internalActions = append(internalActions, internalAction)
 
 		fromAccountExtra = append(fromAccountExtra, action.Sender())
-		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, inc.AssetID, inc.Amount, fromAccountExtra...); err != nil {
+		if err := am.TransferAsset(common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, inc.AssetID, inc.Amount, number, fromAccountExtra...); err != nil {
 			return nil, err
 		}
 		actionX = types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), inc.To, 0, inc.AssetID, 0, inc.Amount, nil, nil)
@@ -1532,6 +1959,9 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.ast.DestroyAsset(common.Name(accountManagerContext.ChainConfig.AssetName), action.AssetID(), action.Value()); err != nil {
 			return nil, err
 		}
+		if err := am.addAssetEvent(curForkID, action.AssetID(), number, action.Type(), action.Sender(), action.Value()); err != nil {
+			return nil, err
+		}
 		actionX := types.NewAction(types.Transfer, common.Name(accountManagerContext.ChainConfig.AssetName), common.Name(""), 0, action.AssetID(), 0, action.Value(), nil, nil)
 		internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
 		internalActions = append(internalActions, internalAction)
@@ -1559,6 +1989,9 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.ast.UpdateAsset(action.Sender(), asset.AssetID, asset.Founder, curForkID); err != nil {
 			return nil, err
 		}
+		if err := am.addAssetEvent(curForkID, asset.AssetID, number, action.Type(), action.Sender(), big.NewInt(0)); err != nil {
+			return nil, err
+		}
 	case types.SetAssetOwner:
 		var asset UpdateAssetOwner
 		err := rlp.DecodeBytes(action.Data(), &asset)
@@ -1581,6 +2014,9 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.ast.SetAssetNewOwner(action.Sender(), asset.AssetID, asset.Owner); err != nil {
 			return nil, err
 		}
+		if err := am.addAssetEvent(curForkID, asset.AssetID, number, action.Type(), action.Sender(), big.NewInt(0)); err != nil {
+			return nil, err
+		}
 	case types.UpdateAssetContract:
 		var assetContract UpdateAssetContract
 		err := rlp.DecodeBytes(action.Data(), &assetContract)
@@ -1605,7 +2041,146 @@ func (am *AccountManager) process(accountManagerContext *types.AccountManagerCon
 		if err := am.ast.SetAssetNewContract(assetContract.AssetID, assetContract.Contract); err != nil {
 			return nil, err
 		}
+		if err := am.addAssetEvent(curForkID, assetContract.AssetID, number, action.Type(), action.Sender(), big.NewInt(0)); err != nil {
+			return nil, err
+		}
+	case types.FreezeAsset:
+		var freeze FreezeAsset
+		if err := rlp.DecodeBytes(action.Data(), &freeze); err != nil {
+			return nil, err
+		}
+		if freeze.AssetID == accountManagerContext.ChainConfig.SysTokenID {
+			return nil, ErrFreezeSysToken
+		}
+		if err := am.ast.CheckOwner(action.Sender(), freeze.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetFrozen(freeze.AssetID, freeze.Frozen); err != nil {
+			return nil, err
+		}
+	case types.FreezeAccountAsset:
+		var freeze FreezeAccountAsset
+		if err := rlp.DecodeBytes(action.Data(), &freeze); err != nil {
+			return nil, err
+		}
+		if freeze.AssetID == accountManagerContext.ChainConfig.SysTokenID {
+			return nil, ErrFreezeSysToken
+		}
+		if isSystemAccount(accountManagerContext.ChainConfig, freeze.Account) {
+			return nil, ErrFreezeSysAccount
+		}
+		acct, err := am.GetAccountByName(freeze.Account)
+		if err != nil {
+			return nil, err
+		}
+		if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		if err := am.ast.CheckOwner(action.Sender(), freeze.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAccountFrozen(freeze.AssetID, freeze.Account, freeze.Frozen); err != nil {
+			return nil, err
+		}
 
+	case types.IssueNFTCollection:
+		var collection IssueNFTCollection
+		if err := rlp.DecodeBytes(action.Data(), &collection); err != nil {
+			return nil, err
+		}
+		if len(collection.Owner) == 0 {
+			collection.Owner = action.Sender()
+		}
+		if acct, err := am.GetAccountByName(collection.Owner); err != nil {
+			return nil, err
+		} else if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		// the name of an account can only be taken by the account
+		if common.Name(collection.Name) != action.Sender() {
+			if exist, err := am.AccountIsExist(common.Name(collection.Name)); err != nil {
+				return nil, err
+			} else if exist {
+				return nil, fmt.Errorf("collection name %s is an account", collection.Name)
+			}
+		}
+		if _, err := am.ast.IssueNFTCollection(collection.Name, collection.Symbol, number, action.Sender(), collection.Owner, collection.Description); err != nil {
+			return nil, err
+		}
+	case types.MintNFT:
+		var mint MintNFT
+		if err := rlp.DecodeBytes(action.Data(), &mint); err != nil {
+			return nil, err
+		}
+		if acct, err := am.GetAccountByName(mint.To); err != nil {
+			return nil, err
+		} else if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		if _, err := am.ast.MintNFT(action.Sender(), mint.CollectionID, mint.To, mint.URI, number); err != nil {
+			return nil, err
+		}
+	case types.TransferNFT:
+		var transfer TransferNFT
+		if err := rlp.DecodeBytes(action.Data(), &transfer); err != nil {
+			return nil, err
+		}
+		if acct, err := am.GetAccountByName(action.Recipient()); err != nil {
+			return nil, err
+		} else if acct == nil {
+			return nil, ErrAccountNotExist
+		}
+		if err := am.ast.TransferNFT(action.Sender(), transfer.CollectionID, transfer.TokenID, action.Recipient()); err != nil {
+			return nil, err
+		}
+	case types.BurnNFT:
+		var burn BurnNFT
+		if err := rlp.DecodeBytes(action.Data(), &burn); err != nil {
+			return nil, err
+		}
+		if err := am.ast.BurnNFT(action.Sender(), burn.CollectionID, burn.TokenID); err != nil {
+			return nil, err
+		}
+	case types.SetAssetMetadata:
+		var set SetAssetMetadata
+		if err := rlp.DecodeBytes(action.Data(), &set); err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), set.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetAssetMetadata(set.AssetID, set.Metadata); err != nil {
+			return nil, err
+		}
+	case types.SetSubAssetPolicy:
+		var set SetSubAssetPolicy
+		if err := rlp.DecodeBytes(action.Data(), &set); err != nil {
+			return nil, err
+		}
+		if err := am.ast.CheckOwner(action.Sender(), set.AssetID); err != nil {
+			return nil, err
+		}
+		if err := am.ast.SetSubAssetPolicy(set.AssetID, set.Policy); err != nil {
+			return nil, err
+		}
+	case types.VestingTransfer:
+		var vesting VestingTransfer
+		if err := rlp.DecodeBytes(action.Data(), &vesting); err != nil {
+			return nil, err
+		}
+		if err := am.addVestingSchedule(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), &vesting, number); err != nil {
+			return nil, err
+		}
+	case types.RejectVestingTransfer:
+		amount, err := am.rejectVestingSchedules(action.Sender(), action.Recipient(), action.AssetID(), number)
+		if err != nil {
+			return nil, err
+		}
+		if amount.Sign() > 0 {
+			actionX := types.NewAction(types.Transfer, action.Sender(), action.Recipient(), 0, action.AssetID(), 0, amount, nil, nil)
+			internalAction := &types.InternalAction{Action: actionX.NewRPCAction(0), ActionType: "rejectvesting", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+			internalActions = append(internalActions, internalAction)
+		}
 	case types.Transfer:
 	default:
 		return nil, ErrUnKnownTxType
@@ -1102,7 +1102,7 @@ func TestAccountManager_EnoughAccountBalance(t *testing.T) {
 			sdb: tt.fields.sdb,
 			ast: tt.fields.ast,
 		}
-		if err := am.EnoughAccountBalance(tt.args.accountName, tt.args.AssetID, tt.args.value); (err != nil) != tt.wantErr {
+		if err := am.EnoughAccountBalance(tt.args.accountName, tt.args.AssetID, tt.args.value, 0); (err != nil) != tt.wantErr {
 			t.Errorf("%q. AccountManager.EnoughAccountBalance() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 		}
 	}
@@ -1281,7 +1281,7 @@ func TestAccountManager_CanTransfer(t *testing.T) {
 			sdb: tt.fields.sdb,
 			ast: tt.fields.ast,
 		}
-		got, err := am.CanTransfer(tt.args.accountName, tt.args.AssetID, tt.args.value)
+		got, err := am.CanTransfer(tt.args.accountName, tt.args.AssetID, tt.args.value, 0)
 		if (err != nil) != tt.wantErr {
 			t.Errorf("%q. AccountManager.CanTransfer() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 			continue
@@ -1326,7 +1326,7 @@ func TestAccountManager_TransferAsset(t *testing.T) {
 			sdb: tt.fields.sdb,
 			ast: tt.fields.ast,
 		}
-		if err := am.TransferAsset(tt.args.fromAccount, tt.args.toAccount, tt.args.AssetID, tt.args.value); (err != nil) != tt.wantErr {
+		if err := am.TransferAsset(tt.args.fromAccount, tt.args.toAccount, tt.args.AssetID, tt.args.value, 0); (err != nil) != tt.wantErr {
 			t.Errorf("%q. AccountManager.TransferAsset() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 		}
 	}
@@ -1958,7 +1958,7 @@ func TestAccountManager_TransferContractAsset(t *testing.T) {
 			sdb: tt.fields.sdb,
 			ast: tt.fields.ast,
 		}
-		if err := am.TransferAsset(tt.args.fromAccount, tt.args.toAccount, tt.args.AssetID, tt.args.value); (err != nil) != tt.wantErr {
+		if err := am.TransferAsset(tt.args.fromAccount, tt.args.toAccount, tt.args.AssetID, tt.args.value, 0); (err != nil) != tt.wantErr {
 			t.Errorf("%q. AccountManager.TransferAsset() error = %v, wantErr %v", tt.name, err, tt.wantErr)
 		}
 	}
@@ -2312,3 +2312,304 @@ func TestAccountManager_AccountHaveCode(t *testing.T) {
 		t.Errorf("TestAccountManager_AccountHaveCode. account not have code error = %v", err)
 	}
 }
+
+func TestAccountManager_FreezeAsset(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, holder := common.Name("freezeowner"), common.Name("freezeholder")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("freezetest123456"))
+	for _, name := range []common.Name{owner, holder} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// asset id 0 is the system token
+	if _, err := am.ast.IssueAsset("freezesys", 0, 0, "fs", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), ""); err != nil {
+		t.Fatal(err)
+	}
+	assetID, err := am.ast.IssueAsset("freezeasset", 0, 0, "fa", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(holder, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	process := func(sender common.Name, actionType types.ActionType, payload interface{}) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, sender, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 1, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	if err := process(holder, types.FreezeAccountAsset, &FreezeAccountAsset{AssetID: assetID, Account: holder, Frozen: true}); err == nil {
+		t.Fatal("account frozen by non owner")
+	}
+	if err := process(owner, types.FreezeAccountAsset, &FreezeAccountAsset{AssetID: assetID, Account: holder, Frozen: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(holder, owner, assetID, big.NewInt(1), 0); err == nil {
+		t.Fatal("frozen account transferred")
+	}
+	acct, _ := am.GetAccountByName(holder)
+	if frozen, _ := am.GetFrozenAssets(acct); len(frozen) != 1 || frozen[0] != assetID {
+		t.Fatalf("frozen assets mismatch: %v", frozen)
+	}
+	if err := process(owner, types.FreezeAccountAsset, &FreezeAccountAsset{AssetID: assetID, Account: holder}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(holder, owner, assetID, big.NewInt(1), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := process(owner, types.FreezeAsset, &FreezeAsset{AssetID: assetID, Frozen: true}); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := am.GetAssetInfoByID(assetID); !info.Frozen {
+		t.Fatal("asset info not frozen")
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(1), 0); err == nil {
+		t.Fatal("frozen asset transferred")
+	}
+	if err := process(owner, types.FreezeAsset, &FreezeAsset{AssetID: assetID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(1), 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccountManager_NFT(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, holder := common.Name("nftowner"), common.Name("nftholder")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("nfttest123456"))
+	for _, name := range []common.Name{owner, holder} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	process := func(sender common.Name, to common.Name, actionType types.ActionType, payload interface{}) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, sender, to, 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 1, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	assetName := common.Name(params.DefaultChainconfig.AssetName)
+	if err := process(owner, assetName, types.IssueNFTCollection, &IssueNFTCollection{Name: holder.String(), Symbol: "cards"}); err == nil {
+		t.Fatal("collection named after another account issued")
+	}
+	if err := process(owner, assetName, types.IssueNFTCollection, &IssueNFTCollection{Name: "nftcards", Symbol: "cards"}); err != nil {
+		t.Fatal(err)
+	}
+	collection, err := am.GetNFTCollectionByName("nftcards")
+	if err != nil || collection.Owner != owner {
+		t.Fatalf("collection mismatch: %v %v", collection, err)
+	}
+	if err := process(owner, assetName, types.MintNFT, &MintNFT{CollectionID: collection.CollectionID, To: owner, URI: "ipfs://card"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(owner, holder, types.TransferNFT, &TransferNFT{CollectionID: collection.CollectionID, TokenID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := am.GetNFToken(collection.CollectionID, 1); token.Owner != holder || token.URI != "ipfs://card" {
+		t.Fatalf("token mismatch: %v", token)
+	}
+	if err := process(owner, assetName, types.BurnNFT, &BurnNFT{CollectionID: collection.CollectionID, TokenID: 1}); err == nil {
+		t.Fatal("token burned by non owner")
+	}
+	if err := process(holder, assetName, types.BurnNFT, &BurnNFT{CollectionID: collection.CollectionID, TokenID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := rlp.EncodeToBytes(&MintNFT{CollectionID: collection.CollectionID, To: owner})
+	action := types.NewAction(types.MintNFT, owner, assetName, 0, 0, 0, big.NewInt(0), data, nil)
+	if err := action.Check(params.ForkID4, params.DefaultChainconfig); err == nil {
+		t.Fatal("nft action accepted before fork")
+	}
+}
+
+func TestAccountManager_AssetHistory(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, newOwner := common.Name("historyowner"), common.Name("historynewowner")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("historytest12345"))
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(params.DefaultChainconfig.AssetName), common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{owner, newOwner} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	process := func(actionType types.ActionType, payload interface{}, forkID uint64) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, owner, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 5, CurForkID: forkID, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	issue := &IssueAsset{AssetName: "historyowner:historyasset", Symbol: "ha", Amount: big.NewInt(100), Owner: owner, Founder: owner, UpperLimit: big.NewInt(1000)}
+	if err := process(types.IssueAsset, issue, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	assetID, _ := am.ast.GetAssetIDByName("historyowner:historyasset")
+	if err := process(types.IncreaseAsset, &IncAsset{AssetID: assetID, Amount: big.NewInt(50), To: owner}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(types.SetAssetOwner, &UpdateAssetOwner{AssetID: assetID, Owner: newOwner}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	events, _, err := am.GetAssetEvents(assetID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[1].Amount.Cmp(big.NewInt(50)) != 0 || events[2].Type != types.SetAssetOwner || events[2].Owner != newOwner || events[2].Account != owner {
+		t.Fatalf("events mismatch: %v", events)
+	}
+}
+
+func TestAccountManager_SetAssetMetadata(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, other := common.Name("metaowner"), common.Name("metaother")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("metadatatest1234"))
+	for _, name := range []common.Name{owner, other} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("metaasset", 1, 0, "ma", big.NewInt(100), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	process := func(sender common.Name) error {
+		data, _ := rlp.EncodeToBytes(&SetAssetMetadata{AssetID: assetID, Metadata: []*asset.Metadata{{Key: "logo", Value: "ipfs://logo"}}})
+		action := types.NewAction(types.SetAssetMetadata, sender, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 5, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	if err := process(other); err != asset.ErrOwnerMismatch {
+		t.Fatalf("set metadata by other account, have %v, want %v", err, asset.ErrOwnerMismatch)
+	}
+	if err := process(owner); err != nil {
+		t.Fatal(err)
+	}
+	ao, err := am.GetAssetInfoByID(assetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ao.Metadata) != 1 || ao.Metadata[0].Value != "ipfs://logo" {
+		t.Fatalf("metadata mismatch: %v", ao.Metadata)
+	}
+}
+
+func TestAccountManager_SubAssetPolicy(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, other := common.Name("subowner"), common.Name("subother")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("subassettest1234"))
+	if err := am.CreateAccount(common.Name("fractal"), common.Name(params.DefaultChainconfig.AssetName), common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{owner, other} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	process := func(sender common.Name, actionType types.ActionType, payload interface{}) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, sender, common.Name(params.DefaultChainconfig.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 5, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	issue := func(sender common.Name, name string) error {
+		return process(sender, types.IssueAsset, &IssueAsset{AssetName: name, Symbol: "sa", Amount: big.NewInt(1), Owner: sender, UpperLimit: big.NewInt(0)})
+	}
+	if err := issue(owner, "subowner:parent"); err != nil {
+		t.Fatal(err)
+	}
+	parentID, _ := am.ast.GetAssetIDByName("subowner:parent")
+	if err := issue(owner, "subowner:parent.child"); err != nil {
+		t.Fatal(err)
+	}
+	if err := issue(other, "subowner:parent.other"); err == nil {
+		t.Fatal("other account issued a sub asset of an owner only parent")
+	}
+	if err := process(other, types.SetSubAssetPolicy, &SetSubAssetPolicy{AssetID: parentID, Policy: asset.SubAssetPolicyOpen}); err != asset.ErrOwnerMismatch {
+		t.Fatalf("set policy by other account, have %v, want %v", err, asset.ErrOwnerMismatch)
+	}
+	if err := process(owner, types.SetSubAssetPolicy, &SetSubAssetPolicy{AssetID: parentID, Policy: asset.SubAssetPolicyOpen}); err != nil {
+		t.Fatal(err)
+	}
+	if err := issue(other, "subowner:parent.other"); err != nil {
+		t.Fatal(err)
+	}
+	if err := process(owner, types.SetSubAssetPolicy, &SetSubAssetPolicy{AssetID: parentID, Policy: asset.SubAssetPolicyClosed}); err != nil {
+		t.Fatal(err)
+	}
+	if err := issue(owner, "subowner:parent.closed"); err != asset.ErrSubAssetClosed {
+		t.Fatalf("issue sub asset of a closed parent, have %v, want %v", err, asset.ErrSubAssetClosed)
+	}
+	assets, _, err := am.GetSubAssets(parentID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 2 || assets[0].AssetName != "subowner:parent.child" || assets[1].Owner != other {
+		t.Fatalf("sub assets mismatch: %v", assets)
+	}
+}
+
+func TestAccountManager_FreezeSystem(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := common.Name("freezesysowner")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("freezetest123456"))
+	if err := am.CreateAccount(common.Name("fractal.founder"), owner, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	sysTokenID, err := am.ast.IssueAsset("freezesystoken", 0, 0, "fst", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assetID, err := am.ast.IssueAsset("freezesysasset", 0, 0, "fsa", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := *params.DefaultChainconfig
+	cfg.SysTokenID = sysTokenID
+
+	process := func(actionType types.ActionType, payload interface{}) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, owner, common.Name(cfg.AssetName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 1, CurForkID: params.ForkID5, ChainConfig: &cfg})
+		return err
+	}
+	if err := process(types.FreezeAsset, &FreezeAsset{AssetID: sysTokenID, Frozen: true}); err != ErrFreezeSysToken {
+		t.Fatalf("freeze system token err = %v, want %v", err, ErrFreezeSysToken)
+	}
+	if err := process(types.FreezeAccountAsset, &FreezeAccountAsset{AssetID: sysTokenID, Account: owner, Frozen: true}); err != ErrFreezeSysToken {
+		t.Fatalf("freeze account system token err = %v, want %v", err, ErrFreezeSysToken)
+	}
+	if err := process(types.FreezeAccountAsset, &FreezeAccountAsset{AssetID: assetID, Account: common.Name(cfg.FeeName), Frozen: true}); err != ErrFreezeSysAccount {
+		t.Fatalf("freeze system account err = %v, want %v", err, ErrFreezeSysAccount)
+	}
+	if err := process(types.FreezeAccountAsset, &FreezeAccountAsset{AssetID: assetID, Account: owner, Frozen: true}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+var allowancePrefix = "allowance"
+
+// ApproveAllowanceAction sets the amount of the asset the spender can
+// transfer from the sender account, zero revokes the allowance.
+type ApproveAllowanceAction struct {
+	Spender common.Name `json:"spender"`
+	AssetID uint64      `json:"assetId"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+// TransferFromAction transfers the asset of the owner to the recipient
+// within the allowance of the sender.
+type TransferFromAction struct {
+	Owner   common.Name `json:"owner"`
+	To      common.Name `json:"to"`
+	AssetID uint64      `json:"assetId"`
+	Amount  *big.Int    `json:"amount"`
+}
+
+func allowanceKey(ownerID uint64, spenderID uint64, assetID uint64) string {
+	return allowancePrefix + strconv.FormatUint(ownerID, 10) + "_" + strconv.FormatUint(spenderID, 10) + "_" + strconv.FormatUint(assetID, 10)
+}
+
+func (am *AccountManager) allowanceKey(owner common.Name, spender common.Name, assetID uint64) (string, error) {
+	ownerID, err := am.GetAccountIDByName(owner)
+	if err != nil {
+		return "", err
+	}
+	spenderID, err := am.GetAccountIDByName(spender)
+	if err != nil {
+		return "", err
+	}
+	if ownerID == 0 || spenderID == 0 {
+		return "", ErrAccountNotExist
+	}
+	return allowanceKey(ownerID, spenderID, assetID), nil
+}
+
+// GetAllowance returns the amount of the asset the spender can transfer from the owner account.
+func (am *AccountManager) GetAllowance(owner common.Name, spender common.Name, assetID uint64) (*big.Int, error) {
+	key, err := am.allowanceKey(owner, spender, assetID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := am.sdb.Get(acctManagerName, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return big.NewInt(0), nil
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// ApproveAllowance sets the allowance of the spender on the asset of the owner.
+func (am *AccountManager) ApproveAllowance(owner common.Name, spender common.Name, assetID uint64, amount *big.Int) error {
+	if amount == nil || amount.Sign() < 0 {
+		return ErrAmountValueInvalid
+	}
+	if owner == spender {
+		return fmt.Errorf("account %s can not approve itself", owner)
+	}
+	if _, err := am.ast.GetAssetObjectByID(assetID); err != nil {
+		return err
+	}
+	key, err := am.allowanceKey(owner, spender, assetID)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() == 0 {
+		am.sdb.Delete(acctManagerName, key)
+		return nil
+	}
+	am.sdb.Put(acctManagerName, key, amount.Bytes())
+	return nil
+}
+
+// TransferFrom transfers the asset of the owner to the recipient and
+// deducts the amount from the allowance of the spender.
+func (am *AccountManager) TransferFrom(spender common.Name, owner common.Name, to common.Name, assetID uint64, amount *big.Int, number uint64) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return ErrAmountValueInvalid
+	}
+	allowance, err := am.GetAllowance(owner, spender, assetID)
+	if err != nil {
+		return err
+	}
+	if allowance.Cmp(amount) < 0 {
+		return fmt.Errorf("%v, allowance %d amount %d", ErrAllowanceExceeded, allowance, amount)
+	}
+	if err := am.ApproveAllowance(owner, spender, assetID, new(big.Int).Sub(allowance, amount)); err != nil {
+		return err
+	}
+	return am.TransferAsset(owner, to, assetID, amount, number)
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_Allowance(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, spender, to := common.Name("allowanceowner"), common.Name("allowancespender"), common.Name("allowanceto")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("allowancetest123"))
+	for _, name := range []common.Name{owner, spender, to} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("allowanceasset", 0, 0, "aa", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(owner, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	process := func(sender common.Name, actionType types.ActionType, payload interface{}) ([]*types.InternalAction, error) {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, sender, common.Name(params.DefaultChainconfig.AccountName), 0, 0, 0, big.NewInt(0), data, nil)
+		return am.Process(&types.AccountManagerContext{Action: action, Number: 1, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+	}
+	if _, err := process(spender, types.TransferFrom, &TransferFromAction{Owner: owner, To: to, AssetID: assetID, Amount: big.NewInt(1)}); err == nil {
+		t.Fatal("transferred without allowance")
+	}
+	if _, err := process(owner, types.ApproveAllowance, &ApproveAllowanceAction{Spender: spender, AssetID: assetID, Amount: big.NewInt(300)}); err != nil {
+		t.Fatal(err)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, assetID); allowance.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("allowance mismatch: have %d, want 300", allowance)
+	}
+	internal, err := process(spender, types.TransferFrom, &TransferFromAction{Owner: owner, To: to, AssetID: assetID, Amount: big.NewInt(200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(internal) != 1 || internal[0].ActionType != "transferfrom" {
+		t.Fatalf("internal actions mismatch: %v", internal)
+	}
+	if balance, _ := am.GetAccountBalanceByID(to, assetID, 0); balance.Cmp(big.NewInt(200)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 200", balance)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, assetID); allowance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("allowance mismatch: have %d, want 100", allowance)
+	}
+	if _, err := process(spender, types.TransferFrom, &TransferFromAction{Owner: owner, To: to, AssetID: assetID, Amount: big.NewInt(101)}); err == nil {
+		t.Fatal("transferred more than allowance")
+	}
+
+	// Approving zero revokes the allowance
+	if _, err := process(owner, types.ApproveAllowance, &ApproveAllowanceAction{Spender: spender, AssetID: assetID, Amount: big.NewInt(0)}); err != nil {
+		t.Fatal(err)
+	}
+	if allowance, _ := am.GetAllowance(owner, spender, assetID); allowance.Sign() != 0 {
+		t.Fatalf("allowance not revoked: %d", allowance)
+	}
+	if _, err := process(owner, types.ApproveAllowance, &ApproveAllowanceAction{Spender: owner, AssetID: assetID, Amount: big.NewInt(1)}); err == nil {
+		t.Fatal("account approved itself")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	authorDelayPrefix         = "authorDelay"
+	authorChangePrefix        = "authorChange"
+	authorChangeAccountPrefix = "authorChangeAccount"
+	authorChangeBlockPrefix   = "authorChangeBlock"
+	authorChangeCounterKey    = "authorChangeCounter"
+)
+
+// AuthorDelayAction sets the number of blocks author changes are delayed.
+type AuthorDelayAction struct {
+	Delay uint64 `json:"delay"`
+}
+
+// CancelAuthorAction cancels a queued author change.
+type CancelAuthorAction struct {
+	ID uint64 `json:"id"`
+}
+
+// PendingAuthorChange is an author change waiting for its activation block.
+type PendingAuthorChange struct {
+	ID             uint64           `json:"id"`
+	Account        common.Name      `json:"account"`
+	Type           types.ActionType `json:"type"`
+	Payload        []byte           `json:"payload"`
+	Number         uint64           `json:"number"`
+	ActivateNumber uint64           `json:"activateNumber"`
+}
+
+func authorDelayKey(accountID uint64) string {
+	return authorDelayPrefix + strconv.FormatUint(accountID, 10)
+}
+
+func authorChangeKey(id uint64) string {
+	return authorChangePrefix + strconv.FormatUint(id, 10)
+}
+
+func authorChangeAccountKey(accountID uint64) string {
+	return authorChangeAccountPrefix + strconv.FormatUint(accountID, 10)
+}
+
+func authorChangeBlockKey(number uint64) string {
+	return authorChangeBlockPrefix + strconv.FormatUint(number, 10)
+}
+
+func (am *AccountManager) getIDList(key string) ([]uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var ids []uint64
+	if err := rlp.DecodeBytes(b, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (am *AccountManager) putIDList(key string, ids []uint64) error {
+	if len(ids) == 0 {
+		am.sdb.Delete(acctManagerName, key)
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(ids)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, key, b)
+	return nil
+}
+
+func (am *AccountManager) removeID(key string, id uint64) error {
+	ids, err := am.getIDList(key)
+	if err != nil {
+		return err
+	}
+	for i, v := range ids {
+		if v == id {
+			return am.putIDList(key, append(ids[:i], ids[i+1:]...))
+		}
+	}
+	return nil
+}
+
+func (am *AccountManager) getAuthorChange(id uint64) (*PendingAuthorChange, error) {
+	b, err := am.sdb.Get(acctManagerName, authorChangeKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var change PendingAuthorChange
+	if err := rlp.DecodeBytes(b, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// GetAuthorDelay returns the number of blocks author changes of the account are delayed.
+func (am *AccountManager) GetAuthorDelay(accountName common.Name) (uint64, error) {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return 0, err
+	}
+	if acct == nil {
+		return 0, ErrAccountNotExist
+	}
+	return am.getUint64(authorDelayKey(acct.GetAccountID()))
+}
+
+func (am *AccountManager) setAuthorDelay(accountName common.Name, delay uint64) error {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return err
+	}
+	if acct == nil {
+		return ErrAccountNotExist
+	}
+	if delay == 0 {
+		am.sdb.Delete(acctManagerName, authorDelayKey(acct.GetAccountID()))
+		return nil
+	}
+	return am.putUint64(authorDelayKey(acct.GetAccountID()), delay)
+}
+
+// queueAuthorChange queues the author change if the account has a delay, it
+// returns false if the change must be applied immediately.
+func (am *AccountManager) queueAuthorChange(accountName common.Name, actionType types.ActionType, payload []byte, number uint64, curForkID uint64) (bool, error) {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return false, err
+	}
+	if acct == nil {
+		return false, ErrAccountNotExist
+	}
+	delay, err := am.getUint64(authorDelayKey(acct.GetAccountID()))
+	if err != nil || delay == 0 {
+		return false, err
+	}
+	// check the change can be applied to the current authors
+	if actionType == types.UpdateAccountAuthor {
+		var acctAuth AccountAuthorAction
+		if err := rlp.DecodeBytes(payload, &acctAuth); err != nil {
+			return false, err
+		}
+		if err := updateAccountAuthor(acct, &acctAuth, curForkID); err != nil {
+			return false, err
+		}
+	}
+
+	accountKey := authorChangeAccountKey(acct.GetAccountID())
+	ids, err := am.getIDList(accountKey)
+	if err != nil {
+		return false, err
+	}
+	if len(ids) >= MaxPendingAuthorChanges {
+		return false, fmt.Errorf("account pending author changes can not exceed %d", MaxPendingAuthorChanges)
+	}
+	id, err := am.getUint64(authorChangeCounterKey)
+	if err != nil {
+		return false, err
+	}
+	id++
+	change := &PendingAuthorChange{
+		ID:             id,
+		Account:        accountName,
+		Type:           actionType,
+		Payload:        payload,
+		Number:         number,
+		ActivateNumber: number + delay,
+	}
+	b, err := rlp.EncodeToBytes(change)
+	if err != nil {
+		return false, err
+	}
+	am.sdb.Put(acctManagerName, authorChangeKey(id), b)
+	if err := am.putUint64(authorChangeCounterKey, id); err != nil {
+		return false, err
+	}
+	if err := am.putIDList(accountKey, append(ids, id)); err != nil {
+		return false, err
+	}
+	blockKey := authorChangeBlockKey(change.ActivateNumber)
+	blockIDs, err := am.getIDList(blockKey)
+	if err != nil {
+		return false, err
+	}
+	return true, am.putIDList(blockKey, append(blockIDs, id))
+}
+
+// deleteAuthorChange removes the change from the queue.
+func (am *AccountManager) deleteAuthorChange(change *PendingAuthorChange) error {
+	accountID, err := am.GetAccountIDByName(change.Account)
+	if err != nil {
+		return err
+	}
+	am.sdb.Delete(acctManagerName, authorChangeKey(change.ID))
+	if err := am.removeID(authorChangeAccountKey(accountID), change.ID); err != nil {
+		return err
+	}
+	return am.removeID(authorChangeBlockKey(change.ActivateNumber), change.ID)
+}
+
+// CancelAuthorChange removes a queued author change of the account.
+func (am *AccountManager) CancelAuthorChange(accountName common.Name, id uint64) error {
+	change, err := am.getAuthorChange(id)
+	if err != nil {
+		return err
+	}
+	if change == nil || change.Account != accountName {
+		return fmt.Errorf("account %s pending author change %d not exist", accountName, id)
+	}
+	return am.deleteAuthorChange(change)
+}
+
+// GetPendingAuthorChanges returns the queued author changes of the account.
+func (am *AccountManager) GetPendingAuthorChanges(accountName common.Name) ([]*PendingAuthorChange, error) {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	ids, err := am.getIDList(authorChangeAccountKey(acct.GetAccountID()))
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*PendingAuthorChange, 0, len(ids))
+	for _, id := range ids {
+		change, err := am.getAuthorChange(id)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// ApplyAuthorChanges applies the author changes activated at the block number.
+// A change that can't be applied anymore is dropped.
+func (am *AccountManager) ApplyAuthorChanges(number uint64, curForkID uint64) error {
+	ids, err := am.getIDList(authorChangeBlockKey(number))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		change, err := am.getAuthorChange(id)
+		if err != nil {
+			return err
+		}
+		if change == nil {
+			continue
+		}
+		if err := am.deleteAuthorChange(change); err != nil {
+			return err
+		}
+		snap := am.sdb.Snapshot()
+		if err := am.applyAuthorChange(change, curForkID); err != nil {
+			am.sdb.RevertToSnapshot(snap)
+			log.Debug("Pending author change dropped", "account", change.Account, "id", change.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (am *AccountManager) applyAuthorChange(change *PendingAuthorChange, curForkID uint64) error {
+	switch change.Type {
+	case types.UpdateAccountAuthor:
+		var acctAuth AccountAuthorAction
+		if err := rlp.DecodeBytes(change.Payload, &acctAuth); err != nil {
+			return err
+		}
+		return am.UpdateAccountAuthor(change.Account, &acctAuth, curForkID)
+	case types.SetAccountAuthorDelay:
+		var delay AuthorDelayAction
+		if err := rlp.DecodeBytes(change.Payload, &delay); err != nil {
+			return err
+		}
+		return am.setAuthorDelay(change.Account, delay.Delay)
+	}
+	return ErrUnKnownTxType
+}
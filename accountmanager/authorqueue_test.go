@@ -0,0 +1,149 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_AuthorQueue(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := common.Name("queuetest")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("queuetest123456"))
+	if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	newKey := new(common.PubKey)
+	newKey.SetBytes([]byte("queuetest654321"))
+	payload, _ := rlp.EncodeToBytes(&AccountAuthorAction{
+		Threshold:     2,
+		AuthorActions: []*AuthorAction{{ActionType: AddAuthor, Author: common.NewAuthor(*newKey, 1)}},
+	})
+
+	// Without a delay the change is applied immediately
+	if queued, err := am.queueAuthorChange(name, types.UpdateAccountAuthor, payload, 1, params.ForkID5); err != nil || queued {
+		t.Fatalf("queued without delay: %v %v", queued, err)
+	}
+	if err := am.setAuthorDelay(name, 10); err != nil {
+		t.Fatal(err)
+	}
+	if delay, _ := am.GetAuthorDelay(name); delay != 10 {
+		t.Fatalf("delay mismatch: have %d, want 10", delay)
+	}
+
+	if queued, err := am.queueAuthorChange(name, types.UpdateAccountAuthor, payload, 1, params.ForkID5); err != nil || !queued {
+		t.Fatalf("change not queued: %v", err)
+	}
+	delayPayload, _ := rlp.EncodeToBytes(&AuthorDelayAction{Delay: 0})
+	if queued, err := am.queueAuthorChange(name, types.SetAccountAuthorDelay, delayPayload, 2, params.ForkID5); err != nil || !queued {
+		t.Fatalf("delay change not queued: %v", err)
+	}
+	changes, err := am.GetPendingAuthorChanges(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].ActivateNumber != 11 || changes[1].ActivateNumber != 12 {
+		t.Fatalf("pending changes mismatch: %v", changes)
+	}
+
+	// Cancel the delay change, apply the author change at its activation block
+	if err := am.CancelAuthorChange(name, changes[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.CancelAuthorChange(common.Name("fractal.founder"), changes[0].ID); err == nil {
+		t.Fatal("change of another account cancelled")
+	}
+	if err := am.ApplyAuthorChanges(10, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if acct, _ := am.GetAccountByName(name); len(acct.Authors) != 1 {
+		t.Fatalf("change applied before activation: %v", acct.Authors)
+	}
+	if err := am.ApplyAuthorChanges(11, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if acct, _ := am.GetAccountByName(name); len(acct.Authors) != 2 || acct.GetThreshold() != 2 {
+		t.Fatalf("change not applied: %v %d", acct.Authors, acct.GetThreshold())
+	}
+	if err := am.ApplyAuthorChanges(12, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if delay, _ := am.GetAuthorDelay(name); delay != 10 {
+		t.Fatalf("cancelled change applied, delay %d", delay)
+	}
+	if changes, _ := am.GetPendingAuthorChanges(name); len(changes) != 0 {
+		t.Fatalf("pending changes not removed: %v", changes)
+	}
+}
+
+func TestAccountManager_CancelAuthorThreshold(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 2; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+	}
+	name := common.Name("cancelthreshold")
+	if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, common.BytesToPubKey(crypto.FromECDSAPub(&keys[0].PublicKey)), ""); err != nil {
+		t.Fatal(err)
+	}
+	second := &AuthorAction{ActionType: AddAuthor, Author: common.NewAuthor(common.BytesToPubKey(crypto.FromECDSAPub(&keys[1].PublicKey)), 1)}
+	if err := am.UpdateAccountAuthor(name, &AccountAuthorAction{Threshold: 1, UpdateAuthorThreshold: 2, AuthorActions: []*AuthorAction{second}}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := types.NewSigner(big.NewInt(1))
+	sign := func(actionType types.ActionType, payload interface{}, indexes ...uint64) *types.Transaction {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, name, common.Name(params.DefaultChainconfig.AccountName), 0, 0, 0, big.NewInt(0), data, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		var pairs []*types.KeyPair
+		for _, index := range indexes {
+			pairs = append(pairs, types.MakeKeyPair(keys[index], []uint64{index}))
+		}
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, pairs); err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	// a single key meets the normal threshold but can't cancel queued author changes
+	if err := am.RecoverTx(signer, sign(types.UpdateAccount, &UpdataAccountAction{}, 0)); err != nil {
+		t.Fatalf("normal threshold rejected: %v", err)
+	}
+	if err := am.RecoverTx(signer, sign(types.CancelAccountAuthor, &CancelAuthorAction{ID: 1}, 0)); err == nil {
+		t.Fatal("cancel signed below the update author threshold")
+	}
+	if err := am.RecoverTx(signer, sign(types.CancelAccountAuthor, &CancelAuthorAction{ID: 1}, 0, 1)); err != nil {
+		t.Fatalf("cancel at the update author threshold rejected: %v", err)
+	}
+}
@@ -27,3 +27,43 @@ type Config struct {
 }
 
 const MaxDescriptionLength uint64 = 255
+
+// MaxAssetHolderMigration is the max number of accounts added to the asset
+// holder index in a block while the index is built.
+const MaxAssetHolderMigration uint64 = 1000
+
+// MaxSubAssetMigration is the max number of assets added to the sub-asset
+// index in a block while the index is built.
+const MaxSubAssetMigration uint64 = 1000
+
+// MaxAuthorDelay is the max number of blocks an author change can be delayed.
+const MaxAuthorDelay uint64 = 201600
+
+// MaxPendingAuthorChanges is the max number of queued author changes of an account.
+const MaxPendingAuthorChanges = 10
+
+// MaxProposalPeriod is the max number of blocks a proposal can be approved,
+// it is also the period of proposals without expiry.
+const MaxProposalPeriod uint64 = 201600
+
+// MaxPendingProposals is the max number of pending proposals of an account.
+const MaxPendingProposals = 10
+
+// MaxPendingProposalsPerAuthor is the max number of pending proposals of an
+// account proposed by the same author.
+const MaxPendingProposalsPerAuthor = 3
+
+// MaxVestingSchedules is the max number of vesting schedules of an account asset.
+const MaxVestingSchedules = 20
+
+// MaxVestingSchedulesPerSender is the max number of vesting schedules of an
+// account asset from the same sender.
+const MaxVestingSchedulesPerSender = 4
+
+// MinDividendPeriod is the min number of blocks the holders can claim a
+// dividend before the distributor reclaims it, it is also the period of
+// dividends without expiry.
+const MinDividendPeriod uint64 = 201600
+
+// MaxHTLCPreimageLength is the max length of a hash time lock preimage.
+const MaxHTLCPreimageLength = 64
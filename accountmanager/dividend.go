@@ -0,0 +1,246 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	dividendPrefix        = "dividend"
+	dividendClaimedPrefix = "dividendClaimed"
+	dividendCounterKey    = "dividendCounter"
+)
+
+// DistributeDividendAction distributes the action value to the holders of
+// the asset at the snapshot time pro rata, the holders claim their shares
+// with ClaimDividendAction until the Expire block number, zero means
+// MinDividendPeriod blocks. The distributor reclaims the outstanding amount
+// after the expiry with ReclaimDividendAction.
+type DistributeDividendAction struct {
+	AssetID uint64 `json:"assetId"`
+	Time    uint64 `json:"time"`
+	Expire  uint64 `json:"expire"`
+}
+
+// ClaimDividendAction transfers the share of the sender of a dividend.
+type ClaimDividendAction struct {
+	ID uint64 `json:"id"`
+}
+
+// ReclaimDividendAction transfers the outstanding amount of an expired
+// dividend back to its distributor.
+type ReclaimDividendAction struct {
+	ID uint64 `json:"id"`
+}
+
+// Dividend is a distribution of Amount of the payout asset to the holders of
+// the asset, Supply is the amount of the asset at the snapshot time and
+// Outstanding is the amount neither claimed nor reclaimed.
+type Dividend struct {
+	ID            uint64      `json:"id"`
+	Distributor   common.Name `json:"distributor"`
+	AssetID       uint64      `json:"assetId"`
+	Time          uint64      `json:"time"`
+	Supply        *big.Int    `json:"supply"`
+	PayoutAssetID uint64      `json:"payoutAssetId"`
+	Amount        *big.Int    `json:"amount"`
+	Claimed       *big.Int    `json:"claimed"`
+	Outstanding   *big.Int    `json:"outstanding"`
+	Number        uint64      `json:"number"`
+	Expire        uint64      `json:"expire"`
+}
+
+func dividendKey(id uint64) string {
+	return dividendPrefix + strconv.FormatUint(id, 10)
+}
+
+func dividendClaimedKey(id uint64, accountID uint64) string {
+	return dividendClaimedPrefix + strconv.FormatUint(id, 10) + "_" + strconv.FormatUint(accountID, 10)
+}
+
+// GetDividend returns the dividend by id.
+func (am *AccountManager) GetDividend(id uint64) (*Dividend, error) {
+	b, err := am.sdb.Get(acctManagerName, dividendKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("dividend %d not exist", id)
+	}
+	var dividend Dividend
+	if err := rlp.DecodeBytes(b, &dividend); err != nil {
+		return nil, err
+	}
+	return &dividend, nil
+}
+
+func (am *AccountManager) setDividend(dividend *Dividend) error {
+	b, err := rlp.EncodeToBytes(dividend)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, dividendKey(dividend.ID), b)
+	return nil
+}
+
+// distributeDividend records the payout the asset owner transferred to the account manager.
+func (am *AccountManager) distributeDividend(distributor common.Name, payoutAssetID uint64, value *big.Int, distribute *DistributeDividendAction, number uint64) (uint64, error) {
+	if value.Sign() <= 0 {
+		return 0, ErrAmountValueInvalid
+	}
+	if err := am.ast.CheckOwner(distributor, distribute.AssetID); err != nil {
+		return 0, err
+	}
+	if distribute.Expire == 0 {
+		distribute.Expire = number + MinDividendPeriod
+	}
+	if distribute.Expire < number+MinDividendPeriod {
+		return 0, fmt.Errorf("dividend expire must be at least %d blocks after %d", MinDividendPeriod, number)
+	}
+	supply, err := am.ast.GetAssetAmountByTime(distribute.AssetID, distribute.Time)
+	if err != nil {
+		return 0, err
+	}
+	if supply.Sign() <= 0 {
+		return 0, fmt.Errorf("asset %d has no supply at %d", distribute.AssetID, distribute.Time)
+	}
+	id, err := am.getUint64(dividendCounterKey)
+	if err != nil {
+		return 0, err
+	}
+	id++
+	if err := am.putUint64(dividendCounterKey, id); err != nil {
+		return 0, err
+	}
+	return id, am.setDividend(&Dividend{
+		ID:            id,
+		Distributor:   distributor,
+		AssetID:       distribute.AssetID,
+		Time:          distribute.Time,
+		Supply:        supply,
+		PayoutAssetID: payoutAssetID,
+		Amount:        new(big.Int).Set(value),
+		Claimed:       big.NewInt(0),
+		Outstanding:   new(big.Int).Set(value),
+		Number:        number,
+		Expire:        distribute.Expire,
+	})
+}
+
+// GetDividendShare returns the share of the account of the dividend and
+// whether it is claimed.
+func (am *AccountManager) GetDividendShare(id uint64, accountName common.Name) (*big.Int, bool, error) {
+	dividend, err := am.GetDividend(id)
+	if err != nil {
+		return nil, false, err
+	}
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, false, err
+	}
+	if accountID == 0 {
+		return nil, false, ErrAccountNotExist
+	}
+	claimed, err := am.getUint64(dividendClaimedKey(id, accountID))
+	if err != nil {
+		return nil, false, err
+	}
+	share, err := am.dividendShare(dividend, accountName)
+	return share, claimed != 0, err
+}
+
+func (am *AccountManager) dividendShare(dividend *Dividend, accountName common.Name) (*big.Int, error) {
+	acct, err := am.GetAccountByTime(accountName, dividend.Time)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return big.NewInt(0), nil
+	}
+	balance, err := acct.GetBalanceByID(dividend.AssetID)
+	if err == ErrAccountAssetNotExist {
+		return big.NewInt(0), nil
+	} else if err != nil {
+		return nil, err
+	}
+	share := new(big.Int).Mul(balance, dividend.Amount)
+	return share.Div(share, dividend.Supply), nil
+}
+
+// claimDividend transfers the share of the account from the account manager.
+func (am *AccountManager) claimDividend(manager common.Name, accountName common.Name, id uint64, number uint64) (*Dividend, *big.Int, error) {
+	dividend, err := am.GetDividend(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if number > dividend.Expire {
+		return nil, nil, fmt.Errorf("dividend %d expired at %d", id, dividend.Expire)
+	}
+	share, claimed, err := am.GetDividendShare(id, accountName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claimed {
+		return nil, nil, fmt.Errorf("account %s dividend %d already claimed", accountName, id)
+	}
+	if share.Sign() == 0 {
+		return nil, nil, fmt.Errorf("account %s has no share of dividend %d", accountName, id)
+	}
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := am.putUint64(dividendClaimedKey(id, accountID), 1); err != nil {
+		return nil, nil, err
+	}
+	dividend.Claimed = new(big.Int).Add(dividend.Claimed, share)
+	dividend.Outstanding = new(big.Int).Sub(dividend.Outstanding, share)
+	if err := am.setDividend(dividend); err != nil {
+		return nil, nil, err
+	}
+	return dividend, share, am.TransferAsset(manager, accountName, dividend.PayoutAssetID, share, number)
+}
+
+// reclaimDividend transfers the outstanding amount of the expired dividend
+// from the account manager to the distributor.
+func (am *AccountManager) reclaimDividend(manager common.Name, distributor common.Name, id uint64, number uint64) (*Dividend, *big.Int, error) {
+	dividend, err := am.GetDividend(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dividend.Distributor != distributor {
+		return nil, nil, fmt.Errorf("account %s is not the distributor of dividend %d", distributor, id)
+	}
+	if number <= dividend.Expire {
+		return nil, nil, fmt.Errorf("dividend %d not expired until %d", id, dividend.Expire)
+	}
+	outstanding := dividend.Outstanding
+	if outstanding.Sign() == 0 {
+		return nil, nil, fmt.Errorf("dividend %d has no outstanding amount", id)
+	}
+	dividend.Outstanding = big.NewInt(0)
+	if err := am.setDividend(dividend); err != nil {
+		return nil, nil, err
+	}
+	return dividend, outstanding, am.TransferAsset(manager, distributor, dividend.PayoutAssetID, outstanding, number)
+}
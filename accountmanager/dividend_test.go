@@ -0,0 +1,166 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_Dividend(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	cachedb := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, cachedb)
+	am, err := NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := common.Name(params.DefaultChainconfig.AccountName)
+	owner, holder, late := common.Name("dividendowner"), common.Name("dividendholder"), common.Name("dividendlate")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("dividendtest1234"))
+	if err := am.CreateAccount(common.Name("fractal"), manager, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{owner, holder} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("dividendasset", 0, 0, "da", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payoutID, err := am.ast.IssueAsset("dividendpayout", 0, 0, "dp", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []uint64{assetID, payoutID} {
+		if err := am.AddAccountBalanceByID(owner, id, big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(250), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// take a snapshot of the state
+	root, err := statedb.Commit(db.NewBatch(), common.Hash{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cachedb.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	rawdb.WriteSnapshot(db, types.SnapshotBlock{Number: 0, BlockHash: common.Hash{}}, types.SnapshotInfo{Root: root})
+	statedb, _ = state.New(root, cachedb)
+	time := uint64(100000000)
+	if err := snapshot.NewSnapshotManager(statedb).SetSnapshot(time, snapshot.BlockInfo{Number: 0, BlockHash: common.Hash{}, Timestamp: 0}); err != nil {
+		t.Fatal(err)
+	}
+	am, _ = NewAccountManager(statedb)
+
+	// balances after the snapshot don't count
+	if err := am.TransferAsset(owner, holder, assetID, big.NewInt(250), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.CreateAccount(common.Name("fractal.founder"), late, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(owner, late, assetID, big.NewInt(100), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	number := uint64(1)
+	process := func(from common.Name, actionType types.ActionType, value int64, payload interface{}) ([]*types.InternalAction, error) {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, from, manager, 0, payoutID, 0, big.NewInt(value), data, nil)
+		return am.Process(&types.AccountManagerContext{Action: action, Number: number, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+	}
+	if _, err := process(holder, types.DistributeDividend, 0, &DistributeDividendAction{AssetID: assetID, Time: time}); err == nil {
+		t.Fatal("dividend distributed by non owner")
+	}
+	if _, err := process(owner, types.DistributeDividend, 400, &DistributeDividendAction{AssetID: assetID, Time: time, Expire: MinDividendPeriod}); err == nil {
+		t.Fatal("dividend expires before the min period")
+	}
+	if _, err := process(owner, types.DistributeDividend, 400, &DistributeDividendAction{AssetID: assetID, Time: time}); err != nil {
+		t.Fatal(err)
+	}
+	dividend, err := am.GetDividend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dividend.Supply.Cmp(big.NewInt(1000)) != 0 || dividend.Amount.Cmp(big.NewInt(400)) != 0 || dividend.Expire != 1+MinDividendPeriod {
+		t.Fatalf("dividend mismatch: %v", dividend)
+	}
+
+	internal, err := process(holder, types.ClaimDividend, 0, &ClaimDividendAction{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(internal) != 1 || internal[0].ActionType != "dividend" {
+		t.Fatalf("internal actions mismatch: %v", internal)
+	}
+	if balance, _ := am.GetAccountBalanceByID(holder, payoutID, 0); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 100", balance)
+	}
+	if _, err := process(holder, types.ClaimDividend, 0, &ClaimDividendAction{ID: 1}); err == nil {
+		t.Fatal("dividend claimed twice")
+	}
+	if _, err := process(late, types.ClaimDividend, 0, &ClaimDividendAction{ID: 1}); err == nil {
+		t.Fatal("dividend claimed by account created after the snapshot")
+	}
+	if share, claimed, _ := am.GetDividendShare(1, owner); share.Cmp(big.NewInt(300)) != 0 || claimed {
+		t.Fatalf("share mismatch: have %d %v, want 300 false", share, claimed)
+	}
+
+	// the distributor reclaims the unclaimed shares after the expiry
+	if _, err := process(owner, types.ReclaimDividend, 0, &ReclaimDividendAction{ID: 1}); err == nil {
+		t.Fatal("dividend reclaimed before expiry")
+	}
+	number = 2 + MinDividendPeriod
+	if _, err := process(owner, types.ClaimDividend, 0, &ClaimDividendAction{ID: 1}); err == nil {
+		t.Fatal("dividend claimed after expiry")
+	}
+	if _, err := process(holder, types.ReclaimDividend, 0, &ReclaimDividendAction{ID: 1}); err == nil {
+		t.Fatal("dividend reclaimed by non distributor")
+	}
+	internal, err = process(owner, types.ReclaimDividend, 0, &ReclaimDividendAction{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(internal) != 1 || internal[0].ActionType != "dividendreclaim" {
+		t.Fatalf("internal actions mismatch: %v", internal)
+	}
+	if balance, _ := am.GetAccountBalanceByID(owner, payoutID, 0); balance.Cmp(big.NewInt(900)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 900", balance)
+	}
+	if dividend, _ := am.GetDividend(1); dividend.Outstanding.Sign() != 0 || dividend.Claimed.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("dividend mismatch: %v", dividend)
+	}
+	if _, err := process(owner, types.ReclaimDividend, 0, &ReclaimDividendAction{ID: 1}); err == nil {
+		t.Fatal("dividend reclaimed twice")
+	}
+}
@@ -52,4 +52,12 @@ var (
 	ErrNegativeAmount         = errors.New("negative amount")
 	ErrAmountMustBeZero       = errors.New("amount must be zero")
 	ErrAssetOwnerInvalid      = errors.New("asset owner Invalid ")
+	ErrHolderIndexNotExist    = errors.New("asset holder index not exist")
+	ErrAuthorOutOfScope       = errors.New("author out of scope")
+	ErrScopeLimitExceeded     = errors.New("author epoch limit exceeded")
+	ErrScopeNotSupported      = errors.New("author scope not supported")
+	ErrAllowanceExceeded      = errors.New("allowance exceeded")
+	ErrFreezeSysToken         = errors.New("system token can not be frozen")
+	ErrFreezeSysAccount       = errors.New("system account can not be frozen")
+	ErrVestingNotExist        = errors.New("vesting schedule not exist")
 )
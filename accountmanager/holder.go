@@ -0,0 +1,434 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/snapshot"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	assetHolderCountPrefix    = "assetHolderCount"
+	assetHolderNodePrefix     = "assetHolderNode"
+	assetHolderIndexInitKey   = "assetHolderIndexInit"
+	assetHolderIndexCursorKey = "assetHolderIndexCursor"
+)
+
+// assetHolderMaxLevel is the number of levels of the holder skip lists.
+const assetHolderMaxLevel = 16
+
+// AssetHolder is an account with a positive balance of an asset.
+type AssetHolder struct {
+	Name    common.Name `json:"name"`
+	Balance *big.Int    `json:"balance"`
+}
+
+// The holders of an asset are stored as a skip list sorted by balance in
+// descending order and by name, the node of a holder is kept under
+// assetHolderNodePrefix + assetID + "_" + name and the head of the list
+// under the empty name. Next and Span are the following holder and the
+// number of positions to it at every level of the node, the end of the list
+// is at position count + 1. The level of a node is derived from its key so
+// the list is the same on every node.
+type assetHolderNode struct {
+	Balance *big.Int
+	Next    []string
+	Span    []uint64
+}
+
+func assetHolderCountKey(assetID uint64) string {
+	return assetHolderCountPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func assetHolderNodeKey(assetID uint64, name common.Name) string {
+	return assetHolderNodePrefix + strconv.FormatUint(assetID, 10) + "_" + name.String()
+}
+
+// assetHolderLevel returns the level of the holder node, a level is kept
+// with probability 1/4.
+func assetHolderLevel(assetID uint64, name common.Name) int {
+	level := 1
+	for _, b := range crypto.Keccak256([]byte(assetHolderNodeKey(assetID, name))) {
+		for shift := uint(0); shift < 8; shift += 2 {
+			if b>>shift&3 != 0 || level == assetHolderMaxLevel {
+				return level
+			}
+			level++
+		}
+	}
+	return level
+}
+
+// holderBefore returns whether holder a is sorted before holder b.
+func holderBefore(a common.Name, balanceA *big.Int, b common.Name, balanceB *big.Int) bool {
+	if c := balanceA.Cmp(balanceB); c != 0 {
+		return c > 0
+	}
+	return a < b
+}
+
+// assetHolderList caches the nodes of the holder skip list of an asset read
+// and changed by an update.
+type assetHolderList struct {
+	am      *AccountManager
+	assetID uint64
+	nodes   map[common.Name]*assetHolderNode
+	dirty   map[common.Name]bool
+}
+
+func (am *AccountManager) assetHolderList(assetID uint64) *assetHolderList {
+	return &assetHolderList{am: am, assetID: assetID, nodes: make(map[common.Name]*assetHolderNode), dirty: make(map[common.Name]bool)}
+}
+
+// node returns the node of the holder, nil if it is not in the list. The
+// head is created if the list is empty.
+func (l *assetHolderList) node(name common.Name) (*assetHolderNode, error) {
+	if node, ok := l.nodes[name]; ok {
+		return node, nil
+	}
+	b, err := l.am.sdb.Get(acctManagerName, assetHolderNodeKey(l.assetID, name))
+	if err != nil {
+		return nil, err
+	}
+	var node *assetHolderNode
+	if len(b) != 0 {
+		node = new(assetHolderNode)
+		if err := rlp.DecodeBytes(b, node); err != nil {
+			return nil, err
+		}
+	} else if name == "" {
+		node = &assetHolderNode{Balance: new(big.Int), Next: make([]string, assetHolderMaxLevel), Span: make([]uint64, assetHolderMaxLevel)}
+		for i := range node.Span {
+			node.Span[i] = 1
+		}
+	}
+	l.nodes[name] = node
+	return node, nil
+}
+
+// predecessors returns the last holders sorted before the holder at every
+// level and their positions.
+func (l *assetHolderList) predecessors(name common.Name, balance *big.Int) ([]common.Name, []uint64, error) {
+	update := make([]common.Name, assetHolderMaxLevel)
+	rank := make([]uint64, assetHolderMaxLevel)
+	x := common.Name("")
+	xn, err := l.node(x)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := assetHolderMaxLevel - 1; i >= 0; i-- {
+		if i < assetHolderMaxLevel-1 {
+			rank[i] = rank[i+1]
+		}
+		for xn.Next[i] != "" {
+			next := common.Name(xn.Next[i])
+			nn, err := l.node(next)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !holderBefore(next, nn.Balance, name, balance) {
+				break
+			}
+			rank[i] += xn.Span[i]
+			x, xn = next, nn
+		}
+		update[i] = x
+	}
+	return update, rank, nil
+}
+
+func (l *assetHolderList) insert(name common.Name, balance *big.Int) error {
+	update, rank, err := l.predecessors(name, balance)
+	if err != nil {
+		return err
+	}
+	level := assetHolderLevel(l.assetID, name)
+	node := &assetHolderNode{Balance: balance, Next: make([]string, level), Span: make([]uint64, level)}
+	for i := 0; i < assetHolderMaxLevel; i++ {
+		prev := l.nodes[update[i]]
+		if i < level {
+			node.Next[i] = prev.Next[i]
+			node.Span[i] = prev.Span[i] - (rank[0] - rank[i])
+			prev.Next[i] = name.String()
+			prev.Span[i] = rank[0] - rank[i] + 1
+		} else {
+			prev.Span[i]++
+		}
+		l.dirty[update[i]] = true
+	}
+	l.nodes[name] = node
+	l.dirty[name] = true
+	return nil
+}
+
+func (l *assetHolderList) remove(name common.Name, node *assetHolderNode) error {
+	update, _, err := l.predecessors(name, node.Balance)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < assetHolderMaxLevel; i++ {
+		prev := l.nodes[update[i]]
+		if i < len(node.Next) {
+			prev.Span[i] += node.Span[i] - 1
+			prev.Next[i] = node.Next[i]
+		} else {
+			prev.Span[i]--
+		}
+		l.dirty[update[i]] = true
+	}
+	l.nodes[name] = nil
+	l.dirty[name] = true
+	return nil
+}
+
+// at returns the holder at the position starting from 1.
+func (l *assetHolderList) at(rank uint64) (common.Name, *assetHolderNode, error) {
+	x := common.Name("")
+	xn, err := l.node(x)
+	if err != nil {
+		return "", nil, err
+	}
+	var traversed uint64
+	for i := assetHolderMaxLevel - 1; i >= 0; i-- {
+		for xn.Next[i] != "" && traversed+xn.Span[i] <= rank {
+			traversed += xn.Span[i]
+			x = common.Name(xn.Next[i])
+			if xn, err = l.node(x); err != nil {
+				return "", nil, err
+			}
+		}
+		if traversed == rank {
+			return x, xn, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// flush writes the changed nodes.
+func (l *assetHolderList) flush() error {
+	names := make([]string, 0, len(l.dirty))
+	for name := range l.dirty {
+		names = append(names, name.String())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := assetHolderNodeKey(l.assetID, common.Name(name))
+		node := l.nodes[common.Name(name)]
+		if node == nil {
+			l.am.sdb.Delete(acctManagerName, key)
+			continue
+		}
+		b, err := rlp.EncodeToBytes(node)
+		if err != nil {
+			return err
+		}
+		l.am.sdb.Put(acctManagerName, key, b)
+	}
+	return nil
+}
+
+func (am *AccountManager) getUint64(key string) (uint64, error) {
+	b, err := am.sdb.Get(acctManagerName, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var value uint64
+	if err := rlp.DecodeBytes(b, &value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+func (am *AccountManager) putUint64(key string, value uint64) error {
+	b, err := rlp.EncodeToBytes(value)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, key, b)
+	return nil
+}
+
+// assetHolderIndexEnabled returns whether the asset holder index was built.
+func (am *AccountManager) assetHolderIndexEnabled() (bool, error) {
+	b, err := am.sdb.Get(acctManagerName, assetHolderIndexInitKey)
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+// assetHolderIndexed returns whether the balances of the account are in the
+// asset holder index, accounts are indexed in id order while it is built.
+func (am *AccountManager) assetHolderIndexed(accountID uint64) (bool, error) {
+	if enabled, err := am.assetHolderIndexEnabled(); err != nil || enabled {
+		return enabled, err
+	}
+	cursor, err := am.getUint64(assetHolderIndexCursorKey)
+	if err != nil {
+		return false, err
+	}
+	return accountID < cursor, nil
+}
+
+// InitAssetHolderIndex indexes the holders of every asset from the current
+// balances at once, it is only used for the genesis block.
+func (am *AccountManager) InitAssetHolderIndex() error {
+	return am.MigrateAssetHolderIndex(math.MaxUint64)
+}
+
+// MigrateAssetHolderIndex indexes the holders of at most limit accounts
+// following the ones indexed before. It is called at the end of every block
+// after the fork until all the accounts are indexed.
+func (am *AccountManager) MigrateAssetHolderIndex(limit uint64) error {
+	if enabled, err := am.assetHolderIndexEnabled(); err != nil || enabled {
+		return err
+	}
+	cursor, err := am.getUint64(assetHolderIndexCursorKey)
+	if err != nil {
+		return err
+	}
+	if cursor == 0 {
+		cursor = counterID + 1
+	}
+	accountCounter, err := am.getAccountCounter()
+	if err != nil {
+		return err
+	}
+	for n := uint64(0); cursor <= accountCounter && n < limit; cursor, n = cursor+1, n+1 {
+		acct, err := am.GetAccountById(cursor)
+		if err != nil {
+			return err
+		}
+		if acct == nil {
+			continue
+		}
+		for _, balance := range acct.GetBalancesList() {
+			if err := am.setAssetHolder(acct.GetName(), balance.AssetID, balance.Balance); err != nil {
+				return err
+			}
+		}
+	}
+	if cursor <= accountCounter {
+		return am.putUint64(assetHolderIndexCursorKey, cursor)
+	}
+	am.sdb.Delete(acctManagerName, assetHolderIndexCursorKey)
+	am.sdb.Put(acctManagerName, assetHolderIndexInitKey, []byte{1})
+	return nil
+}
+
+// updateAssetHolder updates the holders of the asset with the balance of the account.
+func (am *AccountManager) updateAssetHolder(acct *Account, assetID uint64) error {
+	if indexed, err := am.assetHolderIndexed(acct.GetAccountID()); err != nil || !indexed {
+		return err
+	}
+	balance, _ := acct.GetBalanceByID(assetID)
+	return am.setAssetHolder(acct.GetName(), assetID, balance)
+}
+
+// setAssetHolder moves the account to the position of the balance in the
+// holders of the asset when the balance is positive and removes it otherwise.
+func (am *AccountManager) setAssetHolder(name common.Name, assetID uint64, balance *big.Int) error {
+	list := am.assetHolderList(assetID)
+	node, err := list.node(name)
+	if err != nil {
+		return err
+	}
+	if node != nil && node.Balance.Cmp(balance) == 0 {
+		return nil
+	}
+	count, err := am.getUint64(assetHolderCountKey(assetID))
+	if err != nil {
+		return err
+	}
+	if node != nil {
+		if err := list.remove(name, node); err != nil {
+			return err
+		}
+		count--
+	}
+	if balance.Sign() > 0 {
+		if err := list.insert(name, new(big.Int).Set(balance)); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := list.flush(); err != nil {
+		return err
+	}
+	return am.putUint64(assetHolderCountKey(assetID), count)
+}
+
+// GetAssetHolders returns at most limit holders of the asset starting at the
+// cursor sorted by balance in descending order and by name, and the number
+// of holders of the asset.
+func (am *AccountManager) GetAssetHolders(assetID uint64, cursor uint64, limit uint64) ([]*AssetHolder, uint64, error) {
+	if _, err := am.ast.GetAssetObjectByID(assetID); err != nil {
+		return nil, 0, err
+	}
+	if enabled, err := am.assetHolderIndexEnabled(); err != nil {
+		return nil, 0, err
+	} else if !enabled {
+		return nil, 0, ErrHolderIndexNotExist
+	}
+	count, err := am.getUint64(assetHolderCountKey(assetID))
+	if err != nil {
+		return nil, 0, err
+	}
+	holders := make([]*AssetHolder, 0)
+	if cursor >= count || limit == 0 {
+		return holders, count, nil
+	}
+	list := am.assetHolderList(assetID)
+	name, node, err := list.at(cursor + 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	for node != nil && uint64(len(holders)) < limit {
+		holders = append(holders, &AssetHolder{Name: name, Balance: node.Balance})
+		if node.Next[0] == "" {
+			break
+		}
+		name = common.Name(node.Next[0])
+		if node, err = list.node(name); err != nil {
+			return nil, 0, err
+		}
+	}
+	return holders, count, nil
+}
+
+// GetAssetHoldersByTime returns at most limit holders of the asset at the
+// snapshot time starting at the cursor and the number of holders of the asset.
+func (am *AccountManager) GetAssetHoldersByTime(assetID uint64, time uint64, cursor uint64, limit uint64) ([]*AssetHolder, uint64, error) {
+	snapshotManager := snapshot.NewSnapshotManager(am.sdb)
+	statedb, err := snapshotManager.GetSnapshotState(time)
+	if err != nil {
+		return nil, 0, err
+	}
+	snapshotAm := &AccountManager{sdb: statedb, ast: asset.NewAsset(statedb)}
+	return snapshotAm.GetAssetHolders(assetID, cursor, limit)
+}
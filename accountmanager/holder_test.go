@@ -0,0 +1,201 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAccountManager_AssetHolders(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey := new(common.PubKey)
+	names := []common.Name{"holdertest1", "holdertest2", "holdertest3"}
+	for _, name := range names {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("holderasset", 0, 0, "ha", big.NewInt(1000), 0, names[0], names[0], big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(names[0], assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := am.GetAssetHolders(assetID, 0, 10); err != ErrHolderIndexNotExist {
+		t.Fatalf("holders before index init: have %v, want %v", err, ErrHolderIndexNotExist)
+	}
+	if err := am.InitAssetHolderIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := am.TransferAsset(names[0], names[1], assetID, big.NewInt(300), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.TransferAsset(names[0], names[2], assetID, big.NewInt(300), 0); err != nil {
+		t.Fatal(err)
+	}
+	holders, total, err := am.GetAssetHolders(assetID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []AssetHolder{{names[0], big.NewInt(400)}, {names[1], big.NewInt(300)}, {names[2], big.NewInt(300)}}
+	if len(holders) != len(want) || total != uint64(len(want)) {
+		t.Fatalf("holders mismatch: have %d/%d, want %d", len(holders), total, len(want))
+	}
+	for i, h := range holders {
+		if h.Name != want[i].Name || h.Balance.Cmp(want[i].Balance) != 0 {
+			t.Fatalf("holder %d mismatch: have %v %v, want %v %v", i, h.Name, h.Balance, want[i].Name, want[i].Balance)
+		}
+	}
+	if holders, total, _ := am.GetAssetHolders(assetID, 1, 1); len(holders) != 1 || total != 3 || holders[0].Name != names[1] {
+		t.Fatalf("holders page mismatch: %v %d", holders, total)
+	}
+
+	// An emptied account is removed and the receiver moves up
+	if err := am.TransferAsset(names[0], names[1], assetID, big.NewInt(400), 0); err != nil {
+		t.Fatal(err)
+	}
+	holders, _, err = am.GetAssetHolders(assetID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(holders) != 2 || holders[0].Name != names[1] || holders[1].Name != names[2] {
+		t.Fatalf("holders after empty mismatch: %v", holders)
+	}
+	if err := am.SubAccountBalanceByID(names[2], assetID, big.NewInt(300)); err != nil {
+		t.Fatal(err)
+	}
+	if holders, _, err = am.GetAssetHolders(assetID, 0, 10); err != nil || len(holders) != 1 || holders[0].Balance.Cmp(big.NewInt(700)) != 0 {
+		t.Fatalf("holders after sub mismatch: %v %v", holders, err)
+	}
+}
+
+func TestAccountManager_AssetHolderOrder(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := common.Name("ordertest")
+	if err := am.CreateAccount(common.Name("fractal.founder"), owner, common.Name(""), 0, 0, common.PubKey{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	assetID, err := am.ast.IssueAsset("orderasset", 0, 0, "oa", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.InitAssetHolderIndex(); err != nil {
+		t.Fatal(err)
+	}
+	balances := make(map[common.Name]*big.Int)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		name := common.Name(fmt.Sprintf("holder%d", rnd.Intn(200)))
+		balance := big.NewInt(int64(rnd.Intn(20)))
+		if err := am.setAssetHolder(name, assetID, balance); err != nil {
+			t.Fatal(err)
+		}
+		if balance.Sign() > 0 {
+			balances[name] = balance
+		} else {
+			delete(balances, name)
+		}
+	}
+	want := make([]*AssetHolder, 0, len(balances))
+	for name, balance := range balances {
+		want = append(want, &AssetHolder{Name: name, Balance: balance})
+	}
+	sort.Slice(want, func(i, j int) bool {
+		return holderBefore(want[i].Name, want[i].Balance, want[j].Name, want[j].Balance)
+	})
+
+	// every page starts at its position in the sorted holders
+	list := am.assetHolderList(assetID)
+	for cursor := uint64(0); cursor < uint64(len(want)); cursor += 7 {
+		name, node, err := list.at(cursor + 1)
+		if err != nil || name != want[cursor].Name || node.Balance.Cmp(want[cursor].Balance) != 0 {
+			t.Fatalf("holder at %d mismatch: %v %v, want %v", cursor, name, err, want[cursor].Name)
+		}
+		holders, total, err := am.GetAssetHolders(assetID, cursor, 7)
+		if err != nil || total != uint64(len(want)) {
+			t.Fatalf("holders page %d mismatch: %d %v", cursor, total, err)
+		}
+		for i, h := range holders {
+			if h.Name != want[cursor+uint64(i)].Name || h.Balance.Cmp(want[cursor+uint64(i)].Balance) != 0 {
+				t.Fatalf("holder %d mismatch: %v %v", cursor+uint64(i), h.Name, h.Balance)
+			}
+		}
+	}
+}
+
+func TestAccountManager_MigrateAssetHolderIndex(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey := new(common.PubKey)
+	names := []common.Name{"migratetest1", "migratetest2", "migratetest3"}
+	for _, name := range names {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("migrateasset", 0, 0, "ma", big.NewInt(1000), 0, names[0], names[0], big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(names[0], assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first block indexes the first account only
+	if err := am.MigrateAssetHolderIndex(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := am.GetAssetHolders(assetID, 0, 10); err != ErrHolderIndexNotExist {
+		t.Fatalf("holders while building: have %v, want %v", err, ErrHolderIndexNotExist)
+	}
+	// Balances of indexed accounts are updated, the others are indexed later
+	if err := am.TransferAsset(names[0], names[2], assetID, big.NewInt(1000), 0); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if err := am.MigrateAssetHolderIndex(1); err != nil {
+			t.Fatal(err)
+		}
+		if enabled, _ := am.assetHolderIndexEnabled(); enabled {
+			break
+		}
+	}
+	holders, total, err := am.GetAssetHolders(assetID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(holders) != 1 || holders[0].Name != names[2] || holders[0].Balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("holders mismatch: %v %d", holders, total)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	htlcPrefix          = "htlc"
+	htlcHashPrefix      = "htlcHash"
+	htlcHashCountPrefix = "htlcHashCount"
+	htlcCounterKey      = "htlcCounter"
+)
+
+// HTLC status
+const (
+	HTLCLocked uint64 = iota
+	HTLCClaimed
+	HTLCRefunded
+)
+
+// LockHTLCAction locks the action value for the recipient under the sha256
+// hash of a preimage until the Expire block.
+type LockHTLCAction struct {
+	Recipient common.Name `json:"recipient"`
+	HashLock  common.Hash `json:"hashLock"`
+	Expire    uint64      `json:"expire"`
+}
+
+// ClaimHTLCAction transfers a locked value to its recipient, it is accepted
+// from any account with the preimage of the hash lock until the expiry.
+type ClaimHTLCAction struct {
+	ID       uint64 `json:"id"`
+	Preimage []byte `json:"preimage"`
+}
+
+// RefundHTLCAction transfers an expired locked value back to its sender.
+type RefundHTLCAction struct {
+	ID uint64 `json:"id"`
+}
+
+// HTLC is a hash time-locked value, Preimage is set once claimed.
+type HTLC struct {
+	ID        uint64      `json:"id"`
+	Sender    common.Name `json:"sender"`
+	Recipient common.Name `json:"recipient"`
+	AssetID   uint64      `json:"assetId"`
+	Amount    *big.Int    `json:"amount"`
+	HashLock  common.Hash `json:"hashLock"`
+	Expire    uint64      `json:"expire"`
+	Number    uint64      `json:"number"`
+	Status    uint64      `json:"status"`
+	Preimage  []byte      `json:"preimage"`
+}
+
+func htlcKey(id uint64) string {
+	return htlcPrefix + strconv.FormatUint(id, 10)
+}
+
+// The ids locked under a hash are stored one per key, id i is kept under
+// htlcHashPrefix + hash + "_" + i, so that a lock writes a constant number of keys.
+func htlcHashCountKey(hashLock common.Hash) string {
+	return htlcHashCountPrefix + hashLock.Hex()
+}
+
+func htlcHashKey(hashLock common.Hash, index uint64) string {
+	return htlcHashPrefix + hashLock.Hex() + "_" + strconv.FormatUint(index, 10)
+}
+
+// GetHTLC returns the hash time-locked value by id.
+func (am *AccountManager) GetHTLC(id uint64) (*HTLC, error) {
+	b, err := am.sdb.Get(acctManagerName, htlcKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("htlc %d not exist", id)
+	}
+	var htlc HTLC
+	if err := rlp.DecodeBytes(b, &htlc); err != nil {
+		return nil, err
+	}
+	return &htlc, nil
+}
+
+// GetHTLCsByHash returns at most limit hash time-locked values locked under
+// the hash in lock order starting at the cursor, and the number of them.
+func (am *AccountManager) GetHTLCsByHash(hashLock common.Hash, cursor uint64, limit uint64) ([]*HTLC, uint64, error) {
+	count, err := am.getUint64(htlcHashCountKey(hashLock))
+	if err != nil {
+		return nil, 0, err
+	}
+	htlcs := make([]*HTLC, 0)
+	for i := cursor; i < count && uint64(len(htlcs)) < limit; i++ {
+		id, err := am.getUint64(htlcHashKey(hashLock, i))
+		if err != nil {
+			return nil, 0, err
+		}
+		htlc, err := am.GetHTLC(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		htlcs = append(htlcs, htlc)
+	}
+	return htlcs, count, nil
+}
+
+func (am *AccountManager) setHTLC(htlc *HTLC) error {
+	b, err := rlp.EncodeToBytes(htlc)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, htlcKey(htlc.ID), b)
+	return nil
+}
+
+// lockHTLC records the value the sender transferred to the account manager.
+func (am *AccountManager) lockHTLC(sender common.Name, assetID uint64, value *big.Int, lock *LockHTLCAction, number uint64) (uint64, error) {
+	if value.Sign() <= 0 {
+		return 0, ErrAmountValueInvalid
+	}
+	if lock.HashLock == (common.Hash{}) {
+		return 0, ErrHashIsEmpty
+	}
+	if lock.Expire <= number {
+		return 0, fmt.Errorf("htlc expire must be after block %d", number)
+	}
+	if acct, err := am.GetAccountByName(lock.Recipient); err != nil {
+		return 0, err
+	} else if acct == nil {
+		return 0, ErrAccountNotExist
+	}
+	id, err := am.getUint64(htlcCounterKey)
+	if err != nil {
+		return 0, err
+	}
+	id++
+	if err := am.putUint64(htlcCounterKey, id); err != nil {
+		return 0, err
+	}
+	htlc := &HTLC{
+		ID:        id,
+		Sender:    sender,
+		Recipient: lock.Recipient,
+		AssetID:   assetID,
+		Amount:    new(big.Int).Set(value),
+		HashLock:  lock.HashLock,
+		Expire:    lock.Expire,
+		Number:    number,
+	}
+	if err := am.setHTLC(htlc); err != nil {
+		return 0, err
+	}
+	count, err := am.getUint64(htlcHashCountKey(lock.HashLock))
+	if err != nil {
+		return 0, err
+	}
+	if err := am.putUint64(htlcHashKey(lock.HashLock, count), id); err != nil {
+		return 0, err
+	}
+	return id, am.putUint64(htlcHashCountKey(lock.HashLock), count+1)
+}
+
+// claimHTLC reveals the preimage and transfers the locked value from the account manager to the recipient.
+func (am *AccountManager) claimHTLC(manager common.Name, claim *ClaimHTLCAction, number uint64) (*HTLC, error) {
+	htlc, err := am.GetHTLC(claim.ID)
+	if err != nil {
+		return nil, err
+	}
+	if htlc.Status != HTLCLocked {
+		return nil, fmt.Errorf("htlc %d not locked", htlc.ID)
+	}
+	if number > htlc.Expire {
+		return nil, fmt.Errorf("htlc %d expired at %d", htlc.ID, htlc.Expire)
+	}
+	if len(claim.Preimage) > MaxHTLCPreimageLength || common.Hash(sha256.Sum256(claim.Preimage)) != htlc.HashLock {
+		return nil, fmt.Errorf("htlc %d preimage mismatch", htlc.ID)
+	}
+	htlc.Status = HTLCClaimed
+	htlc.Preimage = claim.Preimage
+	if err := am.setHTLC(htlc); err != nil {
+		return nil, err
+	}
+	return htlc, am.TransferAsset(manager, htlc.Recipient, htlc.AssetID, htlc.Amount, number)
+}
+
+// refundHTLC transfers the expired locked value from the account manager back to the sender.
+func (am *AccountManager) refundHTLC(manager common.Name, refund *RefundHTLCAction, number uint64) (*HTLC, error) {
+	htlc, err := am.GetHTLC(refund.ID)
+	if err != nil {
+		return nil, err
+	}
+	if htlc.Status != HTLCLocked {
+		return nil, fmt.Errorf("htlc %d not locked", htlc.ID)
+	}
+	if number <= htlc.Expire {
+		return nil, fmt.Errorf("htlc %d not expired until %d", htlc.ID, htlc.Expire)
+	}
+	htlc.Status = HTLCRefunded
+	if err := am.setHTLC(htlc); err != nil {
+		return nil, err
+	}
+	return htlc, am.TransferAsset(manager, htlc.Sender, htlc.AssetID, htlc.Amount, number)
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_HTLC(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := common.Name(params.DefaultChainconfig.AccountName)
+	sender, recipient := common.Name("htlcsender"), common.Name("htlcrecipient")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("htlctest12345678"))
+	if err := am.CreateAccount(common.Name("fractal"), manager, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{sender, recipient} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("htlcasset", 0, 0, "ha", big.NewInt(1000), 0, sender, sender, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(sender, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	process := func(from common.Name, actionType types.ActionType, value int64, payload interface{}, number uint64) ([]*types.InternalAction, error) {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, from, manager, 0, assetID, 0, big.NewInt(value), data, nil)
+		return am.Process(&types.AccountManagerContext{Action: action, Number: number, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+	}
+	preimage := []byte("htlc secret")
+	hashLock := common.Hash(sha256.Sum256(preimage))
+	for i := 0; i < 2; i++ {
+		if _, err := process(sender, types.LockHTLC, 100, &LockHTLCAction{Recipient: recipient, HashLock: hashLock, Expire: 20}, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	htlcs, total, err := am.GetHTLCsByHash(hashLock, 0, 10)
+	if err != nil || len(htlcs) != 2 || total != 2 {
+		t.Fatalf("htlcs mismatch: %v %d %v", htlcs, total, err)
+	}
+	if htlcs, _, _ = am.GetHTLCsByHash(hashLock, 1, 1); len(htlcs) != 1 || htlcs[0].ID != 2 {
+		t.Fatalf("htlcs page mismatch: %v", htlcs)
+	}
+	if balance, _ := am.GetAccountBalanceByID(sender, assetID, 0); balance.Cmp(big.NewInt(800)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 800", balance)
+	}
+
+	if _, err := process(recipient, types.ClaimHTLC, 0, &ClaimHTLCAction{ID: 1, Preimage: []byte("wrong")}, 11); err == nil {
+		t.Fatal("claimed with wrong preimage")
+	}
+	if _, err := process(sender, types.RefundHTLC, 0, &RefundHTLCAction{ID: 1}, 20); err == nil {
+		t.Fatal("refunded before expiry")
+	}
+	internal, err := process(recipient, types.ClaimHTLC, 0, &ClaimHTLCAction{ID: 1, Preimage: preimage}, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(internal) != 1 || internal[0].ActionType != "htlcclaim" || !bytes.Equal(internal[0].Action.Payload, preimage) {
+		t.Fatalf("internal actions mismatch: %v", internal)
+	}
+	if balance, _ := am.GetAccountBalanceByID(recipient, assetID, 0); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 100", balance)
+	}
+	if htlc, _ := am.GetHTLC(1); htlc.Status != HTLCClaimed || !bytes.Equal(htlc.Preimage, preimage) {
+		t.Fatalf("htlc not claimed: %v", htlc)
+	}
+
+	// Expired locks can only be refunded
+	if _, err := process(recipient, types.ClaimHTLC, 0, &ClaimHTLCAction{ID: 2, Preimage: preimage}, 21); err == nil {
+		t.Fatal("claimed after expiry")
+	}
+	if _, err := process(sender, types.RefundHTLC, 0, &RefundHTLCAction{ID: 2}, 21); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(sender, assetID, 0); balance.Cmp(big.NewInt(900)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 900", balance)
+	}
+	if _, err := process(sender, types.RefundHTLC, 0, &RefundHTLCAction{ID: 2}, 21); err == nil {
+		t.Fatal("refunded twice")
+	}
+}
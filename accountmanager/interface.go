@@ -68,8 +68,8 @@ type IAccountManager interface {
 	IssueAsset(asset *asset.AssetObject) error
 	IncreaseAsset(accountName common.Name, assetID uint64, amount *big.Int) error
 	//
-	CanTransfer(accountName common.Name, assetId uint64, value *big.Int) (bool, error)
-	TransferAsset(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int) error
+	CanTransfer(accountName common.Name, assetId uint64, value *big.Int, number uint64) (bool, error)
+	TransferAsset(fromAccount common.Name, toAccount common.Name, assetID uint64, value *big.Int, number uint64) error
 	IncAsset2Acct(fromName common.Name, toName common.Name, assetId uint64, amount *big.Int) error
 	AddBalanceByName(accountName common.Name, assetID uint64, amount *big.Int) error
 	Process(action *types.Action) error
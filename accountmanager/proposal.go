@@ -0,0 +1,377 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	proposalPrefix        = "proposal"
+	proposalAccountPrefix = "proposalAccount"
+	proposalCounterKey    = "proposalCounter"
+)
+
+// proposalActionTypes are the action types a proposal can execute.
+var proposalActionTypes = map[types.ActionType]bool{
+	types.Transfer:              true,
+	types.CreateAccount:         true,
+	types.UpdateAccount:         true,
+	types.UpdateAccountAuthor:   true,
+	types.SetAccountAuthorDelay: true,
+	types.CancelAccountAuthor:   true,
+	types.ApproveAllowance:      true,
+	types.TransferFrom:          true,
+	types.LockHTLC:              true,
+	types.ClaimHTLC:             true,
+	types.RefundHTLC:            true,
+	types.DistributeDividend:    true,
+	types.ClaimDividend:         true,
+	types.ReclaimDividend:       true,
+	types.AtomicSwap:            true,
+	types.CancelSwapOrder:       true,
+	types.IncreaseAsset:         true,
+	types.IssueAsset:            true,
+	types.DestroyAsset:          true,
+	types.SetAssetOwner:         true,
+	types.UpdateAsset:           true,
+	types.UpdateAssetContract:   true,
+	types.FreezeAsset:           true,
+	types.FreezeAccountAsset:    true,
+	types.VestingTransfer:       true,
+	types.RejectVestingTransfer: true,
+	types.IssueNFTCollection:    true,
+	types.MintNFT:               true,
+	types.TransferNFT:           true,
+	types.BurnNFT:               true,
+	types.SetAssetMetadata:      true,
+	types.SetSubAssetPolicy:     true,
+}
+
+// ProposeAction proposes an action of the sender account, it is executed once
+// the weight of the approving authors reaches the threshold of the account.
+// Expire is the last block number the proposal can be approved at, zero
+// means MaxProposalPeriod blocks. The intrinsic gas of the proposed action
+// type is paid by the propose action.
+type ProposeAction struct {
+	Type    types.ActionType `json:"type"`
+	To      common.Name      `json:"to"`
+	AssetID uint64           `json:"assetID"`
+	Value   *big.Int         `json:"value"`
+	Payload []byte           `json:"payload"`
+	Expire  uint64           `json:"expire"`
+}
+
+// ApproveAction approves a pending proposal of the sender account.
+type ApproveAction struct {
+	ID uint64 `json:"id"`
+}
+
+// Proposal is a pending proposed action of an account, Approvals are the
+// owners of the authors who approved it and Proposers the ones who proposed it.
+type Proposal struct {
+	ID        uint64        `json:"id"`
+	Account   common.Name   `json:"account"`
+	Action    ProposeAction `json:"action"`
+	Number    uint64        `json:"number"`
+	Approvals []string      `json:"approvals"`
+	Proposers []string      `json:"proposers"`
+}
+
+func isProposalActionType(actionType types.ActionType) bool {
+	return actionType == types.ProposeAccountAction || actionType == types.ApproveAccountAction
+}
+
+func proposalKey(id uint64) string {
+	return proposalPrefix + strconv.FormatUint(id, 10)
+}
+
+func proposalAccountKey(accountID uint64) string {
+	return proposalAccountPrefix + strconv.FormatUint(accountID, 10)
+}
+
+func (am *AccountManager) getProposal(id uint64) (*Proposal, error) {
+	b, err := am.sdb.Get(acctManagerName, proposalKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var proposal Proposal
+	if err := rlp.DecodeBytes(b, &proposal); err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+func (am *AccountManager) setProposal(proposal *Proposal) error {
+	b, err := rlp.EncodeToBytes(proposal)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, proposalKey(proposal.ID), b)
+	return nil
+}
+
+func (am *AccountManager) deleteProposal(accountID uint64, id uint64) error {
+	am.sdb.Delete(acctManagerName, proposalKey(id))
+	return am.removeID(proposalAccountKey(accountID), id)
+}
+
+// signAuthors returns the owners of the authors of the sender who signed the action.
+func signAuthors(acct *Account, action *types.Action) ([]string, error) {
+	if action.GetSignParent() != 0 {
+		return nil, fmt.Errorf("proposal can not be signed by parent account")
+	}
+	var owners []string
+	seen := make(map[uint64]bool)
+	for _, sign := range action.GetSign() {
+		if len(sign.Index) == 0 || seen[sign.Index[0]] {
+			continue
+		}
+		idx := sign.Index[0]
+		if idx >= uint64(len(acct.Authors)) {
+			return nil, fmt.Errorf("acct authors modified")
+		}
+		seen[idx] = true
+		owners = append(owners, acct.Authors[idx].Owner.String())
+	}
+	return owners, nil
+}
+
+// approvedWeight returns the current weight of the approving authors and the
+// threshold the proposal must reach.
+func approvedWeight(acct *Account, proposal *Proposal) (uint64, uint64) {
+	approvals := make(map[string]bool, len(proposal.Approvals))
+	for _, owner := range proposal.Approvals {
+		approvals[owner] = true
+	}
+	var weight uint64
+	for _, author := range acct.Authors {
+		if approvals[author.Owner.String()] {
+			weight += author.GetWeight()
+		}
+	}
+	threshold := acct.GetThreshold()
+	if proposal.Action.Type == types.UpdateAccountAuthor || proposal.Action.Type == types.SetAccountAuthorDelay || proposal.Action.Type == types.CancelAccountAuthor {
+		threshold = acct.GetUpdateAuthorThreshold()
+	}
+	return weight, threshold
+}
+
+// Propose stores a proposal of the sender account approved by the authors
+// who signed the action and executes it if the threshold is reached.
+func (am *AccountManager) Propose(action *types.Action, propose *ProposeAction, ctx *types.AccountManagerContext) ([]*types.InternalAction, error) {
+	acct, err := am.GetAccountByName(action.Sender())
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	if !proposalActionTypes[propose.Type] {
+		return nil, fmt.Errorf("proposal action type %d not supported", propose.Type)
+	}
+	if propose.Value == nil {
+		propose.Value = big.NewInt(0)
+	}
+	if propose.Value.Sign() < 0 {
+		return nil, ErrNegativeValue
+	}
+	if propose.Expire == 0 {
+		propose.Expire = ctx.Number + MaxProposalPeriod
+	}
+	if propose.Expire < ctx.Number || propose.Expire > ctx.Number+MaxProposalPeriod {
+		return nil, fmt.Errorf("proposal expire must be between %d and %d", ctx.Number, ctx.Number+MaxProposalPeriod)
+	}
+	if err := proposalAction(acct.GetName(), propose).Check(ctx.CurForkID, ctx.ChainConfig); err != nil {
+		return nil, err
+	}
+	owners, err := signAuthors(acct, action)
+	if err != nil {
+		return nil, err
+	}
+
+	// drop the expired proposals of the account
+	accountKey := proposalAccountKey(acct.GetAccountID())
+	ids, err := am.getIDList(accountKey)
+	if err != nil {
+		return nil, err
+	}
+	var pending []uint64
+	proposed := make(map[string]int)
+	for _, id := range ids {
+		proposal, err := am.getProposal(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal == nil || proposal.Action.Expire < ctx.Number {
+			am.sdb.Delete(acctManagerName, proposalKey(id))
+			continue
+		}
+		pending = append(pending, id)
+		for _, owner := range proposal.Proposers {
+			proposed[owner]++
+		}
+	}
+	if len(pending) >= MaxPendingProposals {
+		return nil, fmt.Errorf("account pending proposals can not exceed %d", MaxPendingProposals)
+	}
+	// a single author can't take all the pending slots of the account
+	for _, owner := range owners {
+		if proposed[owner] >= MaxPendingProposalsPerAuthor {
+			return nil, fmt.Errorf("author %s pending proposals can not exceed %d", owner, MaxPendingProposalsPerAuthor)
+		}
+	}
+
+	id, err := am.getUint64(proposalCounterKey)
+	if err != nil {
+		return nil, err
+	}
+	id++
+	if err := am.putUint64(proposalCounterKey, id); err != nil {
+		return nil, err
+	}
+	proposal := &Proposal{ID: id, Account: acct.GetName(), Action: *propose, Number: ctx.Number, Approvals: owners, Proposers: owners}
+	if weight, threshold := approvedWeight(acct, proposal); weight >= threshold {
+		if err := am.putIDList(accountKey, pending); err != nil {
+			return nil, err
+		}
+		return am.executeProposal(proposal, ctx)
+	}
+	if err := am.setProposal(proposal); err != nil {
+		return nil, err
+	}
+	return nil, am.putIDList(accountKey, append(pending, id))
+}
+
+// Approve adds the authors who signed the action to the approvals of the
+// proposal and executes it if the threshold is reached.
+func (am *AccountManager) Approve(action *types.Action, id uint64, ctx *types.AccountManagerContext) ([]*types.InternalAction, error) {
+	acct, err := am.GetAccountByName(action.Sender())
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	proposal, err := am.getProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil || proposal.Account != acct.GetName() {
+		return nil, fmt.Errorf("account %s proposal %d not exist", acct.GetName(), id)
+	}
+	if proposal.Action.Expire < ctx.Number {
+		return nil, fmt.Errorf("account %s proposal %d expired at %d", acct.GetName(), id, proposal.Action.Expire)
+	}
+	owners, err := signAuthors(acct, action)
+	if err != nil {
+		return nil, err
+	}
+	for _, owner := range owners {
+		approved := false
+		for _, approval := range proposal.Approvals {
+			if approval == owner {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			proposal.Approvals = append(proposal.Approvals, owner)
+		}
+	}
+	if weight, threshold := approvedWeight(acct, proposal); weight >= threshold {
+		if err := am.deleteProposal(acct.GetAccountID(), id); err != nil {
+			return nil, err
+		}
+		return am.executeProposal(proposal, ctx)
+	}
+	return nil, am.setProposal(proposal)
+}
+
+func proposalAction(account common.Name, propose *ProposeAction) *types.Action {
+	return types.NewAction(propose.Type, account, propose.To, 0, propose.AssetID, 0, propose.Value, propose.Payload, nil)
+}
+
+// executeProposal executes the proposed action, the internal actions include
+// the proposed action itself.
+func (am *AccountManager) executeProposal(proposal *Proposal, ctx *types.AccountManagerContext) ([]*types.InternalAction, error) {
+	action := proposalAction(proposal.Account, &proposal.Action)
+	internalActions, err := am.process(&types.AccountManagerContext{
+		Action:      action,
+		Number:      ctx.Number,
+		CurForkID:   ctx.CurForkID,
+		ChainConfig: ctx.ChainConfig,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("proposal %d execute failed: %v", proposal.ID, err)
+	}
+	internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "proposal", GasUsed: 0, GasLimit: 0, Depth: 0, Error: ""}
+	return append([]*types.InternalAction{internalAction}, internalActions...), nil
+}
+
+// GetProposal returns a pending proposal.
+func (am *AccountManager) GetProposal(id uint64) (*Proposal, error) {
+	return am.getProposal(id)
+}
+
+// GetProposals returns the pending proposals of the account which are not expired at the block number.
+func (am *AccountManager) GetProposals(accountName common.Name, number uint64) ([]*Proposal, error) {
+	acct, err := am.GetAccountByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if acct == nil {
+		return nil, ErrAccountNotExist
+	}
+	ids, err := am.getIDList(proposalAccountKey(acct.GetAccountID()))
+	if err != nil {
+		return nil, err
+	}
+	proposals := make([]*Proposal, 0, len(ids))
+	for _, id := range ids {
+		proposal, err := am.getProposal(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal != nil && proposal.Action.Expire >= number {
+			proposals = append(proposals, proposal)
+		}
+	}
+	return proposals, nil
+}
+
+// GetProposalWeight returns the approved weight and the threshold of the proposal.
+func (am *AccountManager) GetProposalWeight(proposal *Proposal) (uint64, uint64, error) {
+	acct, err := am.GetAccountByName(proposal.Account)
+	if err != nil {
+		return 0, 0, err
+	}
+	if acct == nil {
+		return 0, 0, ErrAccountNotExist
+	}
+	weight, threshold := approvedWeight(acct, proposal)
+	return weight, threshold, nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_Proposal(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		keys    []*ecdsa.PrivateKey
+		authors []*AuthorAction
+	)
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		authors = append(authors, &AuthorAction{ActionType: AddAuthor, Author: common.NewAuthor(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey)), 1)})
+	}
+	name, to := common.Name("proposaltest"), common.Name("proposalto")
+	pubkey := common.BytesToPubKey(crypto.FromECDSAPub(&keys[0].PublicKey))
+	for _, n := range []common.Name{name, to} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), n, common.Name(""), 0, 0, pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// authors 1 and 2 are added after the creation key at index 0
+	if err := am.UpdateAccountAuthor(name, &AccountAuthorAction{Threshold: 2, AuthorActions: authors[1:]}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	assetID, err := am.ast.IssueAsset("proposalasset", 0, 0, "pa", big.NewInt(1000), 0, name, name, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(name, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := types.NewSigner(big.NewInt(1))
+	sign := func(actionType types.ActionType, payload interface{}, index uint64) (*types.Transaction, *types.Action) {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, name, common.Name(params.DefaultChainconfig.AccountName), 0, 0, 0, big.NewInt(0), data, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(keys[index], []uint64{index})}); err != nil {
+			t.Fatal(err)
+		}
+		return tx, action
+	}
+	ctx := &types.AccountManagerContext{Number: 10, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig}
+
+	propose := &ProposeAction{Type: types.Transfer, To: to, AssetID: assetID, Value: big.NewInt(300)}
+	tx, action := sign(types.ProposeAccountAction, propose, 0)
+	if err := am.RecoverTx(signer, tx); err != nil {
+		t.Fatalf("single author proposal rejected: %v", err)
+	}
+	if _, err := am.Propose(action, propose, ctx); err != nil {
+		t.Fatal(err)
+	}
+	proposals, err := am.GetProposals(name, ctx.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proposals) != 1 || len(proposals[0].Approvals) != 1 || proposals[0].Action.Expire != ctx.Number+MaxProposalPeriod {
+		t.Fatalf("proposals mismatch: %v", proposals)
+	}
+	if weight, threshold, _ := am.GetProposalWeight(proposals[0]); weight != 1 || threshold != 2 {
+		t.Fatalf("weight mismatch: have %d/%d, want 1/2", weight, threshold)
+	}
+
+	// Approving twice with the same author doesn't add weight
+	id := proposals[0].ID
+	_, action = sign(types.ApproveAccountAction, &ApproveAction{ID: id}, 0)
+	if internal, err := am.Approve(action, id, ctx); err != nil || len(internal) != 0 {
+		t.Fatalf("same author approval executed: %v %v", internal, err)
+	}
+	_, action = sign(types.ApproveAccountAction, &ApproveAction{ID: id}, 2)
+	internal, err := am.Approve(action, id, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(internal) == 0 || internal[0].ActionType != "proposal" {
+		t.Fatalf("proposal not executed: %v", internal)
+	}
+	if balance, _ := am.GetAccountBalanceByID(to, assetID, 0); balance.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 300", balance)
+	}
+	if proposals, _ := am.GetProposals(name, ctx.Number); len(proposals) != 0 {
+		t.Fatalf("executed proposal not removed: %v", proposals)
+	}
+
+	// Expired proposals can't be approved
+	propose = &ProposeAction{Type: types.Transfer, To: to, AssetID: assetID, Value: big.NewInt(1), Expire: ctx.Number + 1}
+	_, action = sign(types.ProposeAccountAction, propose, 1)
+	if _, err := am.Propose(action, propose, ctx); err != nil {
+		t.Fatal(err)
+	}
+	proposals, _ = am.GetProposals(name, ctx.Number)
+	_, action = sign(types.ApproveAccountAction, &ApproveAction{ID: proposals[0].ID}, 2)
+	expired := &types.AccountManagerContext{Number: ctx.Number + 2, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig}
+	if _, err := am.Approve(action, proposals[0].ID, expired); err == nil {
+		t.Fatal("expired proposal approved")
+	}
+}
+
+func TestAccountManager_ProposalLimits(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		keys    []*ecdsa.PrivateKey
+		authors []*AuthorAction
+	)
+	for i := 0; i < 2; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		authors = append(authors, &AuthorAction{ActionType: AddAuthor, Author: common.NewAuthor(common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey)), 1)})
+	}
+	name := common.Name("proposallimit")
+	if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, common.BytesToPubKey(crypto.FromECDSAPub(&keys[0].PublicKey)), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.UpdateAccountAuthor(name, &AccountAuthorAction{Threshold: 2, AuthorActions: authors[1:]}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := types.NewSigner(big.NewInt(1))
+	process := func(actionType types.ActionType, to common.Name, value *big.Int, payload interface{}, index uint64) error {
+		data, _ := rlp.EncodeToBytes(payload)
+		action := types.NewAction(actionType, name, to, 0, 0, 0, value, data, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(keys[index], []uint64{index})}); err != nil {
+			t.Fatal(err)
+		}
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 10, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	if _, err := am.ast.IssueAsset("plasset", 0, 0, "pla", big.NewInt(1000), 0, name, name, big.NewInt(1000), common.Name(""), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(name, 0, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+	accountName := common.Name(params.DefaultChainconfig.AccountName)
+	propose := &ProposeAction{Type: types.UpdateAccount, To: accountName, Payload: []byte{0xc0}}
+
+	// A single author can't take all the pending slots
+	for i := 0; i < MaxPendingProposalsPerAuthor; i++ {
+		if err := process(types.ProposeAccountAction, accountName, big.NewInt(0), propose, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := process(types.ProposeAccountAction, accountName, big.NewInt(0), propose, 0); err == nil {
+		t.Fatal("author pending proposals exceed the limit")
+	}
+	if err := process(types.ProposeAccountAction, accountName, big.NewInt(0), propose, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Account manager actions don't take a value
+	tests := []struct {
+		actionType types.ActionType
+		to         common.Name
+		payload    interface{}
+	}{
+		{types.ProposeAccountAction, accountName, propose},
+		{types.ApproveAccountAction, accountName, &ApproveAction{ID: 1}},
+		{types.ApproveAllowance, accountName, &ApproveAllowanceAction{Spender: name, Amount: big.NewInt(1)}},
+		{types.TransferFrom, accountName, &TransferFromAction{Owner: name, To: name, Amount: big.NewInt(1)}},
+		{types.SetAssetMetadata, common.Name(params.DefaultChainconfig.AssetName), &SetAssetMetadata{}},
+	}
+	for _, tt := range tests {
+		if err := process(tt.actionType, tt.to, big.NewInt(1), tt.payload, 1); err == nil || err.Error() != "Value should is zero" {
+			t.Errorf("action %d with value error = %v", tt.actionType, err)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var authorSpentPrefix = "authorSpent"
+
+// AuthorScope restricts the actions an author of an account can sign, an
+// empty list doesn't restrict. Assets are only checked for actions carrying
+// a value. EpochLimit caps the value of the signed actions of every asset in
+// a dpos epoch, zero is unlimited. A scoped author can only sign
+// author management, allowance and swap actions if the type is listed
+// explicitly, since a swap moves the assets of the order and not the value.
+type AuthorScope struct {
+	Owner       string             `json:"owner"`
+	ActionTypes []types.ActionType `json:"actionTypes,omitempty"`
+	AssetIDs    []uint64           `json:"assetIDs,omitempty"`
+	Recipients  []common.Name      `json:"recipients,omitempty"`
+	EpochLimit  *big.Int           `json:"epochLimit,omitempty"`
+}
+
+// signScope is the scope of an author used to sign an action.
+type signScope struct {
+	account   common.Name
+	accountID uint64
+	scope     *AuthorScope
+}
+
+// authorSpent is the value signed by a scoped author in an epoch.
+type authorSpent struct {
+	Epoch  uint64
+	Amount *big.Int
+}
+
+// IsEmpty returns whether the scope doesn't restrict anything.
+func (s *AuthorScope) IsEmpty() bool {
+	return len(s.ActionTypes) == 0 && len(s.AssetIDs) == 0 && len(s.Recipients) == 0 &&
+		(s.EpochLimit == nil || s.EpochLimit.Sign() == 0)
+}
+
+func (s *AuthorScope) hasActionType(actionType types.ActionType) bool {
+	for _, t := range s.ActionTypes {
+		if t == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+// isListedActionType returns whether a scoped author can only sign the
+// action type if it is listed in the scope.
+func isListedActionType(actionType types.ActionType) bool {
+	switch actionType {
+	case types.UpdateAccountAuthor, types.SetAccountAuthorDelay, types.CancelAccountAuthor,
+		types.ProposeAccountAction, types.ApproveAccountAction, types.ApproveAllowance,
+		types.AtomicSwap, types.CancelSwapOrder, types.RejectVestingTransfer:
+		return true
+	}
+	return false
+}
+
+// permit checks the action against the scope.
+func (s *AuthorScope) permit(action *types.Action) error {
+	if isListedActionType(action.Type()) || len(s.ActionTypes) != 0 {
+		if !s.hasActionType(action.Type()) {
+			return fmt.Errorf("%v, action type %d", ErrAuthorOutOfScope, action.Type())
+		}
+	}
+	if len(s.AssetIDs) != 0 && action.Value().Sign() > 0 {
+		found := false
+		for _, assetID := range s.AssetIDs {
+			if assetID == action.AssetID() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v, asset %d", ErrAuthorOutOfScope, action.AssetID())
+		}
+	}
+	if len(s.Recipients) != 0 {
+		found := false
+		for _, name := range s.Recipients {
+			if name == action.Recipient() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v, recipient %s", ErrAuthorOutOfScope, action.Recipient())
+		}
+	}
+	if s.EpochLimit != nil && s.EpochLimit.Sign() > 0 && action.Value().Cmp(s.EpochLimit) > 0 {
+		return fmt.Errorf("%v, value %d limit %d", ErrScopeLimitExceeded, action.Value(), s.EpochLimit)
+	}
+	return nil
+}
+
+// addScope records the scope of the author at index of the account.
+func (r *recoverActionResult) addScope(acct *Account, index uint64) {
+	if scope := acct.GetAuthorScope(acct.Authors[index].Owner.String()); scope != nil {
+		r.scopes = append(r.scopes, &signScope{account: acct.GetName(), accountID: acct.GetAccountID(), scope: scope})
+	}
+}
+
+// checkScopes checks the action against the scopes of the authors who signed it.
+func (r *recoverActionResult) checkScopes(action *types.Action) error {
+	for _, s := range r.scopes {
+		if err := s.scope.permit(action); err != nil {
+			return fmt.Errorf("account %s author %s %v", s.account, s.scope.Owner, err)
+		}
+	}
+	return nil
+}
+
+func authorSpentKey(accountID uint64, owner string, assetID uint64) string {
+	return authorSpentPrefix + strconv.FormatUint(accountID, 10) + "_" + owner + "_" + strconv.FormatUint(assetID, 10)
+}
+
+// AuthorScopeEpoch returns the dpos epoch of the time used by the author spending caps.
+func AuthorScopeEpoch(config *params.ChainConfig, t uint64) uint64 {
+	interval := config.DposCfg.EpochInterval * uint64(time.Millisecond)
+	if interval == 0 || t < config.ReferenceTime {
+		return 0
+	}
+	return (t-config.ReferenceTime)/interval + 1
+}
+
+// CheckAuthorScopes fails if the value of the action exceeds the epoch
+// spending cap of a scoped author who signed it.
+func (am *AccountManager) CheckAuthorScopes(signer types.Signer, tx *types.Transaction, action *types.Action, epoch uint64) error {
+	return am.spendAuthorScopes(signer, tx, action, epoch, false)
+}
+
+// ChargeAuthorScopes adds the value of the action to the epoch spending of
+// the scoped authors who signed it and fails if a cap is exceeded, it is
+// called once the action succeeded.
+func (am *AccountManager) ChargeAuthorScopes(signer types.Signer, tx *types.Transaction, action *types.Action, epoch uint64) error {
+	return am.spendAuthorScopes(signer, tx, action, epoch, true)
+}
+
+// spendAuthorScopes checks the epoch spending of the scoped authors who
+// signed the action, the spending is written when charge is true.
+func (am *AccountManager) spendAuthorScopes(signer types.Signer, tx *types.Transaction, action *types.Action, epoch uint64, charge bool) error {
+	if action.Value().Sign() <= 0 {
+		return nil
+	}
+	pubs, err := types.RecoverMultiKey(signer, action, tx)
+	if err != nil {
+		return err
+	}
+	signSender, err := am.getParentAccount(action.Sender(), action.GetSignParent())
+	if err != nil {
+		return err
+	}
+	recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor)}
+	for i, pub := range pubs {
+		if err := am.ValidSign(signSender, pub, action.GetSignIndex(uint64(i)), recoverRes); err != nil {
+			return err
+		}
+	}
+	for _, s := range recoverRes.scopes {
+		if s.scope.EpochLimit == nil || s.scope.EpochLimit.Sign() == 0 {
+			continue
+		}
+		key := authorSpentKey(s.accountID, s.scope.Owner, action.AssetID())
+		spent := &authorSpent{Epoch: epoch, Amount: new(big.Int)}
+		b, err := am.sdb.Get(acctManagerName, key)
+		if err != nil {
+			return err
+		}
+		if len(b) != 0 {
+			var last authorSpent
+			if err := rlp.DecodeBytes(b, &last); err != nil {
+				return err
+			}
+			if last.Epoch == epoch {
+				spent.Amount = last.Amount
+			}
+		}
+		spent.Amount = new(big.Int).Add(spent.Amount, action.Value())
+		if spent.Amount.Cmp(s.scope.EpochLimit) > 0 {
+			return fmt.Errorf("account %s author %s %v, spent %d limit %d", s.account, s.scope.Owner, ErrScopeLimitExceeded, spent.Amount, s.scope.EpochLimit)
+		}
+		if !charge {
+			continue
+		}
+		value, err := rlp.EncodeToBytes(spent)
+		if err != nil {
+			return err
+		}
+		am.sdb.Put(acctManagerName, key, value)
+	}
+	return nil
+}
+
+// GetAuthorSpent returns the value signed by a scoped author of the account in the epoch.
+func (am *AccountManager) GetAuthorSpent(accountName common.Name, owner string, assetID uint64, epoch uint64) (*big.Int, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	b, err := am.sdb.Get(acctManagerName, authorSpentKey(accountID, owner, assetID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return big.NewInt(0), nil
+	}
+	var spent authorSpent
+	if err := rlp.DecodeBytes(b, &spent); err != nil {
+		return nil, err
+	}
+	if spent.Epoch != epoch {
+		return big.NewInt(0), nil
+	}
+	return spent.Amount, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAccountManager_AuthorScope(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ownerKey, _ := crypto.GenerateKey()
+	botKey, _ := crypto.GenerateKey()
+	ownerPub := common.BytesToPubKey(crypto.FromECDSAPub(&ownerKey.PublicKey))
+	botPub := common.BytesToPubKey(crypto.FromECDSAPub(&botKey.PublicKey))
+
+	name := common.Name("scopetest")
+	if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, ownerPub, ""); err != nil {
+		t.Fatal(err)
+	}
+	scope := &AuthorScope{
+		Owner:       botPub.String(),
+		ActionTypes: []types.ActionType{types.Transfer},
+		Recipients:  []common.Name{"scopeshop"},
+		EpochLimit:  big.NewInt(100),
+	}
+	acctAuth := &AccountAuthorAction{
+		AuthorActions: []*AuthorAction{{ActionType: AddAuthor, Author: common.NewAuthor(botPub, 1)}},
+		AuthorScopes:  []*AuthorScope{scope},
+	}
+	if err := am.UpdateAccountAuthor(name, acctAuth, params.ForkID4); err != ErrScopeNotSupported {
+		t.Fatalf("scope before fork: have %v, want %v", err, ErrScopeNotSupported)
+	}
+	if err := am.UpdateAccountAuthor(name, acctAuth, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	acct, _ := am.GetAccountByName(name)
+	if s := acct.GetAuthorScope(botPub.String()); s == nil || len(s.Recipients) != 1 {
+		t.Fatalf("scope mismatch: %v", s)
+	}
+
+	signer := types.NewSigner(big.NewInt(1))
+	sign := func(actionType types.ActionType, to common.Name, value int64) (*types.Transaction, *types.Action) {
+		action := types.NewAction(actionType, name, to, 0, 0, 0, big.NewInt(value), nil, nil)
+		tx := types.NewTransaction(0, big.NewInt(0), action)
+		if err := types.SignActionWithMultiKey(action, tx, signer, 0, []*types.KeyPair{types.MakeKeyPair(botKey, []uint64{1})}); err != nil {
+			t.Fatal(err)
+		}
+		return tx, action
+	}
+
+	tx, action := sign(types.Transfer, "scopeshop", 60)
+	if err := am.RecoverTx(signer, tx); err != nil {
+		t.Fatalf("in scope transfer failed: %v", err)
+	}
+	for _, c := range []struct {
+		actionType types.ActionType
+		to         common.Name
+		value      int64
+	}{
+		{types.Transfer, "scopeother", 1},
+		{types.UpdateAccountAuthor, "scopeshop", 0},
+		{types.AtomicSwap, "scopeshop", 0},
+		{types.CancelSwapOrder, "scopeshop", 0},
+		{types.Transfer, "scopeshop", 101},
+	} {
+		tx, _ := sign(c.actionType, c.to, c.value)
+		if err := am.RecoverTx(signer, tx); err == nil {
+			t.Fatalf("out of scope action %d to %s value %d passed", c.actionType, c.to, c.value)
+		}
+	}
+
+	// Checking the cap before the action is applied spends nothing
+	if err := am.CheckAuthorScopes(signer, tx, action, 1); err != nil {
+		t.Fatal(err)
+	}
+	if spent, _ := am.GetAuthorSpent(name, botPub.String(), 0, 1); spent.Sign() != 0 {
+		t.Fatalf("spent by check: %d", spent)
+	}
+
+	// The cap is accumulated in an epoch and reset in the next one
+	if err := am.ChargeAuthorScopes(signer, tx, action, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.ChargeAuthorScopes(signer, tx, action, 1); err == nil || !strings.Contains(err.Error(), ErrScopeLimitExceeded.Error()) {
+		t.Fatalf("exceeded cap: have %v, want %v", err, ErrScopeLimitExceeded)
+	}
+	if spent, _ := am.GetAuthorSpent(name, botPub.String(), 0, 1); spent.Cmp(big.NewInt(60)) != 0 {
+		t.Fatalf("spent mismatch: have %d, want 60", spent)
+	}
+	if err := am.CheckAuthorScopes(signer, tx, action, 1); err == nil || !strings.Contains(err.Error(), ErrScopeLimitExceeded.Error()) {
+		t.Fatalf("check exceeded cap: have %v, want %v", err, ErrScopeLimitExceeded)
+	}
+	if err := am.ChargeAuthorScopes(signer, tx, action, 2); err != nil {
+		t.Fatalf("next epoch charge failed: %v", err)
+	}
+
+	// Removing the author removes its scope
+	acctAuth = &AccountAuthorAction{AuthorActions: []*AuthorAction{{ActionType: DeleteAuthor, Author: common.NewAuthor(botPub, 1)}}}
+	if err := am.UpdateAccountAuthor(name, acctAuth, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if acct, _ := am.GetAccountByName(name); len(acct.AuthorScopes) != 0 {
+		t.Fatalf("scope not removed: %v", acct.AuthorScopes)
+	}
+}
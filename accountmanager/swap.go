@@ -0,0 +1,184 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+)
+
+var swapNoncePrefix = "swapNonce"
+
+// SwapOrder exchanges MakerAmount of the maker asset for TakerAmount of the
+// taker asset until the Expire block, a nonce of the maker can be used once.
+type SwapOrder struct {
+	Maker        common.Name `json:"maker"`
+	Taker        common.Name `json:"taker"`
+	MakerAssetID uint64      `json:"makerAssetId"`
+	MakerAmount  *big.Int    `json:"makerAmount"`
+	TakerAssetID uint64      `json:"takerAssetId"`
+	TakerAmount  *big.Int    `json:"takerAmount"`
+	Expire       uint64      `json:"expire"`
+	Nonce        uint64      `json:"nonce"`
+}
+
+// Hash returns the hash of the order signed by the maker.
+func (o *SwapOrder) Hash(chainID *big.Int) common.Hash {
+	return types.RlpHash([]interface{}{o, chainID})
+}
+
+// AtomicSwapAction settles a swap order signed by the maker, the action is
+// sent by the taker.
+type AtomicSwapAction struct {
+	Order     SwapOrder        `json:"order"`
+	Signature *types.Signature `json:"signature"`
+}
+
+// CancelSwapOrderAction marks the swap nonce of the sender as used, so that
+// the orders signed with the nonce can not be settled.
+type CancelSwapOrderAction struct {
+	Nonce uint64 `json:"nonce"`
+}
+
+// SignSwapOrder signs the order with the keys of the maker.
+func SignSwapOrder(order *SwapOrder, chainID *big.Int, parentIndex uint64, keys []*types.KeyPair) (*types.Signature, error) {
+	return types.SignHashWithMultiKey(order.Hash(chainID), types.NewSigner(chainID), parentIndex, keys)
+}
+
+func swapNonceKey(accountID uint64, nonce uint64) string {
+	return swapNoncePrefix + strconv.FormatUint(accountID, 10) + "_" + strconv.FormatUint(nonce, 10)
+}
+
+// IsSwapNonceUsed returns whether the swap nonce of the maker is used.
+func (am *AccountManager) IsSwapNonceUsed(maker common.Name, nonce uint64) (bool, error) {
+	accountID, err := am.GetAccountIDByName(maker)
+	if err != nil {
+		return false, err
+	}
+	if accountID == 0 {
+		return false, ErrAccountNotExist
+	}
+	used, err := am.getUint64(swapNonceKey(accountID, nonce))
+	return used != 0, err
+}
+
+// CancelSwapOrder marks the swap nonce of the maker as used before the orders expire.
+func (am *AccountManager) CancelSwapOrder(maker common.Name, nonce uint64) error {
+	makerID, err := am.GetAccountIDByName(maker)
+	if err != nil {
+		return err
+	}
+	if makerID == 0 {
+		return ErrAccountNotExist
+	}
+	if used, err := am.getUint64(swapNonceKey(makerID, nonce)); err != nil {
+		return err
+	} else if used != 0 {
+		return fmt.Errorf("account %s swap nonce %d already used", maker, nonce)
+	}
+	return am.putUint64(swapNonceKey(makerID, nonce), 1)
+}
+
+// verifyHashSign checks the signature of the hash reaches the threshold of
+// the account, signatures of scoped authors are not supported.
+func (am *AccountManager) verifyHashSign(accountName common.Name, h common.Hash, sign *types.Signature, chainID *big.Int) error {
+	pubs, err := types.NewSigner(chainID).HashPubKeys(h, sign)
+	if err != nil {
+		return err
+	}
+	if uint64(len(pubs)) > params.MaxSignLength {
+		return fmt.Errorf("exceed max sign length, want most %d, actual is %d", params.MaxSignLength, len(pubs))
+	}
+	signSender, err := am.getParentAccount(accountName, sign.ParentIndex)
+	if err != nil {
+		return err
+	}
+	recoverRes := &recoverActionResult{acctAuthors: make(map[common.Name]*accountAuthor)}
+	for i, pub := range pubs {
+		index := sign.SignData[i].Index
+		if uint64(len(index)) > params.MaxSignDepth {
+			return fmt.Errorf("exceed max sign depth, want most %d, actual is %d", params.MaxSignDepth, len(index))
+		}
+		if err := am.ValidSign(signSender, pub, index, recoverRes); err != nil {
+			return err
+		}
+	}
+	if len(recoverRes.scopes) != 0 {
+		return ErrScopeNotSupported
+	}
+	for name, acctAuthor := range recoverRes.acctAuthors {
+		var count uint64
+		for _, weight := range acctAuthor.indexWeight {
+			count += weight
+		}
+		threshold := acctAuthor.threshold
+		if name == signSender && signSender != accountName {
+			threshold = acctAuthor.updateAuthorThreshold
+		}
+		if count < threshold {
+			return fmt.Errorf("account %s want threshold %d, but actual is %d", name, threshold, count)
+		}
+	}
+	return nil
+}
+
+// AtomicSwap exchanges the assets of the order between the maker and the taker.
+func (am *AccountManager) AtomicSwap(taker common.Name, swap *AtomicSwapAction, number uint64, chainID *big.Int) error {
+	order := &swap.Order
+	if order.Taker != taker {
+		return fmt.Errorf("swap order taker %s mismatch %s", order.Taker, taker)
+	}
+	if order.Maker == order.Taker {
+		return fmt.Errorf("account %s can not swap with itself", taker)
+	}
+	if order.MakerAmount == nil || order.MakerAmount.Sign() <= 0 || order.TakerAmount == nil || order.TakerAmount.Sign() <= 0 {
+		return ErrAmountValueInvalid
+	}
+	if number > order.Expire {
+		return fmt.Errorf("swap order expired at %d", order.Expire)
+	}
+	makerID, err := am.GetAccountIDByName(order.Maker)
+	if err != nil {
+		return err
+	}
+	if makerID == 0 {
+		return ErrAccountNotExist
+	}
+	if used, err := am.getUint64(swapNonceKey(makerID, order.Nonce)); err != nil {
+		return err
+	} else if used != 0 {
+		return fmt.Errorf("account %s swap nonce %d already used", order.Maker, order.Nonce)
+	}
+	if swap.Signature == nil {
+		return fmt.Errorf("swap order signature is nil")
+	}
+	if err := am.verifyHashSign(order.Maker, order.Hash(chainID), swap.Signature, chainID); err != nil {
+		return err
+	}
+	if err := am.putUint64(swapNonceKey(makerID, order.Nonce), 1); err != nil {
+		return err
+	}
+	if err := am.TransferAsset(order.Maker, order.Taker, order.MakerAssetID, order.MakerAmount, number); err != nil {
+		return err
+	}
+	return am.TransferAsset(order.Taker, order.Maker, order.TakerAssetID, order.TakerAmount, number)
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestAccountManager_AtomicSwap(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	makerKey, _ := crypto.GenerateKey()
+	takerKey, _ := crypto.GenerateKey()
+	maker, taker := common.Name("swapmaker"), common.Name("swaptaker")
+	for name, key := range map[common.Name]*ecdsa.PrivateKey{maker: makerKey, taker: takerKey} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey)), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var assetIDs []uint64
+	for i, owner := range []common.Name{maker, taker} {
+		assetID, err := am.ast.IssueAsset("swapasset"+string(rune('a'+i)), 0, 0, "sa", big.NewInt(1000), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := am.AddAccountBalanceByID(owner, assetID, big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+		assetIDs = append(assetIDs, assetID)
+	}
+
+	chainID := params.DefaultChainconfig.ChainID
+	order := SwapOrder{Maker: maker, Taker: taker, MakerAssetID: assetIDs[0], MakerAmount: big.NewInt(100), TakerAssetID: assetIDs[1], TakerAmount: big.NewInt(300), Expire: 10, Nonce: 1}
+	sign := func(order SwapOrder, key *types.KeyPair) *AtomicSwapAction {
+		signature, err := SignSwapOrder(&order, chainID, 0, []*types.KeyPair{key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &AtomicSwapAction{Order: order, Signature: signature}
+	}
+	process := func(swap *AtomicSwapAction, number uint64) ([]*types.InternalAction, error) {
+		data, _ := rlp.EncodeToBytes(swap)
+		action := types.NewAction(types.AtomicSwap, taker, common.Name(params.DefaultChainconfig.AccountName), 0, 0, 0, big.NewInt(0), data, nil)
+		return am.Process(&types.AccountManagerContext{Action: action, Number: number, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+	}
+
+	if _, err := process(sign(order, types.MakeKeyPair(takerKey, []uint64{0})), 1); err == nil {
+		t.Fatal("swap signed by taker settled")
+	}
+	swap := sign(order, types.MakeKeyPair(makerKey, []uint64{0}))
+	if _, err := process(swap, 11); err == nil {
+		t.Fatal("expired swap settled")
+	}
+	tampered := *swap
+	tampered.Order.MakerAmount = big.NewInt(1)
+	if _, err := process(&tampered, 1); err == nil {
+		t.Fatal("tampered swap settled")
+	}
+	internal, err := process(swap, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(internal) != 2 || internal[0].ActionType != "swap" {
+		t.Fatalf("internal actions mismatch: %v", internal)
+	}
+	for _, want := range []struct {
+		name    common.Name
+		assetID uint64
+		balance int64
+	}{{maker, assetIDs[0], 900}, {taker, assetIDs[0], 100}, {maker, assetIDs[1], 300}, {taker, assetIDs[1], 700}} {
+		if balance, _ := am.GetAccountBalanceByID(want.name, want.assetID, 0); balance.Cmp(big.NewInt(want.balance)) != 0 {
+			t.Fatalf("%s asset %d balance mismatch: have %d, want %d", want.name, want.assetID, balance, want.balance)
+		}
+	}
+	if _, err := process(swap, 2); err == nil {
+		t.Fatal("swap nonce replayed")
+	}
+	if used, _ := am.IsSwapNonceUsed(maker, 1); !used {
+		t.Fatal("swap nonce not used")
+	}
+
+	// The taker can't pay, nothing is exchanged
+	order.Nonce, order.TakerAmount = 2, big.NewInt(1000)
+	if _, err := process(sign(order, types.MakeKeyPair(makerKey, []uint64{0})), 1); err == nil {
+		t.Fatal("swap settled without taker balance")
+	}
+	if balance, _ := am.GetAccountBalanceByID(taker, assetIDs[0], 0); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("failed swap not reverted: taker balance %d", balance)
+	}
+
+	// The maker cancels a signed order before it expires
+	order.Nonce, order.TakerAmount = 3, big.NewInt(300)
+	cancelled := sign(order, types.MakeKeyPair(makerKey, []uint64{0}))
+	cancel := func(sender common.Name, nonce uint64) error {
+		data, _ := rlp.EncodeToBytes(&CancelSwapOrderAction{Nonce: nonce})
+		action := types.NewAction(types.CancelSwapOrder, sender, common.Name(params.DefaultChainconfig.AccountName), 0, 0, 0, big.NewInt(0), data, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 1, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	if err := cancel(taker, 3); err != nil {
+		t.Fatal(err)
+	}
+	if used, _ := am.IsSwapNonceUsed(maker, 3); used {
+		t.Fatal("swap nonce of maker cancelled by taker")
+	}
+	if err := cancel(maker, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := process(cancelled, 1); err == nil {
+		t.Fatal("cancelled swap settled")
+	}
+	if err := cancel(maker, 3); err == nil {
+		t.Fatal("swap nonce cancelled twice")
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var vestingPrefix = "vesting"
+
+// VestingTransfer transfers the action value to the recipient locked by a
+// vesting schedule. Nothing is unlocked before the Cliff block, the value is
+// then unlocked linearly from Start until fully unlocked at End, a cliff
+// vesting has Cliff equal to End. Zero Start means the current block. The
+// recipient can return the schedules of a sender by a RejectVestingTransfer
+// action to that sender.
+type VestingTransfer struct {
+	Start uint64 `json:"start"`
+	Cliff uint64 `json:"cliff"`
+	End   uint64 `json:"end"`
+}
+
+// VestingSchedule is a locked balance of an account-asset pair.
+type VestingSchedule struct {
+	From   common.Name `json:"from"`
+	Amount *big.Int    `json:"amount"`
+	Number uint64      `json:"number"`
+	Start  uint64      `json:"start"`
+	Cliff  uint64      `json:"cliff"`
+	End    uint64      `json:"end"`
+}
+
+// Locked returns the amount of the schedule still locked at the block number.
+func (v *VestingSchedule) Locked(number uint64) *big.Int {
+	if number >= v.End {
+		return big.NewInt(0)
+	}
+	if number < v.Cliff || number <= v.Start {
+		return new(big.Int).Set(v.Amount)
+	}
+	vested := new(big.Int).Mul(v.Amount, new(big.Int).SetUint64(number-v.Start))
+	vested.Div(vested, new(big.Int).SetUint64(v.End-v.Start))
+	return vested.Sub(v.Amount, vested)
+}
+
+func vestingKey(accountID uint64, assetID uint64) string {
+	return vestingPrefix + strconv.FormatUint(accountID, 10) + "_" + strconv.FormatUint(assetID, 10)
+}
+
+func (am *AccountManager) getVestingSchedules(accountID uint64, assetID uint64) ([]*VestingSchedule, error) {
+	b, err := am.sdb.Get(acctManagerName, vestingKey(accountID, assetID))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var schedules []*VestingSchedule
+	if err := rlp.DecodeBytes(b, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// addVestingSchedule locks the value transferred to the account by the vesting transfer.
+func (am *AccountManager) addVestingSchedule(from common.Name, accountName common.Name, assetID uint64, value *big.Int, vesting *VestingTransfer, number uint64) error {
+	if value.Sign() <= 0 {
+		return ErrAmountValueInvalid
+	}
+	if vesting.Start == 0 {
+		vesting.Start = number
+	}
+	if vesting.Cliff < vesting.Start || vesting.End < vesting.Cliff || vesting.End <= vesting.Start {
+		return fmt.Errorf("vesting must satisfy start <= cliff <= end and start < end")
+	}
+	if vesting.End <= number {
+		return fmt.Errorf("vesting end must be after block %d", number)
+	}
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return err
+	}
+	if accountID == 0 {
+		return ErrAccountNotExist
+	}
+	schedules, err := am.getVestingSchedules(accountID, assetID)
+	if err != nil {
+		return err
+	}
+	// drop the fully unlocked schedules
+	pending := schedules[:0]
+	for _, schedule := range schedules {
+		if schedule.End > number {
+			pending = append(pending, schedule)
+		}
+	}
+	if len(pending) >= MaxVestingSchedules {
+		return fmt.Errorf("account asset vesting schedules can not exceed %d", MaxVestingSchedules)
+	}
+	senderCount := 0
+	for _, schedule := range pending {
+		if schedule.From == from {
+			senderCount++
+		}
+	}
+	if senderCount >= MaxVestingSchedulesPerSender {
+		return fmt.Errorf("account asset vesting schedules of a sender can not exceed %d", MaxVestingSchedulesPerSender)
+	}
+	pending = append(pending, &VestingSchedule{
+		From:   from,
+		Amount: new(big.Int).Set(value),
+		Number: number,
+		Start:  vesting.Start,
+		Cliff:  vesting.Cliff,
+		End:    vesting.End,
+	})
+	return am.setVestingSchedules(accountID, assetID, pending)
+}
+
+// rejectVestingSchedules drops the schedules of the account asset sent by
+// the sender and returns their balance still locked at the block number to
+// the sender, so the account can free the slots taken by unwanted schedules.
+func (am *AccountManager) rejectVestingSchedules(accountName common.Name, sender common.Name, assetID uint64, number uint64) (*big.Int, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	schedules, err := am.getVestingSchedules(accountID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	var pending []*VestingSchedule
+	locked := big.NewInt(0)
+	for _, schedule := range schedules {
+		if schedule.From == sender {
+			locked.Add(locked, schedule.Locked(number))
+		} else {
+			pending = append(pending, schedule)
+		}
+	}
+	if len(pending) == len(schedules) {
+		return nil, ErrVestingNotExist
+	}
+	if err := am.setVestingSchedules(accountID, assetID, pending); err != nil {
+		return nil, err
+	}
+	return locked, am.TransferAsset(accountName, sender, assetID, locked, number)
+}
+
+func (am *AccountManager) lockedBalance(accountID uint64, assetID uint64, number uint64) (*big.Int, error) {
+	schedules, err := am.getVestingSchedules(accountID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	locked := big.NewInt(0)
+	for _, schedule := range schedules {
+		locked.Add(locked, schedule.Locked(number))
+	}
+	return locked, nil
+}
+
+func (am *AccountManager) setVestingSchedules(accountID uint64, assetID uint64, schedules []*VestingSchedule) error {
+	if len(schedules) == 0 {
+		am.sdb.Delete(acctManagerName, vestingKey(accountID, assetID))
+		return nil
+	}
+	b, err := rlp.EncodeToBytes(schedules)
+	if err != nil {
+		return err
+	}
+	am.sdb.Put(acctManagerName, vestingKey(accountID, assetID), b)
+	return nil
+}
+
+// pruneVestingSchedules drops the schedules fully unlocked at the block number.
+func (am *AccountManager) pruneVestingSchedules(accountID uint64, assetID uint64, number uint64) error {
+	schedules, err := am.getVestingSchedules(accountID, assetID)
+	if err != nil {
+		return err
+	}
+	var pending []*VestingSchedule
+	for _, schedule := range schedules {
+		if schedule.End > number {
+			pending = append(pending, schedule)
+		}
+	}
+	if len(pending) == len(schedules) {
+		return nil
+	}
+	return am.setVestingSchedules(accountID, assetID, pending)
+}
+
+// enoughUnlockedBalance checks the balance of the account less its locked
+// balance at the block number.
+func (am *AccountManager) enoughUnlockedBalance(acct *Account, assetID uint64, value *big.Int, number uint64) error {
+	if err := acct.EnoughAccountBalance(assetID, value); err != nil {
+		return err
+	}
+	locked, err := am.lockedBalance(acct.GetAccountID(), assetID, number)
+	if err != nil || locked.Sign() == 0 {
+		return err
+	}
+	balance, err := acct.GetBalanceByID(assetID)
+	if err != nil {
+		return err
+	}
+	if new(big.Int).Sub(balance, locked).Cmp(value) < 0 {
+		return ErrInsufficientBalance
+	}
+	return nil
+}
+
+// GetVestingSchedules returns the vesting schedules of the account asset.
+func (am *AccountManager) GetVestingSchedules(accountName common.Name, assetID uint64) ([]*VestingSchedule, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.getVestingSchedules(accountID, assetID)
+}
+
+// GetLockedBalance returns the locked balance of the account asset at the block number.
+func (am *AccountManager) GetLockedBalance(accountName common.Name, assetID uint64, number uint64) (*big.Int, error) {
+	accountID, err := am.GetAccountIDByName(accountName)
+	if err != nil {
+		return nil, err
+	}
+	if accountID == 0 {
+		return nil, ErrAccountNotExist
+	}
+	return am.lockedBalance(accountID, assetID, number)
+}
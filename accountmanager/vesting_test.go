@@ -0,0 +1,207 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package accountmanager
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestVestingSchedule_Locked(t *testing.T) {
+	linear := &VestingSchedule{Amount: big.NewInt(100), Start: 10, Cliff: 20, End: 110}
+	cliff := &VestingSchedule{Amount: big.NewInt(100), Start: 10, Cliff: 50, End: 50}
+	tests := []struct {
+		schedule *VestingSchedule
+		number   uint64
+		want     int64
+	}{
+		{linear, 0, 100},
+		{linear, 19, 100},
+		{linear, 20, 90},
+		{linear, 60, 50},
+		{linear, 110, 0},
+		{cliff, 49, 100},
+		{cliff, 50, 0},
+	}
+	for _, tt := range tests {
+		if locked := tt.schedule.Locked(tt.number); locked.Int64() != tt.want {
+			t.Errorf("locked at %d = %d, want %d", tt.number, locked, tt.want)
+		}
+	}
+}
+
+func TestAccountManager_VestingTransfer(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, to := common.Name("vestingfrom"), common.Name("vestingto")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("vestingtest123456"))
+	for _, name := range []common.Name{from, to} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("vestingasset", 0, 0, "va", big.NewInt(1000), 0, from, from, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(from, assetID, big.NewInt(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	payload, _ := rlp.EncodeToBytes(&VestingTransfer{Cliff: 20, End: 110})
+	action := types.NewAction(types.VestingTransfer, from, to, 0, assetID, 0, big.NewInt(100), payload, nil)
+	if _, err := am.Process(&types.AccountManagerContext{Action: action, Number: 10, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig}); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := am.GetAccountBalanceByID(to, assetID, 0); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("balance mismatch: have %d, want 100", balance)
+	}
+	schedules, err := am.GetVestingSchedules(to, assetID)
+	if err != nil || len(schedules) != 1 || schedules[0].Start != 10 || schedules[0].From != from {
+		t.Fatalf("schedules mismatch: %v %v", schedules, err)
+	}
+
+	if ok, _ := am.CanTransfer(to, assetID, big.NewInt(1), 19); ok {
+		t.Fatal("locked balance transferable before cliff")
+	}
+	if err := am.TransferAsset(to, from, assetID, big.NewInt(1), 19); err != ErrInsufficientBalance {
+		t.Fatalf("transfer before cliff error = %v, want %v", err, ErrInsufficientBalance)
+	}
+	if err := am.EnoughAccountBalance(to, assetID, big.NewInt(51), 60); err == nil {
+		t.Fatal("locked balance counted")
+	}
+	if err := am.TransferAsset(to, from, assetID, big.NewInt(50), 60); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _ := am.GetLockedBalance(to, assetID, 110); locked.Sign() != 0 {
+		t.Fatalf("locked at end: %d", locked)
+	}
+}
+
+func TestAccountManager_VestingSchedulesLimit(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, other, to := common.Name("vestingfrom"), common.Name("vestingother"), common.Name("vestingto")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("vestingtest123456"))
+	for _, name := range []common.Name{from, other, to} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("vestingasset", 0, 0, "va", big.NewInt(1000), 0, from, from, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{from, other} {
+		if err := am.AddAccountBalanceByID(name, assetID, big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vest := func(sender common.Name, end uint64) error {
+		payload, _ := rlp.EncodeToBytes(&VestingTransfer{Cliff: end, End: end})
+		action := types.NewAction(types.VestingTransfer, sender, to, 0, assetID, 0, big.NewInt(1), payload, nil)
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 10, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	for i := 0; i < MaxVestingSchedulesPerSender; i++ {
+		if err := vest(from, 20); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := vest(from, 20); err == nil {
+		t.Fatal("vesting schedules of a sender exceed the limit")
+	}
+	if err := vest(other, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// a transfer after the schedules end drops them
+	if err := am.TransferAsset(to, from, assetID, big.NewInt(1), 20); err != nil {
+		t.Fatal(err)
+	}
+	if schedules, _ := am.GetVestingSchedules(to, assetID); len(schedules) != 1 || schedules[0].From != other {
+		t.Fatalf("schedules not pruned: %v", schedules)
+	}
+}
+
+func TestAccountManager_RejectVestingTransfer(t *testing.T) {
+	am, err := NewAccountManager(getStateDB())
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, other, to := common.Name("vestingfrom"), common.Name("vestingother"), common.Name("vestingto")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("vestingtest123456"))
+	for _, name := range []common.Name{from, other, to} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetID, err := am.ast.IssueAsset("vestingasset", 0, 0, "va", big.NewInt(1000), 0, from, from, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []common.Name{from, other} {
+		if err := am.AddAccountBalanceByID(name, assetID, big.NewInt(1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	process := func(action *types.Action) error {
+		_, err := am.Process(&types.AccountManagerContext{Action: action, Number: 10, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig})
+		return err
+	}
+	payload, _ := rlp.EncodeToBytes(&VestingTransfer{Cliff: 100, End: 100})
+	for i := 0; i < MaxVestingSchedulesPerSender; i++ {
+		if err := process(types.NewAction(types.VestingTransfer, from, to, 0, assetID, 0, big.NewInt(1), payload, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := process(types.NewAction(types.VestingTransfer, other, to, 0, assetID, 0, big.NewInt(100), payload, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the recipient returns the dust schedules of a sender and frees their slots
+	reject := types.NewAction(types.RejectVestingTransfer, to, from, 0, assetID, 0, big.NewInt(0), nil, nil)
+	if err := process(reject); err != nil {
+		t.Fatal(err)
+	}
+	if schedules, _ := am.GetVestingSchedules(to, assetID); len(schedules) != 1 || schedules[0].From != other {
+		t.Fatalf("schedules not rejected: %v", schedules)
+	}
+	if balance, _ := am.GetAccountBalanceByID(from, assetID, 0); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("sender balance mismatch: have %d, want 1000", balance)
+	}
+	if balance, _ := am.GetAccountBalanceByID(to, assetID, 0); balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("recipient balance mismatch: have %d, want 100", balance)
+	}
+	if err := process(reject); err != ErrVestingNotExist {
+		t.Fatalf("reject error = %v, want %v", err, ErrVestingNotExist)
+	}
+}
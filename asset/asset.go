@@ -39,6 +39,8 @@ var (
 	assetCountPrefix  = "assetCount"
 	assetNameIDPrefix = "assetNameId"
 	assetObjectPrefix = "assetDefinitionObject"
+	assetFrozenPrefix = "assetFrozen"
+	acctFrozenPrefix  = "assetAccountFrozen"
 )
 
 type Asset struct {
@@ -223,6 +225,13 @@ func (a *Asset) addNewAssetObject(ao *AssetObject) (uint64, error) {
 	if ao == nil {
 		return 0, ErrAssetObjectEmpty
 	}
+	// collections and assets share the names
+	if _, err := a.GetNFTCollectionByName(ao.GetAssetName()); err == nil {
+		return 0, ErrAssetIsExist
+	} else if err != ErrCollectionNotExist {
+		return 0, err
+	}
+
 	//get assetCount
 	assetCount, err := a.getAssetCount()
 	if err != nil {
@@ -628,3 +637,72 @@ func (a *Asset) CheckOwner(fromName common.Name, assetID uint64) error {
 	}
 	return nil
 }
+
+func assetFrozenKey(assetID uint64) string {
+	return assetFrozenPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func acctFrozenKey(assetID uint64, accountName common.Name) string {
+	return acctFrozenPrefix + strconv.FormatUint(assetID, 10) + "_" + accountName.String()
+}
+
+func (a *Asset) setFrozen(key string, frozen bool) {
+	if frozen {
+		a.sdb.Put(assetManagerName, key, []byte{1})
+	} else {
+		a.sdb.Delete(assetManagerName, key)
+	}
+}
+
+func (a *Asset) isFrozen(key string) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, key)
+	if err != nil {
+		return false, err
+	}
+	return len(b) != 0, nil
+}
+
+// SetAssetFrozen freezes or unfreezes all the balances of the asset.
+func (a *Asset) SetAssetFrozen(assetID uint64, frozen bool) error {
+	if _, err := a.GetAssetObjectByID(assetID); err != nil {
+		return err
+	}
+	a.setFrozen(assetFrozenKey(assetID), frozen)
+	return nil
+}
+
+// SetAccountFrozen freezes or unfreezes the balance of the asset of an account.
+func (a *Asset) SetAccountFrozen(assetID uint64, accountName common.Name, frozen bool) error {
+	if _, err := a.GetAssetObjectByID(assetID); err != nil {
+		return err
+	}
+	a.setFrozen(acctFrozenKey(assetID, accountName), frozen)
+	return nil
+}
+
+// IsAssetFrozen returns whether the asset is frozen.
+func (a *Asset) IsAssetFrozen(assetID uint64) (bool, error) {
+	return a.isFrozen(assetFrozenKey(assetID))
+}
+
+// IsAccountFrozen returns whether the balance of the asset of the account is frozen.
+func (a *Asset) IsAccountFrozen(assetID uint64, accountName common.Name) (bool, error) {
+	return a.isFrozen(acctFrozenKey(assetID, accountName))
+}
+
+// CheckFrozen returns an error if the asset or its balance of any account is frozen.
+func (a *Asset) CheckFrozen(assetID uint64, names ...common.Name) error {
+	if frozen, err := a.IsAssetFrozen(assetID); err != nil {
+		return err
+	} else if frozen {
+		return ErrAssetFrozen
+	}
+	for _, name := range names {
+		if frozen, err := a.IsAccountFrozen(assetID, name); err != nil {
+			return err
+		} else if frozen {
+			return fmt.Errorf("%v, account %s", ErrAccountFrozen, name)
+		}
+	}
+	return nil
+}
@@ -36,6 +36,8 @@ type AssetObject struct {
 	UpperLimit  *big.Int    `json:"upperLimit"`
 	Contract    common.Name `json:"contract"`
 	Description string      `json:"description"`
+	Frozen      bool        `json:"frozen" rlp:"-"`
+	Metadata    []*Metadata `json:"metadata,omitempty" rlp:"-"`
 }
 
 func NewAssetObject(assetName string, number uint64, symbol string, amount *big.Int,
@@ -557,3 +557,33 @@ func TestAsset_HasAccess(t *testing.T) {
 		}
 	}
 }
+
+func TestAsset_Freeze(t *testing.T) {
+	a := NewAsset(getStateDB())
+	assetID, err := a.IssueAsset("freezetest", 0, 0, "ft", big.NewInt(100), 0, common.Name("a123456789aeee"), common.Name("a123456789aeee"), big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, to := common.Name("a123456789aeee"), common.Name("a123456789afff")
+	if err := a.SetAccountFrozen(assetID, from, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckFrozen(assetID, to); err != nil {
+		t.Errorf("unfrozen account rejected: %v", err)
+	}
+	if err := a.CheckFrozen(assetID, to, from); err == nil {
+		t.Error("frozen account accepted")
+	}
+	if err := a.SetAccountFrozen(assetID, from, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetAssetFrozen(assetID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.CheckFrozen(assetID, to); err != ErrAssetFrozen {
+		t.Errorf("frozen asset error = %v, want %v", err, ErrAssetFrozen)
+	}
+	if err := a.SetAssetFrozen(assetID+1, true); err != ErrAssetNotExist {
+		t.Errorf("freeze unknown asset error = %v, want %v", err, ErrAssetNotExist)
+	}
+}
@@ -27,3 +27,16 @@ type Config struct {
 }
 
 const MaxDescriptionLength uint64 = 255
+
+// MaxNFTURILength is the max length of the metadata uri of a token.
+const MaxNFTURILength uint64 = 1024
+
+// MaxAssetObjectsScan is the max number of assets read by a GetAssetObjects call.
+const MaxAssetObjectsScan uint64 = 1000
+
+// Asset metadata limits.
+const (
+	MaxMetadataKeys        = 32
+	MaxMetadataKeyLength   = 32
+	MaxMetadataValueLength = 1024
+)
@@ -19,20 +19,28 @@ package asset
 import "errors"
 
 var (
-	ErrAccountNameNull      = errors.New("account name is null")
-	ErrAssetIsExist         = errors.New("asset is exist")
-	ErrAssetNotExist        = errors.New("asset not exist")
-	ErrOwnerMismatch        = errors.New("asset owner mismatch")
-	ErrAssetNameEmpty       = errors.New("asset name is empty")
-	ErrAssetObjectEmpty     = errors.New("asset object is empty")
-	ErrNewAssetObject       = errors.New("create asset object input invalid")
-	ErrAssetAmountZero      = errors.New("asset amount is zero")
-	ErrUpperLimit           = errors.New("asset amount over the issuance limit")
-	ErrDestroyLimit         = errors.New("asset destroy exceeding the lower limit")
-	ErrAssetCountNotExist   = errors.New("asset total count not exist")
-	ErrAssetIDInvalid       = errors.New("asset id invalid")
-	ErrAssetManagerNotExist = errors.New("asset manager name not exist")
-	ErrDetailTooLong        = errors.New("detail info exceed maximum")
-	ErrNegativeAmount       = errors.New("negative amount")
-	ErrAmountOverMax256     = errors.New("amount over max uint256")
+	ErrAccountNameNull       = errors.New("account name is null")
+	ErrAssetIsExist          = errors.New("asset is exist")
+	ErrAssetNotExist         = errors.New("asset not exist")
+	ErrOwnerMismatch         = errors.New("asset owner mismatch")
+	ErrAssetNameEmpty        = errors.New("asset name is empty")
+	ErrAssetObjectEmpty      = errors.New("asset object is empty")
+	ErrNewAssetObject        = errors.New("create asset object input invalid")
+	ErrAssetAmountZero       = errors.New("asset amount is zero")
+	ErrUpperLimit            = errors.New("asset amount over the issuance limit")
+	ErrDestroyLimit          = errors.New("asset destroy exceeding the lower limit")
+	ErrAssetCountNotExist    = errors.New("asset total count not exist")
+	ErrAssetIDInvalid        = errors.New("asset id invalid")
+	ErrAssetManagerNotExist  = errors.New("asset manager name not exist")
+	ErrDetailTooLong         = errors.New("detail info exceed maximum")
+	ErrNegativeAmount        = errors.New("negative amount")
+	ErrAmountOverMax256      = errors.New("amount over max uint256")
+	ErrAssetFrozen           = errors.New("asset is frozen")
+	ErrAccountFrozen         = errors.New("account asset is frozen")
+	ErrCollectionNotExist    = errors.New("nft collection not exist")
+	ErrNFTokenNotExist       = errors.New("nft token not exist")
+	ErrNFTokenOwnerMismatch  = errors.New("nft token owner mismatch")
+	ErrMetadataInvalid       = errors.New("asset metadata invalid")
+	ErrSubAssetPolicyInvalid = errors.New("sub asset policy invalid")
+	ErrSubAssetClosed        = errors.New("sub asset issuance is closed")
 )
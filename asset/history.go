@@ -0,0 +1,109 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+var (
+	assetEventCountPrefix = "assetEventCount"
+	assetEventPrefix      = "assetEvent"
+)
+
+// AssetEvent is an issue, increase, destroy or update of an asset. Amount is
+// the amount issued or destroyed, the other fields are the asset after the
+// event. Event indexes start at 1.
+type AssetEvent struct {
+	AssetID  uint64           `json:"assetId"`
+	Index    uint64           `json:"index"`
+	Number   uint64           `json:"number"`
+	Type     types.ActionType `json:"type"`
+	Account  common.Name      `json:"account"`
+	Amount   *big.Int         `json:"amount"`
+	Supply   *big.Int         `json:"supply"`
+	AddIssue *big.Int         `json:"addIssue"`
+	Founder  common.Name      `json:"founder"`
+	Owner    common.Name      `json:"owner"`
+	Contract common.Name      `json:"contract"`
+}
+
+func assetEventCountKey(assetID uint64) string {
+	return assetEventCountPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func assetEventKey(assetID uint64, index uint64) string {
+	return assetEventPrefix + strconv.FormatUint(assetID, 10) + "_" + strconv.FormatUint(index, 10)
+}
+
+// AddAssetEvent records an event of the asset sent by the account at the block number.
+func (a *Asset) AddAssetEvent(assetID uint64, number uint64, actionType types.ActionType, account common.Name, amount *big.Int) error {
+	asset, err := a.GetAssetObjectByID(assetID)
+	if err != nil {
+		return err
+	}
+	count, err := a.getCount(assetEventCountKey(assetID))
+	if err != nil {
+		return err
+	}
+	event := &AssetEvent{
+		AssetID:  assetID,
+		Index:    count + 1,
+		Number:   number,
+		Type:     actionType,
+		Account:  account,
+		Amount:   new(big.Int).Set(amount),
+		Supply:   asset.Amount,
+		AddIssue: asset.AddIssue,
+		Founder:  asset.Founder,
+		Owner:    asset.Owner,
+		Contract: asset.Contract,
+	}
+	if err := a.put(assetEventKey(assetID, event.Index), event); err != nil {
+		return err
+	}
+	return a.put(assetEventCountKey(assetID), event.Index)
+}
+
+// GetAssetEvents returns at most limit events of the asset with indexes not
+// less than the cursor, oldest first, and the cursor of the following
+// events, zero if there are none.
+func (a *Asset) GetAssetEvents(assetID uint64, cursor uint64, limit uint64) ([]*AssetEvent, uint64, error) {
+	count, err := a.getCount(assetEventCountKey(assetID))
+	if err != nil {
+		return nil, 0, err
+	}
+	if cursor == 0 {
+		cursor = 1
+	}
+	var events []*AssetEvent
+	for index := cursor; index <= count; index++ {
+		if uint64(len(events)) == limit {
+			return events, index, nil
+		}
+		var event AssetEvent
+		if _, err := a.get(assetEventKey(assetID, index), &event); err != nil {
+			return nil, 0, err
+		}
+		events = append(events, &event)
+	}
+	return events, 0, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestAsset_History(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("historyowner")
+	assetID, err := a.IssueAsset("historyasset", 1, 0, "ha", big.NewInt(100), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddAssetEvent(assetID, 1, types.IssueAsset, owner, big.NewInt(100)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.IncreaseAsset(owner, assetID, big.NewInt(50), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddAssetEvent(assetID, 2, types.IncreaseAsset, owner, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DestroyAsset(owner, assetID, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddAssetEvent(assetID, 3, types.DestroyAsset, owner, big.NewInt(30)); err != nil {
+		t.Fatal(err)
+	}
+
+	events, next, err := a.GetAssetEvents(assetID, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || next != 3 || events[0].Index != 1 || events[1].Supply.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("first page mismatch: %v next %d", events, next)
+	}
+	events, next, err = a.GetAssetEvents(assetID, next, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || next != 0 || events[0].Type != types.DestroyAsset || events[0].Supply.Cmp(big.NewInt(120)) != 0 {
+		t.Fatalf("last page mismatch: %v next %d", events, next)
+	}
+
+	// supply is reconciled from the amounts of the events
+	events, _, _ = a.GetAssetEvents(assetID, 0, 10)
+	supply := big.NewInt(0)
+	for _, event := range events {
+		if event.Type == types.DestroyAsset {
+			supply.Sub(supply, event.Amount)
+		} else {
+			supply.Add(supply, event.Amount)
+		}
+	}
+	if supply.Cmp(events[len(events)-1].Supply) != 0 {
+		t.Fatalf("supply mismatch: have %d, want %d", supply, events[len(events)-1].Supply)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"sort"
+	"strconv"
+)
+
+var assetMetadataPrefix = "assetMetadata"
+
+// Metadata is a key/value entry of the metadata of an asset, such as a logo
+// or a website.
+type Metadata struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func assetMetadataKey(assetID uint64) string {
+	return assetMetadataPrefix + strconv.FormatUint(assetID, 10)
+}
+
+// GetAssetMetadata returns the metadata of the asset sorted by key.
+func (a *Asset) GetAssetMetadata(assetID uint64) ([]*Metadata, error) {
+	var metadata []*Metadata
+	_, err := a.get(assetMetadataKey(assetID), &metadata)
+	return metadata, err
+}
+
+// SetAssetMetadata sets the entries of the metadata of the asset, an entry
+// with an empty value removes the key.
+func (a *Asset) SetAssetMetadata(assetID uint64, entries []*Metadata) error {
+	if _, err := a.GetAssetObjectByID(assetID); err != nil {
+		return err
+	}
+	metadata, err := a.GetAssetMetadata(assetID)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string, len(metadata)+len(entries))
+	for _, m := range metadata {
+		values[m.Key] = m.Value
+	}
+	for _, m := range entries {
+		if len(m.Key) == 0 || len(m.Key) > MaxMetadataKeyLength || len(m.Value) > MaxMetadataValueLength {
+			return ErrMetadataInvalid
+		}
+		if len(m.Value) == 0 {
+			delete(values, m.Key)
+		} else {
+			values[m.Key] = m.Value
+		}
+	}
+	if len(values) > MaxMetadataKeys {
+		return ErrMetadataInvalid
+	}
+	if len(values) == 0 {
+		a.sdb.Delete(assetManagerName, assetMetadataKey(assetID))
+		return nil
+	}
+	metadata = make([]*Metadata, 0, len(values))
+	for key, value := range values {
+		metadata = append(metadata, &Metadata{Key: key, Value: value})
+	}
+	sort.Slice(metadata, func(i, j int) bool { return metadata[i].Key < metadata[j].Key })
+	return a.put(assetMetadataKey(assetID), metadata)
+}
+
+// GetAssetObjects returns at most limit assets accepted by the filter with
+// ids not less than the cursor and the cursor of the following assets, zero
+// if there are none. At most MaxAssetObjectsScan assets are read, so a page
+// may be short or empty while the cursor is not zero.
+func (a *Asset) GetAssetObjects(cursor uint64, limit uint64, filter func(*AssetObject) bool) ([]*AssetObject, uint64, error) {
+	return a.getAssetObjects(cursor, limit, MaxAssetObjectsScan, filter)
+}
+
+func (a *Asset) getAssetObjects(cursor uint64, limit uint64, scan uint64, filter func(*AssetObject) bool) ([]*AssetObject, uint64, error) {
+	count, err := a.getAssetCount()
+	if err != nil {
+		return nil, 0, err
+	}
+	var assets []*AssetObject
+	for id := cursor; id < count; id++ {
+		if uint64(len(assets)) == limit || id-cursor == scan {
+			return assets, id, nil
+		}
+		asset, err := a.GetAssetObjectByID(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		if filter == nil || filter(asset) {
+			assets = append(assets, asset)
+		}
+	}
+	return assets, 0, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAsset_Metadata(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("metaowner")
+	assetID, err := a.IssueAsset("metaasset", 1, 0, "ma", big.NewInt(100), 0, owner, owner, big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetAssetMetadata(assetID, []*Metadata{{Key: "website", Value: "https://fractalproject.com"}, {Key: "logo", Value: "ipfs://logo"}}); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := a.GetAssetMetadata(assetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata) != 2 || metadata[0].Key != "logo" || metadata[1].Value != "https://fractalproject.com" {
+		t.Fatalf("metadata mismatch: %v", metadata)
+	}
+	// an empty value removes the key
+	if err := a.SetAssetMetadata(assetID, []*Metadata{{Key: "logo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if metadata, _ = a.GetAssetMetadata(assetID); len(metadata) != 1 || metadata[0].Key != "website" {
+		t.Fatalf("metadata mismatch: %v", metadata)
+	}
+
+	tooLong := []*Metadata{{Key: "abi", Value: strings.Repeat("a", MaxMetadataValueLength+1)}}
+	if err := a.SetAssetMetadata(assetID, tooLong); err != ErrMetadataInvalid {
+		t.Fatalf("set too long value, have %v, want %v", err, ErrMetadataInvalid)
+	}
+	var tooMany []*Metadata
+	for i := 0; i < MaxMetadataKeys; i++ {
+		tooMany = append(tooMany, &Metadata{Key: strings.Repeat("k", i+1), Value: "v"})
+	}
+	if err := a.SetAssetMetadata(assetID, tooMany); err != ErrMetadataInvalid {
+		t.Fatalf("set too many keys, have %v, want %v", err, ErrMetadataInvalid)
+	}
+	if err := a.SetAssetMetadata(assetID+1, nil); err != ErrAssetNotExist {
+		t.Fatalf("set metadata of unknown asset, have %v, want %v", err, ErrAssetNotExist)
+	}
+}
+
+func TestAsset_GetAssetObjects(t *testing.T) {
+	a := NewAsset(getStateDB())
+	start, _ := a.getAssetCount()
+	owner, other := common.Name("listowner"), common.Name("listother")
+	for i, name := range []string{"lista", "listb", "listc"} {
+		o := owner
+		if i == 1 {
+			o = other
+		}
+		if _, err := a.IssueAsset(name, 1, 0, name, big.NewInt(1), 0, o, o, big.NewInt(1), common.Name(""), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filter := func(ao *AssetObject) bool { return ao.Owner == owner }
+	assets, next, err := a.GetAssetObjects(start, 1, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0].AssetName != "lista" || next != start+1 {
+		t.Fatalf("first page mismatch: %v next %d", assets, next)
+	}
+	if assets, next, _ = a.GetAssetObjects(next, 1, filter); len(assets) != 1 || assets[0].AssetName != "listc" || next != 0 {
+		t.Fatalf("last page mismatch: %v next %d", assets, next)
+	}
+
+	// A call reads a bounded number of assets and returns the cursor
+	rare := func(ao *AssetObject) bool { return ao.AssetName == "listc" }
+	if assets, next, _ = a.getAssetObjects(start, 10, 2, rare); len(assets) != 0 || next != start+2 {
+		t.Fatalf("scanned page mismatch: %v next %d", assets, next)
+	}
+	if assets, next, _ = a.getAssetObjects(next, 10, 2, rare); len(assets) != 1 || next != 0 {
+		t.Fatalf("scanned last page mismatch: %v next %d", assets, next)
+	}
+}
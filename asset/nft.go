@@ -0,0 +1,348 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"strconv"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+var (
+	nftCountPrefix      = "nftCount"
+	nftNameIDPrefix     = "nftNameId"
+	nftCollectionPrefix = "nftCollection"
+	nftTokenPrefix      = "nftToken"
+	nftOwnerCountPrefix = "nftOwnerCount"
+	nftOwnerPrefix      = "nftOwner"
+	nftOwnerIndexPrefix = "nftOwnerIndex"
+)
+
+// NFTCollection is a collection of non-fungible tokens, Minted is the last
+// token id and Supply the number of tokens not burned.
+type NFTCollection struct {
+	CollectionID uint64      `json:"collectionId"`
+	Number       uint64      `json:"number"`
+	Name         string      `json:"name"`
+	Symbol       string      `json:"symbol"`
+	Founder      common.Name `json:"founder"`
+	Owner        common.Name `json:"owner"`
+	Minted       uint64      `json:"minted"`
+	Supply       uint64      `json:"supply"`
+	Description  string      `json:"description"`
+}
+
+// NFToken is a token of a collection, token ids start at 1.
+type NFToken struct {
+	CollectionID uint64      `json:"collectionId"`
+	TokenID      uint64      `json:"tokenId"`
+	Number       uint64      `json:"number"`
+	Owner        common.Name `json:"owner"`
+	URI          string      `json:"uri"`
+}
+
+// nftRef refers to a token in the owner index.
+type nftRef struct {
+	CollectionID uint64
+	TokenID      uint64
+}
+
+func nftCollectionKey(collectionID uint64) string {
+	return nftCollectionPrefix + strconv.FormatUint(collectionID, 10)
+}
+
+func nftTokenKey(collectionID uint64, tokenID uint64) string {
+	return nftTokenPrefix + strconv.FormatUint(collectionID, 10) + "_" + strconv.FormatUint(tokenID, 10)
+}
+
+// The tokens of an owner are stored as a list, token i is kept under
+// nftOwnerPrefix + owner + "_" + i and its position + 1 under
+// nftOwnerIndexPrefix + collectionID + "_" + tokenID. A removed token is
+// replaced by the last token of the list.
+func nftOwnerCountKey(owner common.Name) string {
+	return nftOwnerCountPrefix + owner.String()
+}
+
+func nftOwnerKey(owner common.Name, index uint64) string {
+	return nftOwnerPrefix + owner.String() + "_" + strconv.FormatUint(index, 10)
+}
+
+func nftOwnerIndexKey(collectionID uint64, tokenID uint64) string {
+	return nftOwnerIndexPrefix + strconv.FormatUint(collectionID, 10) + "_" + strconv.FormatUint(tokenID, 10)
+}
+
+func (a *Asset) get(key string, val interface{}) (bool, error) {
+	b, err := a.sdb.Get(assetManagerName, key)
+	if err != nil {
+		return false, err
+	}
+	if len(b) == 0 {
+		return false, nil
+	}
+	return true, rlp.DecodeBytes(b, val)
+}
+
+func (a *Asset) put(key string, val interface{}) error {
+	b, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	a.sdb.Put(assetManagerName, key, b)
+	return nil
+}
+
+func (a *Asset) getCount(key string) (uint64, error) {
+	var count uint64
+	_, err := a.get(key, &count)
+	return count, err
+}
+
+// GetNFTCollection returns the collection by id.
+func (a *Asset) GetNFTCollection(collectionID uint64) (*NFTCollection, error) {
+	var collection NFTCollection
+	if ok, err := a.get(nftCollectionKey(collectionID), &collection); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrCollectionNotExist
+	}
+	return &collection, nil
+}
+
+// GetNFTCollectionByName returns the collection by name.
+func (a *Asset) GetNFTCollectionByName(name string) (*NFTCollection, error) {
+	var collectionID uint64
+	if ok, err := a.get(nftNameIDPrefix+name, &collectionID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrCollectionNotExist
+	}
+	return a.GetNFTCollection(collectionID)
+}
+
+// IssueNFTCollection issues a collection, its ids start at 1.
+func (a *Asset) IssueNFTCollection(name string, symbol string, number uint64, founder common.Name, owner common.Name, description string) (uint64, error) {
+	if !common.StrToName(name).IsValid(assetRegExp, assetNameLength) || !common.StrToName(symbol).IsValid(assetRegExp, assetNameLength) {
+		return 0, ErrNewAssetObject
+	}
+	if uint64(len(description)) > MaxDescriptionLength {
+		return 0, ErrDetailTooLong
+	}
+	if _, err := a.GetNFTCollectionByName(name); err == nil {
+		return 0, ErrAssetIsExist
+	} else if err != ErrCollectionNotExist {
+		return 0, err
+	}
+	if _, err := a.GetAssetIDByName(name); err == nil {
+		return 0, ErrAssetIsExist
+	} else if err != ErrAssetNotExist {
+		return 0, err
+	}
+	count, err := a.getCount(nftCountPrefix)
+	if err != nil {
+		return 0, err
+	}
+	collection := &NFTCollection{
+		CollectionID: count + 1,
+		Number:       number,
+		Name:         name,
+		Symbol:       symbol,
+		Founder:      founder,
+		Owner:        owner,
+		Description:  description,
+	}
+	if err := a.put(nftCollectionKey(collection.CollectionID), collection); err != nil {
+		return 0, err
+	}
+	if err := a.put(nftNameIDPrefix+name, collection.CollectionID); err != nil {
+		return 0, err
+	}
+	return collection.CollectionID, a.put(nftCountPrefix, collection.CollectionID)
+}
+
+// GetNFToken returns the token of the collection.
+func (a *Asset) GetNFToken(collectionID uint64, tokenID uint64) (*NFToken, error) {
+	var token NFToken
+	if ok, err := a.get(nftTokenKey(collectionID, tokenID), &token); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrNFTokenNotExist
+	}
+	return &token, nil
+}
+
+func (a *Asset) addOwnerToken(owner common.Name, collectionID uint64, tokenID uint64) error {
+	count, err := a.getCount(nftOwnerCountKey(owner))
+	if err != nil {
+		return err
+	}
+	if err := a.put(nftOwnerKey(owner, count), &nftRef{CollectionID: collectionID, TokenID: tokenID}); err != nil {
+		return err
+	}
+	if err := a.put(nftOwnerIndexKey(collectionID, tokenID), count+1); err != nil {
+		return err
+	}
+	return a.put(nftOwnerCountKey(owner), count+1)
+}
+
+func (a *Asset) removeOwnerToken(owner common.Name, collectionID uint64, tokenID uint64) error {
+	index, err := a.getCount(nftOwnerIndexKey(collectionID, tokenID))
+	if err != nil || index == 0 {
+		return err
+	}
+	count, err := a.getCount(nftOwnerCountKey(owner))
+	if err != nil {
+		return err
+	}
+	last := count - 1
+	if index-1 != last {
+		var ref nftRef
+		if _, err := a.get(nftOwnerKey(owner, last), &ref); err != nil {
+			return err
+		}
+		if err := a.put(nftOwnerKey(owner, index-1), &ref); err != nil {
+			return err
+		}
+		if err := a.put(nftOwnerIndexKey(ref.CollectionID, ref.TokenID), index); err != nil {
+			return err
+		}
+	}
+	a.sdb.Delete(assetManagerName, nftOwnerKey(owner, last))
+	a.sdb.Delete(assetManagerName, nftOwnerIndexKey(collectionID, tokenID))
+	if last == 0 {
+		a.sdb.Delete(assetManagerName, nftOwnerCountKey(owner))
+		return nil
+	}
+	return a.put(nftOwnerCountKey(owner), last)
+}
+
+// MintNFT mints a token of the collection to the account, only the
+// collection owner can mint.
+func (a *Asset) MintNFT(from common.Name, collectionID uint64, to common.Name, uri string, number uint64) (uint64, error) {
+	collection, err := a.GetNFTCollection(collectionID)
+	if err != nil {
+		return 0, err
+	}
+	if collection.Owner != from {
+		return 0, ErrOwnerMismatch
+	}
+	if uint64(len(uri)) > MaxNFTURILength {
+		return 0, ErrDetailTooLong
+	}
+	collection.Minted++
+	collection.Supply++
+	token := &NFToken{CollectionID: collectionID, TokenID: collection.Minted, Number: number, Owner: to, URI: uri}
+	if err := a.put(nftTokenKey(collectionID, token.TokenID), token); err != nil {
+		return 0, err
+	}
+	if err := a.addOwnerToken(to, collectionID, token.TokenID); err != nil {
+		return 0, err
+	}
+	return token.TokenID, a.put(nftCollectionKey(collectionID), collection)
+}
+
+// TransferNFT transfers a token owned by the from account.
+func (a *Asset) TransferNFT(from common.Name, collectionID uint64, tokenID uint64, to common.Name) error {
+	token, err := a.GetNFToken(collectionID, tokenID)
+	if err != nil {
+		return err
+	}
+	if token.Owner != from {
+		return ErrNFTokenOwnerMismatch
+	}
+	if from == to {
+		return nil
+	}
+	if err := a.removeOwnerToken(from, collectionID, tokenID); err != nil {
+		return err
+	}
+	token.Owner = to
+	if err := a.put(nftTokenKey(collectionID, tokenID), token); err != nil {
+		return err
+	}
+	return a.addOwnerToken(to, collectionID, tokenID)
+}
+
+// BurnNFT burns a token owned by the from account.
+func (a *Asset) BurnNFT(from common.Name, collectionID uint64, tokenID uint64) error {
+	token, err := a.GetNFToken(collectionID, tokenID)
+	if err != nil {
+		return err
+	}
+	if token.Owner != from {
+		return ErrNFTokenOwnerMismatch
+	}
+	collection, err := a.GetNFTCollection(collectionID)
+	if err != nil {
+		return err
+	}
+	if err := a.removeOwnerToken(from, collectionID, tokenID); err != nil {
+		return err
+	}
+	a.sdb.Delete(assetManagerName, nftTokenKey(collectionID, tokenID))
+	collection.Supply--
+	return a.put(nftCollectionKey(collectionID), collection)
+}
+
+// GetNFTokensByOwner returns at most limit tokens of the owner starting at
+// the cursor and the number of tokens of the owner.
+func (a *Asset) GetNFTokensByOwner(owner common.Name, cursor uint64, limit uint64) ([]*NFToken, uint64, error) {
+	count, err := a.getCount(nftOwnerCountKey(owner))
+	if err != nil {
+		return nil, 0, err
+	}
+	var tokens []*NFToken
+	for i := cursor; i < count && uint64(len(tokens)) < limit; i++ {
+		var ref nftRef
+		if _, err := a.get(nftOwnerKey(owner, i), &ref); err != nil {
+			return nil, 0, err
+		}
+		token, err := a.GetNFToken(ref.CollectionID, ref.TokenID)
+		if err != nil {
+			return nil, 0, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, count, nil
+}
+
+// GetNFTokensByCollection returns at most limit tokens of the collection
+// with ids not less than the cursor and the cursor of the following tokens,
+// zero if there are none.
+func (a *Asset) GetNFTokensByCollection(collectionID uint64, cursor uint64, limit uint64) ([]*NFToken, uint64, error) {
+	collection, err := a.GetNFTCollection(collectionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if cursor == 0 {
+		cursor = 1
+	}
+	var tokens []*NFToken
+	for id := cursor; id <= collection.Minted; id++ {
+		if uint64(len(tokens)) == limit {
+			return tokens, id, nil
+		}
+		token, err := a.GetNFToken(collectionID, id)
+		if err == ErrNFTokenNotExist {
+			continue
+		} else if err != nil {
+			return nil, 0, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, 0, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+func TestAsset_NFT(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner, alice, bob := common.Name("nftowner"), common.Name("nftalice"), common.Name("nftbob")
+	collectionID, err := a.IssueNFTCollection("nftgame", "game", 1, owner, owner, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.IssueNFTCollection("nftgame", "game", 1, owner, owner, ""); err != ErrAssetIsExist {
+		t.Fatalf("duplicate collection error = %v, want %v", err, ErrAssetIsExist)
+	}
+	if _, err := a.IssueAsset("nftgame", 1, 0, "game", big.NewInt(1), 0, owner, owner, big.NewInt(1), common.Name(""), ""); err != ErrAssetIsExist {
+		t.Fatalf("asset named after a collection error = %v, want %v", err, ErrAssetIsExist)
+	}
+	if _, err := a.IssueAsset("nftcoin", 1, 0, "coin", big.NewInt(1), 0, owner, owner, big.NewInt(1), common.Name(""), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.IssueNFTCollection("nftcoin", "coin", 1, owner, owner, ""); err != ErrAssetIsExist {
+		t.Fatalf("collection named after an asset error = %v, want %v", err, ErrAssetIsExist)
+	}
+	if _, err := a.MintNFT(alice, collectionID, alice, "", 1); err != ErrOwnerMismatch {
+		t.Fatalf("mint by non owner error = %v, want %v", err, ErrOwnerMismatch)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := a.MintNFT(owner, collectionID, alice, "ipfs://token", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := a.TransferNFT(bob, collectionID, 1, bob); err != ErrNFTokenOwnerMismatch {
+		t.Fatalf("transfer by non owner error = %v, want %v", err, ErrNFTokenOwnerMismatch)
+	}
+	if err := a.TransferNFT(alice, collectionID, 1, bob); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.BurnNFT(alice, collectionID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetNFToken(collectionID, 2); err != ErrNFTokenNotExist {
+		t.Fatalf("burned token error = %v, want %v", err, ErrNFTokenNotExist)
+	}
+
+	tokens, total, err := a.GetNFTokensByOwner(alice, 0, 10)
+	if err != nil || total != 1 || len(tokens) != 1 || tokens[0].TokenID != 3 {
+		t.Fatalf("alice tokens mismatch: %v %d %v", tokens, total, err)
+	}
+	tokens, total, _ = a.GetNFTokensByOwner(bob, 0, 10)
+	if total != 1 || tokens[0].TokenID != 1 || tokens[0].Owner != bob {
+		t.Fatalf("bob tokens mismatch: %v %d", tokens, total)
+	}
+	tokens, next, err := a.GetNFTokensByCollection(collectionID, 0, 1)
+	if err != nil || len(tokens) != 1 || tokens[0].TokenID != 1 || next != 2 {
+		t.Fatalf("collection first page mismatch: %v %d %v", tokens, next, err)
+	}
+	tokens, next, _ = a.GetNFTokensByCollection(collectionID, next, 1)
+	if len(tokens) != 1 || tokens[0].TokenID != 3 || next != 0 {
+		t.Fatalf("collection last page mismatch: %v %d", tokens, next)
+	}
+	if collection, _ := a.GetNFTCollectionByName("nftgame"); collection.Minted != 3 || collection.Supply != 2 {
+		t.Fatalf("collection supply mismatch: %v", collection)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Sub-asset policies of a parent asset.
+const (
+	// SubAssetPolicyOwner allows only the owners of the parent asset or its
+	// ancestors to issue sub-assets, it is the default policy.
+	SubAssetPolicyOwner uint64 = iota
+	// SubAssetPolicyOpen allows any account to issue sub-assets.
+	SubAssetPolicyOpen
+	// SubAssetPolicyClosed forbids issuing new sub-assets.
+	SubAssetPolicyClosed
+)
+
+var (
+	subAssetPolicyPrefix = "subAssetPolicy"
+	subAssetCountPrefix  = "subAssetCount"
+	subAssetPrefix       = "subAsset"
+	parentAssetPrefix    = "parentAsset"
+
+	subAssetIndexInitKey   = "subAssetIndexInit"
+	subAssetIndexCursorKey = "subAssetIndexCursor"
+)
+
+func subAssetPolicyKey(assetID uint64) string {
+	return subAssetPolicyPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func subAssetCountKey(assetID uint64) string {
+	return subAssetCountPrefix + strconv.FormatUint(assetID, 10)
+}
+
+func subAssetKey(assetID uint64, index uint64) string {
+	return subAssetPrefix + strconv.FormatUint(assetID, 10) + "_" + strconv.FormatUint(index, 10)
+}
+
+func parentAssetKey(assetID uint64) string {
+	return parentAssetPrefix + strconv.FormatUint(assetID, 10)
+}
+
+// GetSubAssetPolicy returns the sub-asset policy of the asset.
+func (a *Asset) GetSubAssetPolicy(assetID uint64) (uint64, error) {
+	var policy uint64
+	_, err := a.get(subAssetPolicyKey(assetID), &policy)
+	return policy, err
+}
+
+// SetSubAssetPolicy sets the sub-asset policy of the asset.
+func (a *Asset) SetSubAssetPolicy(assetID uint64, policy uint64) error {
+	if policy > SubAssetPolicyClosed {
+		return ErrSubAssetPolicyInvalid
+	}
+	if _, err := a.GetAssetObjectByID(assetID); err != nil {
+		return err
+	}
+	if policy == SubAssetPolicyOwner {
+		a.sdb.Delete(assetManagerName, subAssetPolicyKey(assetID))
+		return nil
+	}
+	return a.put(subAssetPolicyKey(assetID), policy)
+}
+
+// GetParentAssetID returns the id of the nearest existing ancestor of the
+// asset name, such as "owner:a.b" for "owner:a.b.c".
+func (a *Asset) GetParentAssetID(assetName string) (uint64, bool) {
+	var prefix string
+	if i := strings.Index(assetName, ":"); i >= 0 {
+		prefix, assetName = assetName[:i+1], assetName[i+1:]
+	}
+	for i := strings.LastIndex(assetName, "."); i > 0; i = strings.LastIndex(assetName, ".") {
+		assetName = assetName[:i]
+		if assetID, err := a.GetAssetIDByName(prefix + assetName); err == nil {
+			return assetID, true
+		}
+	}
+	return 0, false
+}
+
+// AddSubAsset indexes the asset as a sub-asset of the parent asset.
+func (a *Asset) AddSubAsset(parentID uint64, assetID uint64) error {
+	count, err := a.getCount(subAssetCountKey(parentID))
+	if err != nil {
+		return err
+	}
+	count++
+	if err := a.put(subAssetKey(parentID, count), assetID); err != nil {
+		return err
+	}
+	if err := a.put(parentAssetKey(assetID), parentID); err != nil {
+		return err
+	}
+	return a.put(subAssetCountKey(parentID), count)
+}
+
+// GetParentAsset returns the id of the indexed parent of the asset.
+func (a *Asset) GetParentAsset(assetID uint64) (uint64, bool, error) {
+	var parentID uint64
+	ok, err := a.get(parentAssetKey(assetID), &parentID)
+	return parentID, ok, err
+}
+
+// InitSubAssetIndex indexes the sub-assets of every asset at once, it is only
+// used for the genesis block.
+func (a *Asset) InitSubAssetIndex() error {
+	return a.MigrateSubAssetIndex(math.MaxUint64)
+}
+
+// MigrateSubAssetIndex indexes at most limit assets issued before the fork
+// under their parents, following the ones indexed before. It is called at
+// the end of every block after the fork until all the assets are indexed.
+func (a *Asset) MigrateSubAssetIndex(limit uint64) error {
+	if b, err := a.sdb.Get(assetManagerName, subAssetIndexInitKey); err != nil || len(b) != 0 {
+		return err
+	}
+	cursor, err := a.getCount(subAssetIndexCursorKey)
+	if err != nil {
+		return err
+	}
+	assetCount, err := a.getAssetCount()
+	if err != nil {
+		return err
+	}
+	for n := uint64(0); cursor < assetCount && n < limit; cursor, n = cursor+1, n+1 {
+		// assets issued after the fork are indexed when issued
+		if _, ok, err := a.GetParentAsset(cursor); err != nil {
+			return err
+		} else if ok {
+			continue
+		}
+		asset, err := a.GetAssetObjectByID(cursor)
+		if err != nil {
+			return err
+		}
+		if parentID, ok := a.GetParentAssetID(asset.GetAssetName()); ok {
+			if err := a.AddSubAsset(parentID, cursor); err != nil {
+				return err
+			}
+		}
+	}
+	if cursor < assetCount {
+		return a.put(subAssetIndexCursorKey, cursor)
+	}
+	a.sdb.Delete(assetManagerName, subAssetIndexCursorKey)
+	a.sdb.Put(assetManagerName, subAssetIndexInitKey, []byte{1})
+	return nil
+}
+
+// GetSubAssets returns at most limit indexed sub-assets of the asset in
+// index order starting at the cursor and the cursor of the following
+// sub-assets, zero if there are none. Sub-assets issued before the fork
+// are indexed after the fork by MigrateSubAssetIndex.
+func (a *Asset) GetSubAssets(assetID uint64, cursor uint64, limit uint64) ([]*AssetObject, uint64, error) {
+	count, err := a.getCount(subAssetCountKey(assetID))
+	if err != nil {
+		return nil, 0, err
+	}
+	if cursor == 0 {
+		cursor = 1
+	}
+	var assets []*AssetObject
+	for index := cursor; index <= count; index++ {
+		if uint64(len(assets)) == limit {
+			return assets, index, nil
+		}
+		var subAssetID uint64
+		if _, err := a.get(subAssetKey(assetID, index), &subAssetID); err != nil {
+			return nil, 0, err
+		}
+		asset, err := a.GetAssetObjectByID(subAssetID)
+		if err != nil {
+			return nil, 0, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, 0, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package asset
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+)
+
+func TestAsset_SubAssets(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("subowner")
+	issue := func(name string) uint64 {
+		assetID, err := a.IssueAsset(name, 1, params.ForkID5, "sa", big.NewInt(1), 0, owner, owner, big.NewInt(1), common.Name(""), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return assetID
+	}
+	parentID := issue("subowner:parent")
+	if _, ok := a.GetParentAssetID("subowner:parent"); ok {
+		t.Fatal("main asset has a parent")
+	}
+	// the nearest existing ancestor is the parent
+	for _, name := range []string{"subowner:parent.child1", "subowner:parent.child2.grandchild"} {
+		id, ok := a.GetParentAssetID(name)
+		if !ok || id != parentID {
+			t.Fatalf("parent of %s mismatch: have %d %v, want %d", name, id, ok, parentID)
+		}
+		if err := a.AddSubAsset(id, issue(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assets, next, err := a.GetSubAssets(parentID, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assets) != 1 || assets[0].AssetName != "subowner:parent.child1" || next != 2 {
+		t.Fatalf("first page mismatch: %v next %d", assets, next)
+	}
+	if assets, next, _ = a.GetSubAssets(parentID, next, 1); len(assets) != 1 || next != 0 {
+		t.Fatalf("last page mismatch: %v next %d", assets, next)
+	}
+	if id, ok, _ := a.GetParentAsset(assets[0].AssetID); !ok || id != parentID {
+		t.Fatalf("indexed parent mismatch: have %d %v, want %d", id, ok, parentID)
+	}
+
+	if err := a.SetSubAssetPolicy(parentID, SubAssetPolicyClosed+1); err != ErrSubAssetPolicyInvalid {
+		t.Fatalf("set invalid policy, have %v, want %v", err, ErrSubAssetPolicyInvalid)
+	}
+	if err := a.SetSubAssetPolicy(parentID, SubAssetPolicyOpen); err != nil {
+		t.Fatal(err)
+	}
+	if policy, _ := a.GetSubAssetPolicy(parentID); policy != SubAssetPolicyOpen {
+		t.Fatalf("policy mismatch: have %d, want %d", policy, SubAssetPolicyOpen)
+	}
+}
+
+func TestAsset_MigrateSubAssetIndex(t *testing.T) {
+	a := NewAsset(getStateDB())
+	owner := common.Name("subowner")
+	issue := func(name string, forkID uint64) uint64 {
+		assetID, err := a.IssueAsset(name, 1, forkID, "sa", big.NewInt(1), 0, owner, owner, big.NewInt(1), common.Name(""), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return assetID
+	}
+	// sub-assets issued before the fork are not indexed
+	parentID := issue("subowner:parent", params.ForkID4)
+	issue("subowner:parent.child1", params.ForkID4)
+	issue("subowner:other", params.ForkID4)
+	issue("subowner:parent.child2", params.ForkID4)
+	// a sub-asset issued after the fork while the index is migrated
+	if err := a.AddSubAsset(parentID, issue("subowner:parent.child3", params.ForkID5)); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if b, _ := a.sdb.Get(assetManagerName, subAssetIndexInitKey); len(b) != 0 {
+			t.Fatalf("index built after %d of 5 assets", i)
+		}
+		if err := a.MigrateSubAssetIndex(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b, _ := a.sdb.Get(assetManagerName, subAssetIndexInitKey); len(b) == 0 {
+		t.Fatal("index not built")
+	}
+	if err := a.AddSubAsset(parentID, issue("subowner:parent.child4", params.ForkID5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.MigrateSubAssetIndex(1); err != nil {
+		t.Fatal(err)
+	}
+
+	assets, next, err := a.GetSubAssets(parentID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, asset := range assets {
+		names = append(names, asset.AssetName)
+	}
+	want := []string{"subowner:parent.child3", "subowner:parent.child1", "subowner:parent.child2", "subowner:parent.child4"}
+	if next != 0 || len(names) != len(want) {
+		t.Fatalf("sub-assets mismatch: have %v next %d, want %v", names, next, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("sub-assets mismatch: have %v, want %v", names, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+)
+
+// accountTxBackfillBlocks is the number of blocks indexed per backfill batch.
+const accountTxBackfillBlocks = 1024
+
+var (
+	errAccountTxIndexDisabled    = errors.New("account tx index is disabled")
+	errAccountTxIndexUnsupported = errors.New("account tx index is unsupported by the database")
+	errAccountTxBackfillRunning  = errors.New("account tx index backfill is running")
+)
+
+// SetAccountTxIndex enables/disables the account tx index. Blocks written
+// after the current head are indexed, older blocks are indexed by
+// BackfillAccountTxIndex. The accounts of internal actions are indexed only
+// when the contract log is on, as internal actions are not stored otherwise.
+func (bc *BlockChain) SetAccountTxIndex(enable bool) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.accountTxIndex = enable
+	tail := rawdb.ReadAccountTxIndexTail(bc.db)
+	if !enable {
+		// Blocks written while disabled are not indexed, force a full
+		// backfill once the index is enabled again.
+		if tail != nil {
+			rawdb.DeleteAccountTxIndexTail(bc.db)
+		}
+		return
+	}
+	if tail == nil {
+		rawdb.WriteAccountTxIndexTail(bc.db, bc.CurrentBlock().NumberU64()+1)
+	}
+}
+
+// AccountTxIndexTail returns the oldest block number covered by the account tx index.
+func (bc *BlockChain) AccountTxIndexTail() (uint64, error) {
+	if !bc.accountTxIndex {
+		return 0, errAccountTxIndexDisabled
+	}
+	tail := rawdb.ReadAccountTxIndexTail(bc.db)
+	if tail == nil {
+		return 0, errAccountTxIndexDisabled
+	}
+	return *tail, nil
+}
+
+// AccountTxEntries returns at most limit canonical entries of the account,
+// newest first, starting below the given cursor.
+func (bc *BlockChain) AccountTxEntries(name common.Name, cursor []byte, limit int) ([]*rawdb.AccountTxEntry, []byte, error) {
+	if !bc.accountTxIndex {
+		return nil, nil, errAccountTxIndexDisabled
+	}
+	db, ok := bc.db.(rawdb.DatabaseIteratee)
+	if !ok {
+		return nil, nil, errAccountTxIndexUnsupported
+	}
+	entries, next := rawdb.ReadAccountTxEntries(db, name, cursor, limit)
+	canonical := entries[:0]
+	for _, entry := range entries {
+		if rawdb.ReadCanonicalHash(bc.db, entry.BlockNumber) == entry.BlockHash {
+			canonical = append(canonical, entry)
+		}
+	}
+	return canonical, next, nil
+}
+
+// BackfillAccountTxIndex starts indexing the canonical blocks below the
+// account tx index tail in the background.
+func (bc *BlockChain) BackfillAccountTxIndex() error {
+	if !bc.accountTxIndex {
+		return errAccountTxIndexDisabled
+	}
+	if !atomic.CompareAndSwapInt32(&bc.accountTxBackfill, 0, 1) {
+		return errAccountTxBackfillRunning
+	}
+	bc.wg.Add(1)
+	go bc.backfillAccountTxIndex()
+	return nil
+}
+
+func (bc *BlockChain) backfillAccountTxIndex() {
+	defer bc.wg.Done()
+	defer atomic.StoreInt32(&bc.accountTxBackfill, 0)
+
+	log.Info("Account tx index backfill started")
+	for {
+		select {
+		case <-bc.quit:
+			log.Info("Account tx index backfill interrupted")
+			return
+		default:
+		}
+		tail, err := bc.backfillAccountTxBlocks(accountTxBackfillBlocks)
+		if err != nil {
+			log.Error("Account tx index backfill failed", "tail", tail, "err", err)
+			return
+		}
+		if tail == 0 {
+			log.Info("Account tx index backfill finished")
+			return
+		}
+		log.Debug("Account tx index backfill", "tail", tail)
+	}
+}
+
+// backfillAccountTxBlocks indexes at most count blocks below the tail and
+// returns the new tail. The chain is locked so reorgs can't interleave.
+func (bc *BlockChain) backfillAccountTxBlocks(count int) (uint64, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	tailp := rawdb.ReadAccountTxIndexTail(bc.db)
+	if tailp == nil {
+		return 0, errAccountTxIndexDisabled
+	}
+	tail := *tailp
+	batch := bc.db.NewBatch()
+	for ; tail > 0 && count > 0; count-- {
+		number := tail - 1
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		block := bc.GetBlock(hash, number)
+		if block == nil {
+			return tail, errors.New("canonical block not found")
+		}
+		rawdb.WriteAccountTxEntries(batch, block, rawdb.ReadDetailTxs(bc.db, hash, number))
+		tail = number
+	}
+	rawdb.WriteAccountTxIndexTail(batch, tail)
+	if err := batch.Write(); err != nil {
+		return *tailp, err
+	}
+	return tail, nil
+}
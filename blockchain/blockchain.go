@@ -88,6 +88,9 @@ type BlockChain struct {
 	validator     processor.Validator // block and state validator interface
 	station       *station            // p2p station
 
+	accountTxIndex    bool  // index the transactions of every account
+	accountTxBackfill int32 // accountTxBackfill must be called atomically, account tx index backfill running
+
 	headerCache  *lru.Cache    // Cache for the most recent block headers
 	tdCache      *lru.Cache    // Cache for the most recent block total difficulties
 	numberCache  *lru.Cache    // Cache for the most recent block numbers
@@ -662,8 +665,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	}
 
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	var detailTxs []*types.DetailTx
 	if bc.vmConfig.ContractLogFlag {
-		detailTxs := make([]*types.DetailTx, len(receipts))
+		detailTxs = make([]*types.DetailTx, len(receipts))
 		for i := 0; i < len(receipts); i++ {
 			detailTxs[i] = receipts[i].GetInternalTxsLog()
 		}
@@ -688,6 +692,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 
 		// Write the positional metadata for transaction/receipt lookups and preimages
 		rawdb.WriteTxLookupEntries(batch, block)
+		if bc.accountTxIndex {
+			rawdb.WriteAccountTxEntries(batch, block, detailTxs)
+		}
 		rawdb.WritePreimages(batch, block.NumberU64(), state.Preimages())
 		isCanon = true
 	}
@@ -962,10 +969,21 @@ func (bc *BlockChain) reorgChain(oldBlock, newBlock *types.Block, batch fdb.Batc
 		}
 	}
 
+	// Account tx entries are keyed by position, drop the old ones before
+	// writing the new ones at the same heights.
+	if bc.accountTxIndex {
+		for _, block := range oldChain {
+			rawdb.DeleteAccountTxEntries(batch, block, rawdb.ReadDetailTxs(bc.db, block.Hash(), block.NumberU64()))
+		}
+	}
+
 	var addedTxs []*types.Transaction
 	for i := len(newChain) - 1; i >= 0; i-- {
 		bc.insert(batch, newChain[i])
 		rawdb.WriteTxLookupEntries(batch, newChain[i])
+		if bc.accountTxIndex {
+			rawdb.WriteAccountTxEntries(batch, newChain[i], rawdb.ReadDetailTxs(bc.db, newChain[i].Hash(), newChain[i].NumberU64()))
+		}
 		addedTxs = append(addedTxs, newChain[i].Txs...)
 	}
 
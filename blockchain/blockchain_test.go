@@ -20,7 +20,9 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/rawdb"
 	"github.com/fractalplatform/fractal/txpool"
 )
 
@@ -108,3 +110,58 @@ func TestBadBlockHashes(t *testing.T) {
 	t.Log(newChain.CurrentBlock().Hash().String())
 	t.Log(newChain.Genesis().Hash().String())
 }
+
+func TestAccountTxIndex(t *testing.T) {
+	genesis := DefaultGenesis()
+	chain := newCanonical(t, genesis)
+	defer chain.Stop()
+
+	chain.SetAccountTxIndex(true)
+	chain, _ = makeNewChain(t, genesis, chain, 10, canonicalSeed)
+
+	// reorg to a fork, the entries of the dropped blocks must be replaced
+	forkChain := newCanonical(t, genesis)
+	defer forkChain.Stop()
+
+	_, forkBlocks := makeNewChain(t, genesis, forkChain, 11, forkSeed)
+	if _, err := chain.InsertChain(forkBlocks); err != nil {
+		t.Fatal(err)
+	}
+
+	if tail, err := chain.AccountTxIndexTail(); err != nil || tail != 1 {
+		t.Fatalf("tail mismatch: have %d, want 1, err %v", tail, err)
+	}
+	if tail, err := chain.backfillAccountTxBlocks(accountTxBackfillBlocks); err != nil || tail != 0 {
+		t.Fatalf("backfill tail mismatch: have %d, want 0, err %v", tail, err)
+	}
+
+	name := common.StrToName(genesis.Config.AccountName)
+	var (
+		entries []*rawdb.AccountTxEntry
+		cursor  []byte
+	)
+	for {
+		page, next := rawdb.ReadAccountTxEntries(chain.db.(rawdb.DatabaseIteratee), name, cursor, 3)
+		entries = append(entries, page...)
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	var blocks int
+	for _, entry := range entries {
+		if rawdb.ReadCanonicalHash(chain.db, entry.BlockNumber) != entry.BlockHash {
+			t.Fatalf("stale entry of block %d %x", entry.BlockNumber, entry.BlockHash)
+		}
+		if entry.BlockNumber > 0 {
+			if entry.Roles != rawdb.AccountTxRecipient || entry.BlockHash != forkBlocks[entry.BlockNumber-1].Hash() {
+				t.Fatalf("entry mismatch: %+v", entry)
+			}
+			blocks++
+		}
+	}
+	if blocks != len(forkBlocks) {
+		t.Fatalf("indexed blocks mismatch: have %d, want %d", blocks, len(forkBlocks))
+	}
+}
@@ -426,6 +426,15 @@ func (g *Genesis) ToBlock(db fdb.Database) (*types.Block, []*types.Receipt, erro
 		return nil, nil, fmt.Errorf("genesis init fork controller failed %v", err)
 	}
 
+	if g.ForkID >= params.ForkID5 {
+		if err := accountManager.InitAssetHolderIndex(); err != nil {
+			return nil, nil, fmt.Errorf("genesis init asset holder index failed %v", err)
+		}
+		if err := accountManager.InitSubAssetIndex(); err != nil {
+			return nil, nil, fmt.Errorf("genesis init sub asset index failed %v", err)
+		}
+	}
+
 	// snapshot
 	currentTime := timestamp
 	currentTimeFormat := (currentTime / g.Config.SnapshotInterval) * g.Config.SnapshotInterval
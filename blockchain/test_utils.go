@@ -117,7 +117,7 @@ func makeNewChain(t *testing.T, genesis *Genesis, chain *BlockChain, n, seed int
 			name := common.StrToName(genesis.Config.SysName)
 			b.SetCoinbase(name)
 
-			engine.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+			engine.SetSignFn(func(content []byte, header *types.Header, state *state.StateDB) ([]byte, error) {
 				return crypto.Sign(content, systemPrivateKey)
 			})
 		})
@@ -0,0 +1,191 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/keystore"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+var (
+	passwordFile string
+	lightKDF     bool
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage the encrypted keys of the keystore",
+	Long:  `Manage the encrypted keys of the keystore`,
+	Args:  cobra.NoArgs,
+}
+
+var accountNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate a new key and store it encrypted in the keystore",
+	Long:  `Generate a new key and store it encrypted in the keystore`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		passphrase := getPassphrase(true)
+		info, err := makeKeyStore().NewKey(passphrase)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(info)
+	},
+}
+
+var accountImportCmd = &cobra.Command{
+	Use:   "import <privateKey file>",
+	Short: "Import a hex encoded private key into the keystore",
+	Long:  `Import a hex encoded private key into the keystore`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		priv, err := crypto.LoadECDSA(args[0])
+		if err != nil {
+			jww.ERROR.Println("load private key failed.", "path", args[0], "err", err)
+			os.Exit(1)
+		}
+		passphrase := getPassphrase(true)
+		info, err := makeKeyStore().ImportECDSA(priv, passphrase)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(info)
+	},
+}
+
+var accountListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the keys of the keystore",
+	Long:  `List the keys of the keystore`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		infos, err := makeKeyStore().Keys()
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSONList(infos)
+	},
+}
+
+var accountExportCmd = &cobra.Command{
+	Use:   "export <publicKey>",
+	Short: "Export the hex encoded private key of the public key",
+	Long:  `Export the hex encoded private key of the public key`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pubKey := parsePubKey(args[0])
+		key, err := makeKeyStore().GetKey(pubKey, getPassphrase(false))
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)))
+	},
+}
+
+var accountSignCmd = &cobra.Command{
+	Use:   "sign <publicKey> <hash>",
+	Short: "Sign the hex encoded 32 bytes hash with the key of the public key",
+	Long:  `Sign the hex encoded 32 bytes hash with the key of the public key`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		pubKey := parsePubKey(args[0])
+		hash, err := hexutil.Decode(args[1])
+		if err != nil {
+			jww.ERROR.Println("invalid hash", args[1], err)
+			os.Exit(1)
+		}
+		sig, err := makeKeyStore().SignHashWithPassphrase(pubKey, getPassphrase(false), hash)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(hexutil.Bytes(sig))
+	},
+}
+
+func makeKeyStore() *keystore.KeyStore {
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if lightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	return keystore.NewKeyStore(ftCfgInstance.NodeCfg.KeyStoreDir(), scryptN, scryptP)
+}
+
+func parsePubKey(arg string) common.PubKey {
+	if !common.IsHexPubKey(arg) {
+		jww.ERROR.Printf("%v is not a valid public key", arg)
+		os.Exit(1)
+	}
+	return common.HexToPubKey(arg)
+}
+
+// getPassphrase reads the passphrase from the password file, or prompts
+// for it on the standard input.
+func getPassphrase(confirm bool) string {
+	if passwordFile != "" {
+		text, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			jww.ERROR.Println("read password file failed.", "path", passwordFile, "err", err)
+			os.Exit(1)
+		}
+		return strings.TrimRight(strings.Split(string(text), "\n")[0], "\r")
+	}
+	reader := bufio.NewReader(os.Stdin)
+	jww.FEEDBACK.Print("Passphrase: ")
+	passphrase := readLine(reader)
+	if confirm {
+		jww.FEEDBACK.Print("Repeat passphrase: ")
+		if readLine(reader) != passphrase {
+			jww.ERROR.Println("passphrases do not match")
+			os.Exit(1)
+		}
+	}
+	return passphrase
+}
+
+func readLine(reader *bufio.Reader) string {
+	line, err := reader.ReadString('\n')
+	if err != nil && len(line) == 0 {
+		jww.ERROR.Println(err)
+		os.Exit(1)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func init() {
+	RootCmd.AddCommand(accountCmd)
+	accountCmd.AddCommand(accountNewCmd, accountImportCmd, accountListCmd, accountExportCmd, accountSignCmd)
+	accountCmd.PersistentFlags().StringVarP(&ftCfgInstance.NodeCfg.DataDir, "datadir", "d", ftCfgInstance.NodeCfg.DataDir, "Data directory for the databases ")
+	accountCmd.PersistentFlags().StringVar(&ftCfgInstance.NodeCfg.KeyStore, "keystore", ftCfgInstance.NodeCfg.KeyStore, "Directory for the keystore (default = inside the datadir)")
+	accountCmd.PersistentFlags().StringVarP(&passwordFile, "password", "p", "", "Password file to use for non-interactive passphrase input")
+	accountCmd.PersistentFlags().BoolVar(&lightKDF, "lightkdf", false, "Reduce key-derivation RAM & CPU usage at some expense of KDF strength")
+}
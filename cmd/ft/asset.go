@@ -0,0 +1,54 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/fractalplatform/fractal/params"
+	"github.com/spf13/cobra"
+)
+
+var assetHistoryLimit uint64
+
+var assetCommand = &cobra.Command{
+	Use:   "asset",
+	Short: "Query asset state. ",
+	Long:  "Query asset state. ",
+	Args:  cobra.NoArgs,
+}
+
+var assetHistoryCmd = &cobra.Command{
+	Use:   "history <assetID uint64> <cursor uint64>",
+	Short: "Returns the issue, increase, destroy and update events of the asset. ",
+	Long:  "Returns the issue, increase, destroy and update events of the asset, oldest first, starting at the cursor. ",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var cursor uint64
+		if len(args) > 1 {
+			cursor = parseUint64(args[1])
+		}
+		var result interface{}
+		clientCall(ipcEndpoint, &result, "account_getAssetHistory", parseUint64(args[0]), cursor, assetHistoryLimit)
+		printJSON(result)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(assetCommand)
+	assetCommand.AddCommand(assetHistoryCmd)
+	assetCommand.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+	assetHistoryCmd.Flags().Uint64VarP(&assetHistoryLimit, "limit", "l", 0, "Max number of events, 0 uses the node default")
+}
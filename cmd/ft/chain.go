@@ -55,12 +55,25 @@ var (
 			printJSON(result)
 		},
 	}
+
+	backfillTxIndexCommand = &cobra.Command{
+		Use:   "backfilltxindex",
+		Short: "Index the transactions of every account in blocks written before the index was enabled. ",
+		Long:  "Index the transactions of every account in blocks written before the index was enabled, the node must run with --accounttxindex. ",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var tail uint64
+			clientCall(ipcEndpoint, &tail, "bc_backfillAccountTxIndex")
+			printJSON(map[string]uint64{"indexTail": tail})
+		},
+	}
 )
 
 func init() {
 	RootCmd.AddCommand(chainCommand)
-	chainCommand.AddCommand(statePureCommand, forkStatusCommand)
+	chainCommand.AddCommand(statePureCommand, forkStatusCommand, backfillTxIndexCommand)
 	statePureCommand.Flags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+	backfillTxIndexCommand.Flags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
 }
 
 func pruneState(arg string) error {
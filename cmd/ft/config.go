@@ -91,6 +91,7 @@ func defaultFtServiceConfig() *ftservice.Config {
 		},
 		MetricsConf:     defaultMetricsConfig(),
 		ContractLogFlag: false,
+		AccountTxIndex:  false,
 		StatePruning:    true,
 	}
 }
@@ -101,6 +102,9 @@ func defaultMinerConfig() *ftservice.MinerConfig {
 		PrivateKeys: []string{"289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032"},
 		ExtraData:   "system",
 		Delay:       0,
+		LeaseSlots:  6,
+		Protection:  "protection.json",
+		AuditLog:    "audit.log",
 	}
 }
 
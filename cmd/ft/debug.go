@@ -21,7 +21,9 @@ import (
 	"runtime"
 	"runtime/debug"
 
+	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/rpc"
 	"github.com/spf13/cobra"
 )
 
@@ -134,9 +136,33 @@ var freeOSMemoryCmd = &cobra.Command{
 	},
 }
 
+var traceTxCmd = &cobra.Command{
+	Use:   "tracetx <hash>",
+	Short: "Re-executes the transaction and returns its call trace.",
+	Long:  `Re-executes the transaction and returns its call trace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		result := new(interface{})
+		clientCall(ipcEndpoint, &result, "debug_traceTransaction", common.HexToHash(args[0]))
+		printJSON(result)
+	},
+}
+
+var traceBlockCmd = &cobra.Command{
+	Use:   "traceblock <number>",
+	Short: "Re-executes the transactions of the block and returns their call traces.",
+	Long:  `Re-executes the transactions of the block and returns their call traces.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		result := new(interface{})
+		clientCall(ipcEndpoint, &result, "debug_traceBlockByNumber", rpc.BlockNumber(parseUint64(args[0])))
+		printJSON(result)
+	},
+}
+
 func init() {
 	RootCmd.AddCommand(debugCmd)
 	debugCmd.AddCommand(memStatsCmd, gcStatsCmd, cpuProfileCmd, goTraceCmd, blockProfileCmd,
-		mutexProfileCmd, writeMemProfileCmd, stacksCmd, freeOSMemoryCmd)
+		mutexProfileCmd, writeMemProfileCmd, stacksCmd, freeOSMemoryCmd, traceTxCmd, traceBlockCmd)
 	debugCmd.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
 }
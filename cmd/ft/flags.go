@@ -160,6 +160,14 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("node.ipcpath", flags.Lookup("ipcpath"))
 
+	flags.StringVar(
+		&ftCfgInstance.NodeCfg.KeyStore,
+		"keystore",
+		ftCfgInstance.NodeCfg.KeyStore,
+		"Directory for the keystore (default = inside the datadir)",
+	)
+	viper.BindPFlag("node.keystore", flags.Lookup("keystore"))
+
 	flags.StringVar(
 		&ftCfgInstance.NodeCfg.HTTPHost,
 		"http_host",
@@ -257,6 +265,14 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("ftservice.contractlog", flags.Lookup("contractlog"))
 
+	flags.BoolVar(
+		&ftCfgInstance.FtServiceCfg.AccountTxIndex,
+		"accounttxindex",
+		ftCfgInstance.FtServiceCfg.AccountTxIndex,
+		"flag for db to index the transactions of every account, internal actions are indexed only with contractlog.",
+	)
+	viper.BindPFlag("ftservice.accounttxindex", flags.Lookup("accounttxindex"))
+
 	// state pruning
 	flags.BoolVar(
 		&ftCfgInstance.FtServiceCfg.StatePruning,
@@ -423,6 +439,86 @@ func addFlags(flags *flag.FlagSet) {
 	)
 	viper.BindPFlag("ftservice.miner.private", flags.Lookup("miner_private"))
 
+	flags.StringSliceVar(
+		&ftCfgInstance.FtServiceCfg.Miner.KeyStoreKeys,
+		"miner_keystorekeys",
+		ftCfgInstance.FtServiceCfg.Miner.KeyStoreKeys,
+		"Public keys of the keystore for block mining rewards, used instead of miner_private",
+	)
+	viper.BindPFlag("ftservice.miner.keystorekeys", flags.Lookup("miner_keystorekeys"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.PasswordFile,
+		"miner_passwordfile",
+		ftCfgInstance.FtServiceCfg.Miner.PasswordFile,
+		"Password file to unlock the keystore keys of the miner",
+	)
+	viper.BindPFlag("ftservice.miner.passwordfile", flags.Lookup("miner_passwordfile"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.Signer,
+		"miner_signer",
+		ftCfgInstance.FtServiceCfg.Miner.Signer,
+		"IPC path or HTTP url of the external signer for block mining, used instead of miner_private and miner_keystorekeys",
+	)
+	viper.BindPFlag("ftservice.miner.signer", flags.Lookup("miner_signer"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.Protection,
+		"miner_protection",
+		ftCfgInstance.FtServiceCfg.Miner.Protection,
+		"Slashing protection file of the headers signed by miner_private or miner_keystorekeys (relative to the datadir)",
+	)
+	viper.BindPFlag("ftservice.miner.protection", flags.Lookup("miner_protection"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.AuditLog,
+		"miner_auditlog",
+		ftCfgInstance.FtServiceCfg.Miner.AuditLog,
+		"Audit log file of the sign requests of miner_private or miner_keystorekeys (relative to the datadir)",
+	)
+	viper.BindPFlag("ftservice.miner.auditlog", flags.Lookup("miner_auditlog"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.LeaseFile,
+		"miner_leasefile",
+		ftCfgInstance.FtServiceCfg.Miner.LeaseFile,
+		"Lease file shared with the standby nodes of the same miner name, only the lease holder mints blocks",
+	)
+	viper.BindPFlag("ftservice.miner.leasefile", flags.Lookup("miner_leasefile"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.LeaseServer,
+		"miner_leaseserver",
+		ftCfgInstance.FtServiceCfg.Miner.LeaseServer,
+		"IPC path or HTTP url of the node serving the miner lease, used instead of miner_leasefile",
+	)
+	viper.BindPFlag("ftservice.miner.leaseserver", flags.Lookup("miner_leaseserver"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.LeaseSecret,
+		"miner_leasesecret",
+		ftCfgInstance.FtServiceCfg.Miner.LeaseSecret,
+		"Secret shared with the miner lease server, the node serves the lease rpc module only if it is set",
+	)
+	viper.BindPFlag("ftservice.miner.leasesecret", flags.Lookup("miner_leasesecret"))
+
+	flags.StringVar(
+		&ftCfgInstance.FtServiceCfg.Miner.LeaseHolder,
+		"miner_leaseholder",
+		ftCfgInstance.FtServiceCfg.Miner.LeaseHolder,
+		"Holder name of this node in the miner lease (default = hostname-pid)",
+	)
+	viper.BindPFlag("ftservice.miner.leaseholder", flags.Lookup("miner_leaseholder"))
+
+	flags.Uint64Var(
+		&ftCfgInstance.FtServiceCfg.Miner.LeaseSlots,
+		"miner_leaseslots",
+		ftCfgInstance.FtServiceCfg.Miner.LeaseSlots,
+		"Slots without producing a block after which the standby node takes over the miner lease",
+	)
+	viper.BindPFlag("ftservice.miner.leaseslots", flags.Lookup("miner_leaseslots"))
+
 	flags.StringVar(
 		&ftCfgInstance.FtServiceCfg.Miner.ExtraData,
 		"miner_extra",
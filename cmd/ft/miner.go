@@ -122,6 +122,32 @@ var setCoinbaseCmd = &cobra.Command{
 	},
 }
 
+var setCoinbaseKeyStoreCmd = &cobra.Command{
+	Use:   "setcoinbasekeystore <name> <publicKey>...",
+	Short: "Set the coinbase of the miner with keys unlocked from the node keystore.",
+	Long:  `Set the coinbase of the miner with keys unlocked from the node keystore.`,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := common.Name(args[0])
+		for _, pubKey := range args[1:] {
+			parsePubKey(pubKey)
+		}
+		clientCall(ipcEndpoint, nil, "miner_setCoinbaseFromKeyStore", name, args[1:], getPassphrase(false))
+		printJSON(true)
+	},
+}
+
+var setCoinbaseSignerCmd = &cobra.Command{
+	Use:   "setcoinbasesigner <name> <signer endpoint>",
+	Short: "Set the coinbase of the miner with keys of an external signer.",
+	Long:  `Set the coinbase of the miner with keys of an external signer, the endpoint is an IPC path or HTTP url.`,
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		clientCall(ipcEndpoint, nil, "miner_setCoinbaseFromSigner", common.Name(args[0]), args[1])
+		printJSON(true)
+	},
+}
+
 var setExtraCmd = &cobra.Command{
 	Use:   "setextra <extra>",
 	Short: "Set the extra of the miner.",
@@ -146,6 +172,7 @@ var setDelayCmd = &cobra.Command{
 
 func init() {
 	RootCmd.AddCommand(minerCmd)
-	minerCmd.AddCommand(startCmd, forceCmd, stopCmd, miningCmd, setCoinbaseCmd, setExtraCmd, setDelayCmd)
+	minerCmd.AddCommand(startCmd, forceCmd, stopCmd, miningCmd, setCoinbaseCmd, setCoinbaseKeyStoreCmd, setCoinbaseSignerCmd, setExtraCmd, setDelayCmd)
+	setCoinbaseKeyStoreCmd.Flags().StringVarP(&passwordFile, "password", "p", "", "Password file to use for non-interactive passphrase input")
 	minerCmd.PersistentFlags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
 }
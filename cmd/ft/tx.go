@@ -0,0 +1,509 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus/dpos"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/rpcapi"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+)
+
+var (
+	txChainID     uint64
+	txActionIndex int
+	txParentIndex uint64
+	txSigners     []string
+)
+
+// actionTypes maps the action type names accepted by tx build.
+var actionTypes = map[string]types.ActionType{
+	"CallContract":          types.CallContract,
+	"CreateContract":        types.CreateContract,
+	"CreateAccount":         types.CreateAccount,
+	"UpdateAccount":         types.UpdateAccount,
+	"DeleteAccount":         types.DeleteAccount,
+	"UpdateAccountAuthor":   types.UpdateAccountAuthor,
+	"SetAccountAuthorDelay": types.SetAccountAuthorDelay,
+	"CancelAccountAuthor":   types.CancelAccountAuthor,
+	"ProposeAccountAction":  types.ProposeAccountAction,
+	"ApproveAccountAction":  types.ApproveAccountAction,
+	"ApproveAllowance":      types.ApproveAllowance,
+	"TransferFrom":          types.TransferFrom,
+	"LockHTLC":              types.LockHTLC,
+	"ClaimHTLC":             types.ClaimHTLC,
+	"RefundHTLC":            types.RefundHTLC,
+	"DistributeDividend":    types.DistributeDividend,
+	"ClaimDividend":         types.ClaimDividend,
+	"ReclaimDividend":       types.ReclaimDividend,
+	"AtomicSwap":            types.AtomicSwap,
+	"CancelSwapOrder":       types.CancelSwapOrder,
+	"IncreaseAsset":         types.IncreaseAsset,
+	"IssueAsset":            types.IssueAsset,
+	"DestroyAsset":          types.DestroyAsset,
+	"SetAssetOwner":         types.SetAssetOwner,
+	"UpdateAsset":           types.UpdateAsset,
+	"Transfer":              types.Transfer,
+	"UpdateAssetContract":   types.UpdateAssetContract,
+	"FreezeAsset":           types.FreezeAsset,
+	"FreezeAccountAsset":    types.FreezeAccountAsset,
+	"VestingTransfer":       types.VestingTransfer,
+	"RejectVestingTransfer": types.RejectVestingTransfer,
+	"IssueNFTCollection":    types.IssueNFTCollection,
+	"MintNFT":               types.MintNFT,
+	"TransferNFT":           types.TransferNFT,
+	"BurnNFT":               types.BurnNFT,
+	"SetAssetMetadata":      types.SetAssetMetadata,
+	"SetSubAssetPolicy":     types.SetSubAssetPolicy,
+	"RegCandidate":          types.RegCandidate,
+	"UpdateCandidate":       types.UpdateCandidate,
+	"UnregCandidate":        types.UnregCandidate,
+	"RefundCandidate":       types.RefundCandidate,
+	"VoteCandidate":         types.VoteCandidate,
+	"UpdateCandidatePubKey": types.UpdateCandidatePubKey,
+	"KickedCandidate":       types.KickedCandidate,
+	"ExitTakeOver":          types.ExitTakeOver,
+	"RemoveKickedCandidate": types.RemoveKickedCandidate,
+	"ReportDoubleSign":      types.ReportDoubleSign,
+	"ClaimVoterReward":      types.ClaimVoterReward,
+	"WithdrawFee":           types.WithdrawFee,
+}
+
+// payloadTypes returns the typed payload of the action type.
+var payloadTypes = map[types.ActionType]func() interface{}{
+	types.CreateAccount:         func() interface{} { return new(accountmanager.CreateAccountAction) },
+	types.UpdateAccount:         func() interface{} { return new(accountmanager.UpdataAccountAction) },
+	types.UpdateAccountAuthor:   func() interface{} { return new(accountmanager.AccountAuthorAction) },
+	types.SetAccountAuthorDelay: func() interface{} { return new(accountmanager.AuthorDelayAction) },
+	types.CancelAccountAuthor:   func() interface{} { return new(accountmanager.CancelAuthorAction) },
+	types.ProposeAccountAction:  func() interface{} { return new(accountmanager.ProposeAction) },
+	types.ApproveAccountAction:  func() interface{} { return new(accountmanager.ApproveAction) },
+	types.ApproveAllowance:      func() interface{} { return new(accountmanager.ApproveAllowanceAction) },
+	types.TransferFrom:          func() interface{} { return new(accountmanager.TransferFromAction) },
+	types.LockHTLC:              func() interface{} { return new(accountmanager.LockHTLCAction) },
+	types.ClaimHTLC:             func() interface{} { return new(accountmanager.ClaimHTLCAction) },
+	types.RefundHTLC:            func() interface{} { return new(accountmanager.RefundHTLCAction) },
+	types.DistributeDividend:    func() interface{} { return new(accountmanager.DistributeDividendAction) },
+	types.ClaimDividend:         func() interface{} { return new(accountmanager.ClaimDividendAction) },
+	types.ReclaimDividend:       func() interface{} { return new(accountmanager.ReclaimDividendAction) },
+	types.AtomicSwap:            func() interface{} { return new(accountmanager.AtomicSwapAction) },
+	types.CancelSwapOrder:       func() interface{} { return new(accountmanager.CancelSwapOrderAction) },
+	types.IssueAsset:            func() interface{} { return new(accountmanager.IssueAsset) },
+	types.IncreaseAsset:         func() interface{} { return new(accountmanager.IncAsset) },
+	types.UpdateAsset:           func() interface{} { return new(accountmanager.UpdateAsset) },
+	types.SetAssetOwner:         func() interface{} { return new(accountmanager.UpdateAssetOwner) },
+	types.UpdateAssetContract:   func() interface{} { return new(accountmanager.UpdateAssetContract) },
+	types.FreezeAsset:           func() interface{} { return new(accountmanager.FreezeAsset) },
+	types.FreezeAccountAsset:    func() interface{} { return new(accountmanager.FreezeAccountAsset) },
+	types.VestingTransfer:       func() interface{} { return new(accountmanager.VestingTransfer) },
+	types.IssueNFTCollection:    func() interface{} { return new(accountmanager.IssueNFTCollection) },
+	types.MintNFT:               func() interface{} { return new(accountmanager.MintNFT) },
+	types.TransferNFT:           func() interface{} { return new(accountmanager.TransferNFT) },
+	types.BurnNFT:               func() interface{} { return new(accountmanager.BurnNFT) },
+	types.SetAssetMetadata:      func() interface{} { return new(accountmanager.SetAssetMetadata) },
+	types.SetSubAssetPolicy:     func() interface{} { return new(accountmanager.SetSubAssetPolicy) },
+	types.RegCandidate:          func() interface{} { return new(dpos.RegisterCandidate) },
+	types.UpdateCandidate:       func() interface{} { return new(dpos.UpdateCandidate) },
+	types.UpdateCandidatePubKey: func() interface{} { return new(dpos.UpdateCandidatePubKey) },
+	types.VoteCandidate:         func() interface{} { return new(dpos.VoteCandidate) },
+	types.KickedCandidate:       func() interface{} { return new(dpos.KickedCandidate) },
+	types.RemoveKickedCandidate: func() interface{} { return new(dpos.RemoveKickedCandidate) },
+	types.ReportDoubleSign:      func() interface{} { return new(dpos.ReportDoubleSign) },
+	types.ClaimVoterReward:      func() interface{} { return new(dpos.ClaimVoterReward) },
+}
+
+// txArgs is the json description of an unsigned transaction.
+type txArgs struct {
+	GasAssetID uint64        `json:"gasAssetID"`
+	GasPrice   *big.Int      `json:"gasPrice"`
+	Actions    []*actionArgs `json:"actions"`
+}
+
+// actionArgs is the json description of an action. Type is the action type
+// name or number, Payload is either a typed json object or a hex string.
+type actionArgs struct {
+	Type    json.RawMessage `json:"type"`
+	From    common.Name     `json:"from"`
+	To      common.Name     `json:"to"`
+	Nonce   uint64          `json:"nonce"`
+	AssetID uint64          `json:"assetID"`
+	Gas     uint64          `json:"gas"`
+	Value   *big.Int        `json:"value"`
+	Remark  hexutil.Bytes   `json:"remark"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type signDataResult struct {
+	Index  []uint64      `json:"index"`
+	PubKey common.PubKey `json:"pubKey"`
+}
+
+type actionResult struct {
+	*types.RPCAction
+	TypeName    string            `json:"typeName"`
+	Decoded     interface{}       `json:"decodedPayload,omitempty"`
+	ParentIndex uint64            `json:"parentIndex"`
+	Signatures  []*signDataResult `json:"signatures"`
+}
+
+type txResult struct {
+	Hash       common.Hash     `json:"txHash"`
+	SignHash   common.Hash     `json:"signHash"`
+	GasAssetID uint64          `json:"gasAssetID"`
+	GasPrice   *big.Int        `json:"gasPrice"`
+	Actions    []*actionResult `json:"actions"`
+}
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Build, sign, decode and send transactions offline",
+	Long:  `Build, sign, decode and send transactions offline`,
+	Args:  cobra.NoArgs,
+}
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build <tx json file>",
+	Short: "Build an unsigned raw transaction from its json description",
+	Long: `Build an unsigned raw transaction from its json description, e.g.
+{
+  "gasAssetID": 0,
+  "gasPrice": 100000000000,
+  "actions": [{
+    "type": "CreateAccount",
+    "from": "fractal.founder",
+    "to": "fractal.account",
+    "nonce": 0,
+    "assetID": 0,
+    "gas": 200000,
+    "value": 0,
+    "payload": {"accountName": "testaccount", "founder": "testaccount", "publicKey": "0x04..."}
+  }]
+}`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		tx, err := buildTx(data)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(encodeTx(tx))
+	},
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign <rawTx or file> --signer <privateKey file|keystore publicKey>[:<author index>]...",
+	Short: "Sign the actions of a raw transaction, appending to the existing signatures",
+	Long: `Sign the actions of a raw transaction, appending to the existing signatures.
+The author index is the dot separated path of the author in the account
+authors, e.g. "1" or "0.2" for the author of a sub account, default is "0".`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tx := decodeTx(readRawTx(args[0]))
+		if len(txSigners) == 0 {
+			jww.ERROR.Println("no signer specified")
+			os.Exit(1)
+		}
+		keys := make([]*types.KeyPair, 0, len(txSigners))
+		for _, s := range txSigners {
+			keys = append(keys, parseSigner(s))
+		}
+		if err := signTx(tx, new(big.Int).SetUint64(txChainID), txActionIndex, txParentIndex, keys); err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(1)
+		}
+		printJSON(encodeTx(tx))
+	},
+}
+
+var txDecodeCmd = &cobra.Command{
+	Use:   "decode <rawTx or file>",
+	Short: "Decode a raw transaction and recover the signing public keys",
+	Long:  `Decode a raw transaction and recover the signing public keys`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tx := decodeTx(readRawTx(args[0]))
+		printJSON(newTxResult(tx, new(big.Int).SetUint64(txChainID)))
+	},
+}
+
+var txSendCmd = &cobra.Command{
+	Use:   "send <rawTx or file>",
+	Short: "Send a signed raw transaction to the node",
+	Long:  `Send a signed raw transaction to the node`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var result common.Hash
+		clientCall(ipcEndpoint, &result, "ft_sendRawTransaction", hexutil.Bytes(readRawTx(args[0])))
+		printJSON(result)
+	},
+}
+
+var txSimulateCmd = &cobra.Command{
+	Use:   "simulate <rawTx or file>",
+	Short: "Simulate a raw transaction on the latest state without checking its signatures",
+	Long:  `Simulate a raw transaction on the latest state without checking its signatures`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tx := decodeTx(readRawTx(args[0]))
+		simArgs := rpcapi.SimulateArgs{GasAssetID: tx.GasAssetID(), GasPrice: tx.GasPrice()}
+		for _, a := range tx.GetActions() {
+			nonce := a.Nonce()
+			simAction := rpcapi.SimulateActionArgs{
+				ActionType: a.Type(),
+				From:       a.Sender(),
+				To:         a.Recipient(),
+				Nonce:      &nonce,
+				AssetID:    a.AssetID(),
+				Gas:        a.Gas(),
+				Value:      a.Value(),
+				Data:       a.Data(),
+				Remark:     a.Remark(),
+			}
+			if a.PayerIsExist() {
+				simAction.Payer = a.Payer()
+				simAction.PayerGasPrice = a.PayerGasPrice()
+			}
+			simArgs.Actions = append(simArgs.Actions, simAction)
+		}
+		result := new(interface{})
+		clientCall(ipcEndpoint, &result, "ft_simulateTransaction", simArgs, rpc.LatestBlockNumber)
+		printJSON(result)
+	},
+}
+
+func buildTx(data []byte) (*types.Transaction, error) {
+	var args txArgs
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	if len(args.Actions) == 0 {
+		return nil, types.ErrEmptyActions
+	}
+	actions := make([]*types.Action, 0, len(args.Actions))
+	for i, a := range args.Actions {
+		action, err := buildAction(a)
+		if err != nil {
+			return nil, fmt.Errorf("action %d: %v", i, err)
+		}
+		actions = append(actions, action)
+	}
+	return types.NewTransaction(args.GasAssetID, args.GasPrice, actions...), nil
+}
+
+func buildAction(a *actionArgs) (*types.Action, error) {
+	aType, err := parseActionType(a.Type)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := encodePayload(aType, a.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewAction(aType, a.From, a.To, a.Nonce, a.AssetID, a.Gas, a.Value, payload, a.Remark), nil
+}
+
+func parseActionType(raw json.RawMessage) (types.ActionType, error) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		if aType, ok := actionTypes[name]; ok {
+			return aType, nil
+		}
+		if num, err := strconv.ParseUint(name, 0, 64); err == nil {
+			return types.ActionType(num), nil
+		}
+		return 0, fmt.Errorf("unknown action type %v", name)
+	}
+	var num uint64
+	if err := json.Unmarshal(raw, &num); err != nil {
+		return 0, fmt.Errorf("invalid action type %s", string(raw))
+	}
+	return types.ActionType(num), nil
+}
+
+func encodePayload(aType types.ActionType, raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var hex hexutil.Bytes
+	if err := json.Unmarshal(raw, &hex); err == nil {
+		return hex, nil
+	}
+	newPayload, ok := payloadTypes[aType]
+	if !ok {
+		return nil, fmt.Errorf("action type %d has no typed payload, use hex", aType)
+	}
+	payload := newPayload()
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(payload)
+}
+
+// signTx appends the signatures of the keys to the action at the index, or
+// to all actions if the index is negative.
+func signTx(tx *types.Transaction, chainID *big.Int, actionIndex int, parentIndex uint64, keys []*types.KeyPair) error {
+	signer := types.NewSigner(chainID)
+	for i, action := range tx.GetActions() {
+		if actionIndex >= 0 && i != actionIndex {
+			continue
+		}
+		if err := types.SignActionWithMultiKey(action, tx, signer, parentIndex, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func actionTypeName(aType types.ActionType) string {
+	for name, t := range actionTypes {
+		if t == aType {
+			return name
+		}
+	}
+	return strconv.FormatUint(uint64(aType), 10)
+}
+
+func newTxResult(tx *types.Transaction, chainID *big.Int) *txResult {
+	signer := types.NewSigner(chainID)
+	result := &txResult{
+		Hash:       tx.Hash(),
+		SignHash:   signer.Hash(tx),
+		GasAssetID: tx.GasAssetID(),
+		GasPrice:   tx.GasPrice(),
+	}
+	for i, action := range tx.GetActions() {
+		ar := &actionResult{
+			RPCAction:   action.NewRPCAction(uint64(i)),
+			TypeName:    actionTypeName(action.Type()),
+			ParentIndex: action.GetSignParent(),
+			Signatures:  make([]*signDataResult, 0, len(action.GetSign())),
+		}
+		if newPayload, ok := payloadTypes[action.Type()]; ok && len(action.Data()) > 0 {
+			payload := newPayload()
+			if err := rlp.DecodeBytes(action.Data(), payload); err == nil {
+				ar.Decoded = payload
+			}
+		}
+		var pubKeys []common.PubKey
+		if len(action.GetSign()) > 0 && action.ChainID().Cmp(chainID) == 0 {
+			pubKeys, _ = types.RecoverMultiKey(signer, action, tx)
+		}
+		for j, sign := range action.GetSign() {
+			sd := &signDataResult{Index: sign.Index}
+			if j < len(pubKeys) {
+				sd.PubKey = pubKeys[j]
+			}
+			ar.Signatures = append(ar.Signatures, sd)
+		}
+		result.Actions = append(result.Actions, ar)
+	}
+	return result
+}
+
+// parseSigner parses <privateKey file|keystore publicKey>[:<author index>].
+func parseSigner(s string) *types.KeyPair {
+	key, indexStr := s, "0"
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		key, indexStr = s[:i], s[i+1:]
+	}
+	var index []uint64
+	for _, n := range strings.Split(indexStr, ".") {
+		index = append(index, parseUint64(n))
+	}
+	if common.IsHexPubKey(key) {
+		k, err := makeKeyStore().GetKey(common.HexToPubKey(key), getPassphrase(false))
+		if err != nil {
+			jww.ERROR.Println(key, err)
+			os.Exit(1)
+		}
+		return types.MakeKeyPair(k.PrivateKey, index)
+	}
+	priv, err := crypto.LoadECDSA(key)
+	if err != nil {
+		jww.ERROR.Println("load private key failed.", "path", key, "err", err)
+		os.Exit(1)
+	}
+	return types.MakeKeyPair(priv, index)
+}
+
+// readRawTx reads the hex encoded raw transaction from the argument or the
+// file it names.
+func readRawTx(arg string) []byte {
+	if data, err := ioutil.ReadFile(arg); err == nil {
+		arg = string(data)
+	}
+	arg = strings.Trim(strings.TrimSpace(arg), `"`)
+	raw, err := hexutil.Decode(arg)
+	if err != nil {
+		jww.ERROR.Println("invalid raw transaction", err)
+		os.Exit(1)
+	}
+	return raw
+}
+
+func decodeTx(raw []byte) *types.Transaction {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		jww.ERROR.Println("decode raw transaction failed", err)
+		os.Exit(1)
+	}
+	return tx
+}
+
+func encodeTx(tx *types.Transaction) hexutil.Bytes {
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		jww.ERROR.Println(err)
+		os.Exit(1)
+	}
+	return raw
+}
+
+func init() {
+	RootCmd.AddCommand(txCmd)
+	txCmd.AddCommand(txBuildCmd, txSignCmd, txDecodeCmd, txSendCmd, txSimulateCmd)
+	txCmd.PersistentFlags().Uint64Var(&txChainID, "chainid", params.DefaultChainconfig.ChainID.Uint64(), "Chain id used for signing")
+	txSignCmd.Flags().StringSliceVarP(&txSigners, "signer", "s", nil, "Signer private key file or keystore public key, with optional author index")
+	txSignCmd.Flags().IntVarP(&txActionIndex, "action", "a", -1, "Index of the action to sign, -1 signs all actions")
+	txSignCmd.Flags().Uint64Var(&txParentIndex, "parent", 0, "Parent index of the signatures")
+	txSignCmd.Flags().StringVarP(&ftCfgInstance.NodeCfg.DataDir, "datadir", "d", ftCfgInstance.NodeCfg.DataDir, "Data directory for the keystore")
+	txSignCmd.Flags().StringVar(&ftCfgInstance.NodeCfg.KeyStore, "keystore", ftCfgInstance.NodeCfg.KeyStore, "Directory for the keystore (default = inside the datadir)")
+	txSignCmd.Flags().StringVarP(&passwordFile, "password", "p", "", "Password file to use for non-interactive passphrase input")
+	txSendCmd.Flags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+	txSimulateCmd.Flags().StringVarP(&ipcEndpoint, "ipcpath", "i", defaultIPCEndpoint(params.ClientIdentifier), "IPC Endpoint path")
+}
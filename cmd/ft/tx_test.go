@@ -0,0 +1,222 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestParseActionType(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    types.ActionType
+		wantErr bool
+	}{
+		{`"Transfer"`, types.Transfer, false},
+		{`"CreateAccount"`, types.CreateAccount, false},
+		{`"ReclaimDividend"`, types.ReclaimDividend, false},
+		{`"0x102"`, types.DeleteAccount, false},
+		{`"257"`, types.UpdateAccount, false},
+		{`769`, types.UpdateCandidate, false},
+		{`"NoSuchAction"`, 0, true},
+		{`true`, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseActionType(json.RawMessage(tt.raw))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseActionType(%s) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseActionType(%s) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+
+	// every named action type round-trips through its name
+	for name, aType := range actionTypes {
+		if got, err := parseActionType(json.RawMessage(`"` + name + `"`)); err != nil || got != aType {
+			t.Errorf("parseActionType(%s) = %v %v, want %v", name, got, err, aType)
+		}
+		if got := actionTypeName(aType); got != name {
+			t.Errorf("actionTypeName(%v) = %v, want %v", aType, got, name)
+		}
+	}
+}
+
+func TestEncodePayload(t *testing.T) {
+	pubKey := common.HexToPubKey("0x047db227d7094ce215c3a0f57e1bcc732551fe351f94249471934567e0f5dc1bf795962b8cccb87a2eb56b29fbe37d614e2f4c3c45b789ae4f1f51f4cb21972ffd")
+	create, _ := rlp.EncodeToBytes(&accountmanager.CreateAccountAction{
+		AccountName: "testaccount",
+		Founder:     "testaccount",
+		PublicKey:   pubKey,
+	})
+	tests := []struct {
+		aType   types.ActionType
+		raw     string
+		want    []byte
+		wantErr bool
+	}{
+		{types.Transfer, ``, nil, false},
+		{types.Transfer, `null`, nil, false},
+		{types.Transfer, `"0x0102"`, []byte{1, 2}, false},
+		{types.CreateAccount, `"0x0102"`, []byte{1, 2}, false},
+		{types.CreateAccount, `{"accountName": "testaccount", "founder": "testaccount", "publicKey": "` + pubKey.String() + `"}`, create, false},
+		{types.CreateAccount, `{"description": 1}`, nil, true},
+		{types.Transfer, `{"to": "testaccount"}`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := encodePayload(tt.aType, json.RawMessage(tt.raw))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("encodePayload(%v, %s) error = %v, wantErr %v", tt.aType, tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("encodePayload(%v, %s) = %x, want %x", tt.aType, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTx(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"no actions", `{"gasAssetID": 0, "gasPrice": 1, "actions": []}`, true},
+		{"unknown type", `{"actions": [{"type": "NoSuchAction"}]}`, true},
+		{"invalid payload", `{"actions": [{"type": "CreateAccount", "payload": {"description": 1}}]}`, true},
+		{"invalid json", `{"actions": [`, true},
+	}
+	for _, tt := range tests {
+		if _, err := buildTx([]byte(tt.data)); (err != nil) != tt.wantErr {
+			t.Errorf("%s: buildTx error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	data := `{
+  "gasAssetID": 1,
+  "gasPrice": 100000000000,
+  "actions": [{
+    "type": "Transfer",
+    "from": "testfrom",
+    "to": "testto",
+    "nonce": 3,
+    "assetID": 1,
+    "gas": 30000,
+    "value": 12345,
+    "remark": "0x0a0b"
+  }, {
+    "type": "ClaimDividend",
+    "from": "testfrom",
+    "to": "fractal.account",
+    "nonce": 4,
+    "gas": 30000,
+    "value": 0,
+    "payload": {"id": 7}
+  }]
+}`
+	tx, err := buildTx([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the raw transaction decodes to the same transaction
+	decoded := decodeTx(encodeTx(tx))
+	if decoded.GasAssetID() != 1 || decoded.GasPrice().Cmp(big.NewInt(100000000000)) != 0 || len(decoded.GetActions()) != 2 {
+		t.Fatalf("transaction mismatch %v", decoded)
+	}
+	transfer := decoded.GetActions()[0]
+	if transfer.Type() != types.Transfer || transfer.Sender() != "testfrom" || transfer.Recipient() != "testto" ||
+		transfer.Nonce() != 3 || transfer.AssetID() != 1 || transfer.Gas() != 30000 ||
+		transfer.Value().Cmp(big.NewInt(12345)) != 0 || !bytes.Equal(transfer.Remark(), []byte{0x0a, 0x0b}) {
+		t.Fatalf("transfer mismatch %v", transfer.NewRPCAction(0))
+	}
+	var claim accountmanager.ClaimDividendAction
+	if err := rlp.DecodeBytes(decoded.GetActions()[1].Data(), &claim); err != nil || claim.ID != 7 {
+		t.Fatalf("payload mismatch %v %v", claim, err)
+	}
+	result := newTxResult(decoded, big.NewInt(1))
+	if result.Hash != tx.Hash() || result.Actions[1].TypeName != "ClaimDividend" ||
+		!reflect.DeepEqual(result.Actions[1].Decoded, &claim) {
+		t.Fatalf("decode result mismatch %v", result.Actions[1])
+	}
+}
+
+func TestSignTxMultiSigner(t *testing.T) {
+	tx, err := buildTx([]byte(`{"gasPrice": 1, "actions": [
+		{"type": "Transfer", "from": "testfrom", "to": "testto", "gas": 30000, "value": 1},
+		{"type": "Transfer", "from": "testfrom", "to": "testto", "nonce": 1, "gas": 30000, "value": 1}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(1)
+	wantIndex := [][]uint64{{0}, {1}, {0, 2}}
+	var keys []*types.KeyPair
+	var pubKeys []common.PubKey
+	for _, index := range wantIndex {
+		priv, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKeys = append(pubKeys, common.BytesToPubKey(crypto.FromECDSAPub(&priv.PublicKey)))
+		keys = append(keys, types.MakeKeyPair(priv, index))
+	}
+
+	// the signers sign the raw transaction in turn, like repeated "tx sign" runs
+	if err := signTx(tx, chainID, 0, 1, keys[:1]); err != nil {
+		t.Fatal(err)
+	}
+	tx = decodeTx(encodeTx(tx))
+	if err := signTx(tx, chainID, 0, 1, keys[1:]); err != nil {
+		t.Fatal(err)
+	}
+	tx = decodeTx(encodeTx(tx))
+
+	action := tx.GetActions()[0]
+	if action.GetSignParent() != 1 {
+		t.Fatalf("parent index mismatch %v", action.GetSignParent())
+	}
+	if len(action.GetSign()) != len(wantIndex) {
+		t.Fatalf("signatures mismatch %v", len(action.GetSign()))
+	}
+	for i, sign := range action.GetSign() {
+		if !reflect.DeepEqual(sign.Index, wantIndex[i]) {
+			t.Fatalf("signature %d index mismatch %v, want %v", i, sign.Index, wantIndex[i])
+		}
+	}
+	recovered, err := types.RecoverMultiKey(types.NewSigner(chainID), action, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recovered, pubKeys) {
+		t.Fatalf("recovered keys mismatch %v, want %v", recovered, pubKeys)
+	}
+	// the action not selected stays unsigned
+	if len(tx.GetActions()[1].GetSign()) != 0 {
+		t.Fatalf("action 1 signed %v", tx.GetActions()[1].GetSign())
+	}
+}
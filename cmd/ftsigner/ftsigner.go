@@ -0,0 +1,233 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// ftsigner is a reference external signer of block producers. It holds the
+// keys encrypted in a keystore directory (see "ft account"), refuses to sign
+// two headers at the same slot and logs every request:
+//
+//	ftsigner --keystore ./keystore --keys 0x04... --ipcpath ./ftsigner.ipc
+//	ft miner setcoinbasesigner <name> ./ftsigner.ipc
+//
+// The HTTP endpoint serves only the clients presenting the token in the
+// token file as the basic auth password:
+//
+//	ftsigner --keys 0x04... --http 127.0.0.1:8550 --httptoken ./token
+//	ft miner setcoinbasesigner <name> http://ftsigner:<token>@localhost:8550
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/cmd/utils"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/keystore"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/signer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dataDir      = "./ftsigner"
+	keyStoreDir  string
+	pubKeys      []string
+	passwordFile string
+	ipcPath      = "ftsigner.ipc"
+	httpAddr     string
+	httpToken    string
+	protection   = "protection.json"
+	auditLog     = "audit.log"
+	logLevel     = 3
+)
+
+// RootCmd represents the base command when called without any subcommands
+var RootCmd = &cobra.Command{
+	Use:   "ftsigner",
+	Short: "ftsigner is a remote signer of fractal block producers",
+	Long:  `ftsigner is a remote signer of fractal block producers`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(logLevel), log.StreamHandler(os.Stderr, log.TerminalFormat(false))))
+		if err := run(); err != nil {
+			log.Error("ftsigner start failed", "error", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func run() error {
+	if len(pubKeys) == 0 {
+		return fmt.Errorf("no keys to sign")
+	}
+	var token string
+	if httpAddr != "" {
+		if httpToken == "" {
+			return fmt.Errorf("no token file for the HTTP endpoint")
+		}
+		text, err := ioutil.ReadFile(httpToken)
+		if err != nil {
+			return err
+		}
+		if token = strings.TrimSpace(string(text)); token == "" {
+			return fmt.Errorf("empty token file %v", httpToken)
+		}
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return err
+	}
+	if keyStoreDir == "" {
+		keyStoreDir = filepath.Join(dataDir, "keystore")
+	}
+	passphrase, err := readPassphrase()
+	if err != nil {
+		return err
+	}
+	ks := keystore.NewKeyStore(keyStoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+	privKeys := make([]*ecdsa.PrivateKey, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		if !common.IsHexPubKey(pubKey) {
+			return fmt.Errorf("invalid public key %v", pubKey)
+		}
+		key, err := ks.GetKey(common.HexToPubKey(pubKey), passphrase)
+		if err != nil {
+			return fmt.Errorf("unlock %v failed: %v", pubKey, err)
+		}
+		privKeys = append(privKeys, key.PrivateKey)
+	}
+
+	p, err := signer.NewProtection(resolvePath(protection))
+	if err != nil {
+		return err
+	}
+	audit, err := signer.NewAuditLog(resolvePath(auditLog))
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	apis := signer.APIs(signer.NewKeySigner(privKeys, p, audit))
+
+	ipcListener, ipcHandler, err := rpc.StartIPCEndpoint(resolvePath(ipcPath), apis)
+	if err != nil {
+		return err
+	}
+	defer ipcHandler.Stop()
+	defer ipcListener.Close()
+	log.Info("IPC endpoint opened", "url", ipcListener.Addr())
+
+	if httpAddr != "" {
+		httpListener, httpHandler, err := startHTTPEndpoint(httpAddr, token, apis)
+		if err != nil {
+			return err
+		}
+		defer httpHandler.Stop()
+		defer httpListener.Close()
+		log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%v", httpListener.Addr()))
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	<-sigc
+	log.Info("Got interrupt, shutting down...")
+	return nil
+}
+
+// startHTTPEndpoint starts the HTTP endpoint serving only the requests with
+// the token as the basic auth password.
+func startHTTPEndpoint(endpoint string, token string, apis []rpc.API) (net.Listener, *rpc.Server, error) {
+	handler := rpc.NewServer()
+	for _, api := range apis {
+		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, nil, err
+		}
+	}
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	server := rpc.NewHTTPServer(nil, []string{"localhost"}, handler)
+	next := server.Handler
+	server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || subtle.ConstantTimeCompare([]byte(password), []byte(token)) != 1 {
+			log.Warn("Unauthorized sign request", "remote", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+	go server.Serve(listener)
+	return listener, handler, nil
+}
+
+// resolvePath resolves the path relative to the data directory.
+func resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dataDir, path)
+}
+
+// readPassphrase reads the passphrase from the password file, or prompts
+// for it on the standard input.
+func readPassphrase() (string, error) {
+	if passwordFile != "" {
+		text, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(strings.Split(string(text), "\n")[0], "\r"), nil
+	}
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && len(line) == 0 {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func init() {
+	RootCmd.AddCommand(utils.VersionCmd)
+	flags := RootCmd.Flags()
+	flags.StringVarP(&dataDir, "datadir", "d", dataDir, "Data directory of the signer")
+	flags.StringVar(&keyStoreDir, "keystore", keyStoreDir, "Directory for the keystore (default = inside the datadir)")
+	flags.StringSliceVar(&pubKeys, "keys", pubKeys, "Public keys of the keystore to sign with")
+	flags.StringVarP(&passwordFile, "password", "p", passwordFile, "Password file to unlock the keys")
+	flags.StringVar(&ipcPath, "ipcpath", ipcPath, "IPC endpoint path (relative to the datadir)")
+	flags.StringVar(&httpAddr, "http", httpAddr, "HTTP endpoint listening address, disabled when empty")
+	flags.StringVar(&httpToken, "httptoken", httpToken, "Token file of the HTTP endpoint, clients present the token as the basic auth password")
+	flags.StringVar(&protection, "protection", protection, "Slashing protection file of signed headers (relative to the datadir)")
+	flags.StringVar(&auditLog, "audit", auditLog, "Audit log file of sign requests (relative to the datadir)")
+	flags.IntVar(&logLevel, "log_level", logLevel, "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail")
+}
+
+func main() {
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(-1)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/signer"
+)
+
+func TestHTTPEndpointToken(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&privKey.PublicKey))
+	p, _ := signer.NewProtection("")
+	listener, handler, err := startHTTPEndpoint("127.0.0.1:0", "secret", signer.APIs(signer.NewKeySigner([]*ecdsa.PrivateKey{privKey}, p, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Stop()
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	for _, c := range []struct {
+		userinfo string
+		ok       bool
+	}{
+		{"", false},
+		{"ftsigner:wrong@", false},
+		{"ftsigner:secret@", true},
+	} {
+		remote, err := signer.NewRemoteSigner(fmt.Sprintf("http://%vlocalhost:%d", c.userinfo, port))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKeys, err := remote.PubKeys()
+		remote.Close()
+		if !c.ok {
+			if err == nil {
+				t.Fatalf("request with %q served", c.userinfo)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pubKeys) != 1 || pubKeys[0] != pubKey {
+			t.Fatalf("public keys mismatch %v", pubKeys)
+		}
+	}
+}
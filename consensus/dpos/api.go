@@ -17,17 +17,34 @@
 package dpos
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/consensus"
+	"github.com/fractalplatform/fractal/event"
 	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
 )
 
+// maxUptimeEpochs max epochs of a candidate uptime query
+const maxUptimeEpochs = 1000
+
+// CandidateUptime produced and scheduled blocks of a candidate in epochs
+type CandidateUptime struct {
+	Candidate     string  `json:"candidate"`
+	FromEpoch     uint64  `json:"fromEpoch"`
+	ToEpoch       uint64  `json:"toEpoch"`
+	Counter       uint64  `json:"shouldCounter"`
+	ActualCounter uint64  `json:"actualCounter"`
+	Uptime        float64 `json:"uptime"`
+}
+
 // API exposes dpos related methods for the RPC interface.
 type API struct {
 	dpos  *Dpos
@@ -623,6 +640,194 @@ func (api *API) SnapShotTime(epoch uint64) (interface{}, error) {
 	return res, nil
 }
 
+// Commission get commission rate of the candidate in percent, the next rate applies from the epoch on
+func (api *API) Commission(candidate string) (*Commission, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	commission, err := sys.GetCommission(candidate)
+	if err != nil {
+		return nil, err
+	}
+	if commission == nil {
+		return &Commission{Rate: MaxCommissionRate, Next: MaxCommissionRate}, nil
+	}
+	return commission, nil
+}
+
+// VoterRewards get block rewards shared to the voter by the candidates it voted in the epoch
+func (api *API) VoterRewards(epoch uint64, voter string) ([]*VoterReward, error) {
+	if epoch == 0 {
+		epoch, _ = api.epoch(api.chain.CurrentHeader().Number.Uint64())
+	}
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	voterInfos, err := sys.GetVotersByVoter(epoch, voter)
+	if err != nil {
+		return nil, err
+	}
+	rewards := []*VoterReward{}
+	for _, voterInfo := range voterInfos {
+		reward, err := sys.GetVoterReward(epoch, voter, voterInfo.Candidate)
+		if err != nil {
+			return nil, err
+		}
+		rewards = append(rewards, reward)
+	}
+	return rewards, nil
+}
+
+// DoubleSignRecords get double sign slashing records of the candidate
+func (api *API) DoubleSignRecords(candidate string) ([]*DoubleSignRecord, error) {
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	return sys.GetDoubleSignRecords(candidate)
+}
+
+// MissedSlots get missed slots of the epoch
+func (api *API) MissedSlots(epoch uint64) ([]*MissedSlot, error) {
+	if epoch == 0 {
+		epoch, _ = api.epoch(api.chain.CurrentHeader().Number.Uint64())
+	}
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	return missedSlots(sys, epoch, 0)
+}
+
+// missedSlots get missed slots of the epoch recorded at the number, zero means any number
+func missedSlots(sys *System, epoch uint64, number uint64) ([]*MissedSlot, error) {
+	size, err := sys.MissedSlotsSize(epoch)
+	if err != nil {
+		return nil, err
+	}
+	slots := []*MissedSlot{}
+	for index := size; index > 0; index-- {
+		slot, err := sys.GetMissedSlot(epoch, index-1)
+		if err != nil {
+			return nil, err
+		}
+		if slot == nil {
+			continue
+		}
+		if number != 0 && slot.Number != number {
+			if slot.Number < number {
+				break
+			}
+			continue
+		}
+		slots = append(slots, slot)
+	}
+	for i, j := 0, len(slots)-1; i < j; i, j = i+1, j-1 {
+		slots[i], slots[j] = slots[j], slots[i]
+	}
+	return slots, nil
+}
+
+// CandidateUptime get produced and scheduled blocks of the candidate from epoch to epoch
+func (api *API) CandidateUptime(name string, fromEpoch uint64, toEpoch uint64) (*CandidateUptime, error) {
+	if toEpoch == 0 {
+		toEpoch, _ = api.epoch(api.chain.CurrentHeader().Number.Uint64())
+	}
+	if fromEpoch > toEpoch || toEpoch-fromEpoch >= maxUptimeEpochs {
+		return nil, fmt.Errorf("invalid epoch range %v-%v(max %v epochs)", fromEpoch, toEpoch, maxUptimeEpochs)
+	}
+	sys, err := api.system()
+	if err != nil {
+		return nil, err
+	}
+	uptime := &CandidateUptime{Candidate: name, FromEpoch: fromEpoch, ToEpoch: toEpoch}
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		gstate, err := sys.GetState(epoch)
+		if err != nil {
+			if strings.Compare(err.Error(), "epoch not found") == 0 {
+				continue
+			}
+			return nil, err
+		}
+		candidate, err := sys.GetCandidate(epoch, name)
+		if err != nil {
+			return nil, err
+		}
+		if candidate == nil {
+			continue
+		}
+		// counters are accumulated from the previous epoch
+		counter, actualCounter := candidate.Counter, candidate.ActualCounter
+		if gstate.PreEpoch != epoch {
+			if pcandidate, err := sys.GetCandidate(gstate.PreEpoch, name); err != nil {
+				return nil, err
+			} else if pcandidate != nil {
+				counter -= pcandidate.Counter
+				actualCounter -= pcandidate.ActualCounter
+			}
+		}
+		uptime.Counter += counter
+		uptime.ActualCounter += actualCounter
+	}
+	if uptime.Counter > 0 {
+		uptime.Uptime = float64(uptime.ActualCounter) / float64(uptime.Counter)
+	}
+	return uptime, nil
+}
+
+// MissedSlotEvents send the missed slots each time a new block is appended to the chain
+func (api *API) MissedSlotEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		blockCh := make(chan *event.Event)
+		blockSub := event.Subscribe(nil, blockCh, event.ChainHeadEv, &types.Block{})
+		defer blockSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-blockCh:
+				block := ev.Data.(*types.Block)
+				state, err := api.chain.StateAt(block.Root())
+				if err != nil {
+					log.Warn("missed slots", "number", block.NumberU64(), "err", err)
+					continue
+				}
+				sys := NewSystem(state, api.dpos.config)
+				// skipped slots may span epochs since the parent block
+				epoch := api.dpos.config.epoch(block.Time().Uint64())
+				pepoch := epoch
+				if parent := api.chain.GetHeaderByNumber(block.NumberU64() - 1); parent != nil {
+					pepoch = api.dpos.config.epoch(parent.Time.Uint64())
+				}
+				for ; pepoch <= epoch; pepoch++ {
+					slots, err := missedSlots(sys, pepoch, block.NumberU64())
+					if err != nil {
+						log.Warn("missed slots", "number", block.NumberU64(), "err", err)
+						break
+					}
+					for _, slot := range slots {
+						notifier.Notify(rpcSub.ID, slot)
+					}
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 func (api *API) epoch(number uint64) (uint64, error) {
 	header := api.chain.GetHeaderByNumber(number)
 	if header == nil {
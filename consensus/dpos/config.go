@@ -50,6 +50,23 @@ var DefaultConfig = &Config{
 	ReferenceTime:                 1555776000000 * uint64(time.Millisecond), // 2019-04-21 00:00:00
 }
 
+// Double sign slashing rates in percent.
+const (
+	// DoubleSignSlashRate is the part of the candidate stake slashed for a double sign.
+	DoubleSignSlashRate = 50
+	// DoubleSignRewardRate is the part of the slashed stake rewarded to the reporter,
+	// the rest goes to the system account.
+	DoubleSignRewardRate = 20
+)
+
+// DoubleSignEvidenceEpochs is the number of epochs a double sign can be
+// reported after, the replaced pubkeys of candidates are kept as long.
+const DoubleSignEvidenceEpochs = 2
+
+// MaxCommissionRate is the max commission rate of a candidate in percent,
+// a candidate without commission rate keeps the whole block reward.
+const MaxCommissionRate = 100
+
 // Config dpos configures
 type Config struct {
 	// consensus fileds
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"time"
 )
 
 func TestConfig(t *testing.T) {
@@ -103,4 +104,5 @@ func TestConfig(t *testing.T) {
 	if err := DefaultConfig.IsValid(); err == nil {
 		panic(fmt.Errorf("Config IsValid err %v", err))
 	}
+	DefaultConfig.epochInter.Store(DefaultConfig.EpochInterval * uint64(time.Millisecond))
 }
@@ -54,12 +54,29 @@ type IDB interface {
 	SetTakeOver(uint64) error
 	GetTakeOver() (uint64, error)
 
-	Undelegate(string, *big.Int) (*types.Action, error)
+	Undelegate(string, *big.Int, uint64) (*types.Action, error)
 	IncAsset2Acct(string, string, *big.Int, uint64) (*types.Action, error)
 	GetBalanceByTime(name string, timestamp uint64) (*big.Int, error)
 	GetCandidateInfoByTime(epoch uint64, name string, timestamp uint64) (*CandidateInfo, error)
 
 	CanMine(name string, pub []byte) error
+	IsValidSign(name string, pub []byte) error
+
+	AddDoubleSignRecord(*DoubleSignRecord) error
+	GetDoubleSignRecords(string) ([]*DoubleSignRecord, error)
+	SetCandidatePubKeyRecords(string, []*CandidatePubKeyRecord) error
+	GetCandidatePubKeyRecords(string) ([]*CandidatePubKeyRecord, error)
+
+	SetCommission(string, *Commission) error
+	GetCommission(string) (*Commission, error)
+	SetVoterRewardPool(uint64, string, *big.Int) error
+	GetVoterRewardPool(uint64, string) (*big.Int, error)
+	SetVoterRewardClaimed(uint64, string, string, *big.Int) error
+	GetVoterRewardClaimed(uint64, string, string) (*big.Int, error)
+
+	AddMissedSlot(*MissedSlot) error
+	GetMissedSlot(uint64, uint64) (*MissedSlot, error)
+	MissedSlotsSize(uint64) (uint64, error)
 }
 
 // CandidateType candidate status
@@ -162,6 +179,63 @@ func (candidateInfo *CandidateInfo) invalid() bool {
 	return candidateInfo.Type != Normal
 }
 
+// DoubleSignRecord slashing of a candidate for a double sign
+type DoubleSignRecord struct {
+	Candidate string      `json:"candidate"`
+	Reporter  string      `json:"reporter"`
+	Epoch     uint64      `json:"epoch"`
+	Timestamp uint64      `json:"timestamp"` // slot of the conflicting headers
+	HashA     common.Hash `json:"hashA"`
+	HashB     common.Hash `json:"hashB"`
+	Slashed   *big.Int    `json:"slashed"` // slashed stake, including the reward
+	Reward    *big.Int    `json:"reward"`  // reward of the reporter
+	Refund    *big.Int    `json:"refund"`  // stake refunded to the candidate
+	Number    uint64      `json:"number"`
+}
+
+// CandidatePubKeyRecord pubkey of a candidate replaced in the epoch, an
+// empty pubkey means the candidate signed with its account authors
+type CandidatePubKeyRecord struct {
+	Epoch  uint64        `json:"epoch"`
+	PubKey common.PubKey `json:"pubkey"`
+}
+
+// Commission commission rate of a candidate in percent, Next replaces Rate
+// from the Epoch on, so that a candidate can't change the rate the voters
+// elected it with during an epoch
+type Commission struct {
+	Rate  uint64 `json:"rate"`
+	Next  uint64 `json:"next"`
+	Epoch uint64 `json:"epoch"`
+}
+
+// RateAt returns the commission rate in the epoch.
+func (c *Commission) RateAt(epoch uint64) uint64 {
+	if epoch >= c.Epoch {
+		return c.Next
+	}
+	return c.Rate
+}
+
+// MissedSlot slot the scheduled candidate did not produce a block at
+type MissedSlot struct {
+	Epoch     uint64 `json:"epoch"`
+	Timestamp uint64 `json:"timestamp"` // slot
+	Candidate string `json:"candidate"` // scheduled candidate
+	Producer  string `json:"producer"`  // producer of the next block which took over
+	Number    uint64 `json:"number"`    // number of the next block
+}
+
+// VoterReward block rewards shared to a voter of the candidate in the epoch
+type VoterReward struct {
+	Epoch     uint64   `json:"epoch"`
+	Candidate string   `json:"candidate"`
+	Quantity  *big.Int `json:"quantity"` // stake approved by the voter
+	Reward    *big.Int `json:"reward"`   // reward accrued so far
+	Claimed   *big.Int `json:"claimed"`
+	Pending   *big.Int `json:"pending"`
+}
+
 // VoterInfo info
 type VoterInfo struct {
 	Epoch               uint64   `json:"epoch"`
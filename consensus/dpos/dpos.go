@@ -78,13 +78,13 @@ func (s *stateDB) Delete(key string) error {
 	s.state.Delete(s.name, key)
 	return nil
 }
-func (s *stateDB) Undelegate(to string, amount *big.Int) (*types.Action, error) {
+func (s *stateDB) Undelegate(to string, amount *big.Int, number uint64) (*types.Action, error) {
 	action := types.NewAction(types.Transfer, common.StrToName(s.name), common.StrToName(to), 0, s.assetid, 0, amount, nil, nil)
 	accountDB, err := accountmanager.NewAccountManager(s.state)
 	if err != nil {
 		return action, err
 	}
-	return action, accountDB.TransferAsset(common.StrToName(s.name), common.StrToName(to), s.assetid, amount)
+	return action, accountDB.TransferAsset(common.StrToName(s.name), common.StrToName(to), s.assetid, amount, number)
 }
 func (s *stateDB) IncAsset2Acct(from string, to string, amount *big.Int, forkID uint64) (*types.Action, error) {
 	action := types.NewAction(types.IncreaseAsset, common.StrToName(s.name), common.StrToName(to), 0, s.assetid, 0, amount, nil, nil)
@@ -146,7 +146,7 @@ func Genesis(cfg *Config, state *state.StateDB, timestamp uint64, number uint64)
 }
 
 // SignFn signature function
-type SignFn func([]byte, *state.StateDB) ([]byte, error)
+type SignFn func([]byte, *types.Header, *state.StateDB) ([]byte, error)
 
 // Dpos dpos engine
 type Dpos struct {
@@ -504,6 +504,17 @@ func (dpos *Dpos) prepare1(chain consensus.IChainReader, header *types.Header, t
 			candidate = pcandidate
 		}
 		candidate.Counter++
+		if timestamp < header.Time.Uint64() && header.CurForkID() >= params.ForkID5 {
+			if err := sys.AddMissedSlot(&MissedSlot{
+				Epoch:     gstate.Epoch,
+				Timestamp: timestamp,
+				Candidate: name,
+				Producer:  header.Coinbase.String(),
+				Number:    header.Number.Uint64(),
+			}); err != nil {
+				return err
+			}
+		}
 	}
 	candidates[sys.config.getoffset(header.Time.Uint64(), params.ForkID2)].ActualCounter++
 	for _, candidate := range candidates {
@@ -520,6 +531,21 @@ func (dpos *Dpos) Finalize(chain consensus.IChainReader, header *types.Header, t
 		header.Root = state.IntermediateRoot()
 		return types.NewBlock(header, txs, receipts), nil
 	}
+	if fid := header.CurForkID(); fid >= params.ForkID5 {
+		accountDB, err := accountmanager.NewAccountManager(state)
+		if err != nil {
+			return nil, err
+		}
+		if err := accountDB.ApplyAuthorChanges(header.Number.Uint64(), fid); err != nil {
+			return nil, err
+		}
+		if err := accountDB.MigrateAssetHolderIndex(accountmanager.MaxAssetHolderMigration); err != nil {
+			return nil, err
+		}
+		if err := accountDB.MigrateSubAssetIndex(accountmanager.MaxSubAssetMigration); err != nil {
+			return nil, err
+		}
+	}
 	if fid := header.CurForkID(); fid >= params.ForkID2 {
 		return dpos.finalize1(chain, header, txs, receipts, state)
 	}
@@ -689,7 +715,13 @@ func (dpos *Dpos) finalize1(chain consensus.IChainReader, header *types.Header,
 	extraCounter := int64(0)
 	extraReward := new(big.Int).Mul(dpos.config.extraBlockReward(), big.NewInt(extraCounter))
 	reward := new(big.Int).Add(dpos.config.blockReward(), extraReward)
-	sys.IncAsset2Acct(dpos.config.SystemName, header.Coinbase.String(), reward, header.CurForkID())
+	if fid := header.CurForkID(); fid >= params.ForkID5 {
+		if err := sys.ShareBlockReward(header.Coinbase.String(), reward, fid); err != nil {
+			return nil, err
+		}
+	} else {
+		sys.IncAsset2Acct(dpos.config.SystemName, header.Coinbase.String(), reward, header.CurForkID())
+	}
 
 	blk := types.NewBlock(header, txs, receipts)
 	// first hard fork at a specific number
@@ -734,7 +766,7 @@ func (dpos *Dpos) Seal(chain consensus.IChainReader, block *types.Block, stop <-
 	if err != nil {
 		return nil, err
 	}
-	sighash, err := dpos.signFn(signHash(header, chain.Config().ChainID.Bytes()).Bytes(), state)
+	sighash, err := dpos.signFn(signHash(header, chain.Config().ChainID.Bytes()).Bytes(), header, state)
 	if err != nil {
 		return nil, err
 	}
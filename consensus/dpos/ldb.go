@@ -35,7 +35,7 @@ type IDatabase interface {
 	Put(key string, value []byte) error
 	Delete(key string) error
 
-	Undelegate(string, *big.Int) (*types.Action, error)
+	Undelegate(string, *big.Int, uint64) (*types.Action, error)
 	IncAsset2Acct(string, string, *big.Int, uint64) (*types.Action, error)
 	GetBalanceByTime(name string, timestamp uint64) (*big.Int, error)
 	IsValidSign(name string, pubkey []byte) error
@@ -61,6 +61,23 @@ var (
 	// TakeOver key
 	TakeOver = "takeover"
 
+	// DoubleSignKeyPrefix double sign records
+	DoubleSignKeyPrefix = "ds"
+	// CandidatePubKeyRecordKeyPrefix replaced pubkeys of candidate
+	CandidatePubKeyRecordKeyPrefix = "pkr"
+
+	// CommissionKeyPrefix candidate commission rate
+	CommissionKeyPrefix = "cm"
+	// VoterRewardPoolKeyPrefix voter reward pool of candidate
+	VoterRewardPoolKeyPrefix = "vrp"
+	// VoterRewardClaimedKeyPrefix voter reward claimed
+	VoterRewardClaimedKeyPrefix = "vrc"
+
+	// MissedSlotKeyPrefix missed slots
+	MissedSlotKeyPrefix = "ms"
+	// MissedSlotSizeKeyPrefix missed slots size of epoch
+	MissedSlotSizeKeyPrefix = "mss"
+
 	// StateKeyPrefix globalState
 	StateKeyPrefix = "s"
 	// LastestStateKey lastest
@@ -457,6 +474,175 @@ func (db *LDB) GetTakeOver() (uint64, error) {
 	return epoch, nil
 }
 
+// AddDoubleSignRecord append a double sign record of the candidate
+func (db *LDB) AddDoubleSignRecord(record *DoubleSignRecord) error {
+	records, err := db.GetDoubleSignRecords(record.Candidate)
+	if err != nil {
+		return err
+	}
+	key := strings.Join([]string{DoubleSignKeyPrefix, record.Candidate}, Separator)
+	if val, err := rlp.EncodeToBytes(append(records, record)); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetDoubleSignRecords get double sign records of the candidate
+func (db *LDB) GetDoubleSignRecords(candidate string) ([]*DoubleSignRecord, error) {
+	key := strings.Join([]string{DoubleSignKeyPrefix, candidate}, Separator)
+	records := []*DoubleSignRecord{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return records, nil
+	} else if err := rlp.DecodeBytes(val, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SetCandidatePubKeyRecords set the replaced pubkeys of the candidate
+func (db *LDB) SetCandidatePubKeyRecords(candidate string, records []*CandidatePubKeyRecord) error {
+	key := strings.Join([]string{CandidatePubKeyRecordKeyPrefix, candidate}, Separator)
+	if len(records) == 0 {
+		return db.Delete(key)
+	}
+	if val, err := rlp.EncodeToBytes(records); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCandidatePubKeyRecords get the replaced pubkeys of the candidate
+func (db *LDB) GetCandidatePubKeyRecords(candidate string) ([]*CandidatePubKeyRecord, error) {
+	key := strings.Join([]string{CandidatePubKeyRecordKeyPrefix, candidate}, Separator)
+	records := []*CandidatePubKeyRecord{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return records, nil
+	} else if err := rlp.DecodeBytes(val, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SetCommission set commission rate of the candidate
+func (db *LDB) SetCommission(candidate string, commission *Commission) error {
+	key := strings.Join([]string{CommissionKeyPrefix, candidate}, Separator)
+	if val, err := rlp.EncodeToBytes(commission); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCommission get commission rate of the candidate
+func (db *LDB) GetCommission(candidate string) (*Commission, error) {
+	key := strings.Join([]string{CommissionKeyPrefix, candidate}, Separator)
+	commission := &Commission{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	} else if err := rlp.DecodeBytes(val, commission); err != nil {
+		return nil, err
+	}
+	return commission, nil
+}
+
+// SetVoterRewardPool set rewards shared to the voters of the candidate in the epoch
+func (db *LDB) SetVoterRewardPool(epoch uint64, candidate string, amount *big.Int) error {
+	key := strings.Join([]string{VoterRewardPoolKeyPrefix, fmt.Sprintf("0x%x_%s", epoch, candidate)}, Separator)
+	return db.putBig(key, amount)
+}
+
+// GetVoterRewardPool get rewards shared to the voters of the candidate in the epoch
+func (db *LDB) GetVoterRewardPool(epoch uint64, candidate string) (*big.Int, error) {
+	key := strings.Join([]string{VoterRewardPoolKeyPrefix, fmt.Sprintf("0x%x_%s", epoch, candidate)}, Separator)
+	return db.getBig(key)
+}
+
+// SetVoterRewardClaimed set rewards claimed by the voter of the candidate in the epoch
+func (db *LDB) SetVoterRewardClaimed(epoch uint64, candidate string, voter string, amount *big.Int) error {
+	key := strings.Join([]string{VoterRewardClaimedKeyPrefix, fmt.Sprintf("0x%x_%s_%s", epoch, candidate, voter)}, Separator)
+	return db.putBig(key, amount)
+}
+
+// GetVoterRewardClaimed get rewards claimed by the voter of the candidate in the epoch
+func (db *LDB) GetVoterRewardClaimed(epoch uint64, candidate string, voter string) (*big.Int, error) {
+	key := strings.Join([]string{VoterRewardClaimedKeyPrefix, fmt.Sprintf("0x%x_%s_%s", epoch, candidate, voter)}, Separator)
+	return db.getBig(key)
+}
+
+func (db *LDB) putBig(key string, amount *big.Int) error {
+	if val, err := rlp.EncodeToBytes(amount); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *LDB) getBig(key string) (*big.Int, error) {
+	amount := big.NewInt(0)
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return amount, nil
+	} else if err := rlp.DecodeBytes(val, amount); err != nil {
+		return nil, err
+	}
+	return amount, nil
+}
+
+// AddMissedSlot append a missed slot of the epoch
+func (db *LDB) AddMissedSlot(slot *MissedSlot) error {
+	size, err := db.MissedSlotsSize(slot.Epoch)
+	if err != nil {
+		return err
+	}
+	key := strings.Join([]string{MissedSlotKeyPrefix, fmt.Sprintf("0x%x_0x%x", slot.Epoch, size)}, Separator)
+	if val, err := rlp.EncodeToBytes(slot); err != nil {
+		return err
+	} else if err := db.Put(key, val); err != nil {
+		return err
+	}
+	skey := strings.Join([]string{MissedSlotSizeKeyPrefix, fmt.Sprintf("0x%x", slot.Epoch)}, Separator)
+	return db.Put(skey, uint64tobytes(size+1))
+}
+
+// GetMissedSlot get missed slot of the epoch by index
+func (db *LDB) GetMissedSlot(epoch uint64, index uint64) (*MissedSlot, error) {
+	key := strings.Join([]string{MissedSlotKeyPrefix, fmt.Sprintf("0x%x_0x%x", epoch, index)}, Separator)
+	slot := &MissedSlot{}
+	if val, err := db.Get(key); err != nil {
+		return nil, err
+	} else if val == nil {
+		return nil, nil
+	} else if err := rlp.DecodeBytes(val, slot); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
+
+// MissedSlotsSize get missed slots size of the epoch
+func (db *LDB) MissedSlotsSize(epoch uint64) (uint64, error) {
+	skey := strings.Join([]string{MissedSlotSizeKeyPrefix, fmt.Sprintf("0x%x", epoch)}, Separator)
+	if val, err := db.Get(skey); err != nil {
+		return 0, err
+	} else if val == nil {
+		return 0, nil
+	} else {
+		return bytestouint64(val), nil
+	}
+}
+
 // SetState set global state info
 func (db *LDB) SetState(gstate *GlobalState) error {
 	key := strings.Join([]string{StateKeyPrefix, hex.EncodeToString(uint64tobytes(gstate.Epoch))}, Separator)
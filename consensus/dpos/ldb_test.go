@@ -58,7 +58,7 @@ func (ldb *levelDB) Delete(key string) error {
 func (ldb *levelDB) Delegate(string, *big.Int) error {
 	return nil
 }
-func (ldb *levelDB) Undelegate(string, *big.Int) (*types.Action, error) {
+func (ldb *levelDB) Undelegate(string, *big.Int, uint64) (*types.Action, error) {
 	return nil, nil
 }
 func (ldb *levelDB) IncAsset2Acct(string, string, *big.Int, uint64) (*types.Action, error) {
@@ -478,3 +478,43 @@ func TestLDBTakeOver(t *testing.T) {
 	}
 
 }
+
+func TestLDBMissedSlot(t *testing.T) {
+	ldb, function := newTestLDB()
+	db, _ := NewLDB(ldb)
+	defer function()
+
+	epoch := uint64(2)
+	if size, err := db.MissedSlotsSize(epoch); err != nil {
+		panic(fmt.Errorf("MissedSlotsSize --- %v", err))
+	} else if size != 0 {
+		panic(fmt.Errorf("MissedSlotsSize mismatch"))
+	}
+	for index := 0; index < 3; index++ {
+		slot := &MissedSlot{
+			Epoch:     epoch,
+			Timestamp: uint64(index * 3),
+			Candidate: fmt.Sprintf("candidate%v", index),
+			Producer:  "producer",
+			Number:    uint64(index + 10),
+		}
+		if err := db.AddMissedSlot(slot); err != nil {
+			panic(fmt.Errorf("AddMissedSlot --- %v", err))
+		}
+		if nslot, err := db.GetMissedSlot(epoch, uint64(index)); err != nil {
+			panic(fmt.Errorf("GetMissedSlot --- %v", err))
+		} else if !reflect.DeepEqual(slot, nslot) {
+			panic(fmt.Errorf("GetMissedSlot mismatch"))
+		}
+		if size, err := db.MissedSlotsSize(epoch); err != nil {
+			panic(fmt.Errorf("MissedSlotsSize --- %v", err))
+		} else if size != uint64(index+1) {
+			panic(fmt.Errorf("MissedSlotsSize mismatch"))
+		}
+	}
+	if slot, err := db.GetMissedSlot(epoch+1, 0); err != nil {
+		panic(fmt.Errorf("Nil GetMissedSlot --- %v", err))
+	} else if slot != nil {
+		panic(fmt.Errorf("Nil GetMissedSlot mismatch"))
+	}
+}
@@ -32,12 +32,14 @@ import (
 
 // RegisterCandidate candidate info
 type RegisterCandidate struct {
-	Info string
+	Info       string
+	Commission []uint64 `rlp:"tail"` // optional commission rate in percent, since ForkID5
 }
 
 // UpdateCandidate candidate info
 type UpdateCandidate struct {
-	Info string
+	Info       string
+	Commission []uint64 `rlp:"tail"` // optional commission rate in percent, since ForkID5
 }
 
 // UpdateCandidatePubKey candidate info
@@ -61,6 +63,18 @@ type RemoveKickedCandidate struct {
 	Candidates []string
 }
 
+// ClaimVoterReward claim the block rewards shared by the candidate to the voters of the epoch
+type ClaimVoterReward struct {
+	Epoch     uint64
+	Candidate string
+}
+
+// ReportDoubleSign double sign evidence, two different headers signed by the same candidate for the same slot
+type ReportDoubleSign struct {
+	HeaderA *types.Header
+	HeaderB *types.Header
+}
+
 // ProcessAction exec action
 func (dpos *Dpos) ProcessAction(fid uint64, number uint64, chainCfg *params.ChainConfig, state *state.StateDB, action *types.Action) ([]*types.InternalAction, error) {
 	snap := state.Snapshot()
@@ -82,7 +96,7 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 		if err != nil {
 			return nil, err
 		}
-		if err := accountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value()); err != nil {
+		if err := accountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), number); err != nil {
 			return nil, err
 		}
 	}
@@ -104,6 +118,9 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 		if err := sys.RegCandidate(epoch, action.Sender().String(), arg.Info, action.Value(), number, fid); err != nil {
 			return nil, err
 		}
+		if err := sys.setCommission(epoch, action.Sender().String(), arg.Commission, fid); err != nil {
+			return nil, err
+		}
 	case types.UpdateCandidate:
 		if fid >= params.ForkID2 {
 			if action.Value().Sign() == 1 {
@@ -117,6 +134,9 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 		if err := sys.UpdateCandidate(epoch, action.Sender().String(), arg.Info, action.Value(), number, fid); err != nil {
 			return nil, err
 		}
+		if err := sys.setCommission(epoch, action.Sender().String(), arg.Commission, fid); err != nil {
+			return nil, err
+		}
 	case types.UpdateCandidatePubKey:
 		if fid >= params.ForkID4 {
 			arg := &UpdateCandidatePubKey{}
@@ -127,6 +147,14 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 			if err != nil {
 				return nil, err
 			}
+			if fid >= params.ForkID5 {
+				if candidate == nil {
+					return nil, fmt.Errorf("invalid candidate %v(not exist)", action.Sender())
+				}
+				if err := sys.RecordCandidatePubKey(epoch, candidate); err != nil {
+					return nil, err
+				}
+			}
 			candidate.PubKey.SetBytes(arg.PubKey.Bytes())
 
 			err = sys.SetCandidate(candidate)
@@ -188,6 +216,22 @@ func (dpos *Dpos) processAction(fid uint64, number uint64, chainCfg *params.Chai
 			}
 		}
 
+	case types.ClaimVoterReward:
+		arg := &ClaimVoterReward{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if err := sys.ClaimVoterReward(arg.Epoch, action.Sender().String(), arg.Candidate, number, fid); err != nil {
+			return nil, err
+		}
+	case types.ReportDoubleSign:
+		arg := &ReportDoubleSign{}
+		if err := rlp.DecodeBytes(action.Data(), &arg); err != nil {
+			return nil, err
+		}
+		if err := sys.SlashDoubleSign(epoch, action.Sender().String(), arg.HeaderA, arg.HeaderB, chainCfg.ChainID.Bytes(), number, fid); err != nil {
+			return nil, err
+		}
 	case types.ExitTakeOver:
 		gstate, _ := sys.GetState(epoch)
 		if gstate.TakeOver == false || strings.Compare(action.Sender().String(), dpos.config.SystemName) != 0 {
@@ -0,0 +1,87 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package dpos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestProcessActionVestedStake(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, candidate := common.Name("dposvestfrom"), common.Name("dposvestcand")
+	pubkey := new(common.PubKey)
+	pubkey.SetBytes([]byte("dposvesttest123456"))
+	for _, name := range []common.Name{from, candidate, common.Name(DefaultConfig.AccountName)} {
+		if err := am.CreateAccount(common.Name("fractal.founder"), name, common.Name(""), 0, 0, *pubkey, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	supply := new(big.Int).Mul(big10, minStakeCandidate)
+	assetID, err := asset.NewAsset(statedb).IssueAsset("dposvestasset", 0, 0, "dva", supply, 0, from, from, supply, common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddAccountBalanceByID(from, assetID, supply); err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := rlp.EncodeToBytes(&accountmanager.VestingTransfer{Cliff: 100, End: 100})
+	action := types.NewAction(types.VestingTransfer, from, candidate, 0, assetID, 0, minStakeCandidate, payload, nil)
+	if _, err := am.Process(&types.AccountManagerContext{Action: action, Number: 10, CurForkID: params.ForkID5, ChainConfig: params.DefaultChainconfig}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := *DefaultConfig
+	cfg.AssetID = assetID
+	chainCfg := *params.DefaultChainconfig
+	chainCfg.DposName = cfg.AccountName
+	sys := NewSystem(statedb, &cfg)
+	if err := sys.SetState(&GlobalState{TotalQuantity: big.NewInt(0), Dpos: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.SetAvailableQuantity(0, candidate.String(), DefaultConfig.CandidateMinQuantity); err != nil {
+		t.Fatal(err)
+	}
+	dpos := New(&cfg, nil)
+	payload, _ = rlp.EncodeToBytes(&RegisterCandidate{Info: "www.dposvest.com"})
+	reg := types.NewAction(types.RegCandidate, candidate, common.Name(cfg.AccountName), 0, assetID, 0, minStakeCandidate, payload, nil)
+	if _, err := dpos.ProcessAction(params.ForkID5, 50, &chainCfg, statedb, reg); err != accountmanager.ErrInsufficientBalance {
+		t.Fatalf("stake locked balance err = %v, want %v", err, accountmanager.ErrInsufficientBalance)
+	}
+	if _, err := dpos.ProcessAction(params.ForkID5, 100, &chainCfg, statedb, reg); err != nil {
+		t.Fatalf("stake vested balance: %v", err)
+	}
+	if prod, err := sys.GetCandidate(0, candidate.String()); err != nil || prod == nil {
+		t.Fatalf("candidate not registered: %v", err)
+	}
+}
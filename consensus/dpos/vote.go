@@ -17,6 +17,7 @@
 package dpos
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"math/big"
@@ -175,7 +176,7 @@ func (sys *System) UpdateCandidate(epoch uint64, candidate string, info string,
 
 	// db
 	// stake := new(big.Int).Mul(prod.Quantity, sys.config.unitStake())
-	// action, err := sys.Undelegate(candidate, stake)
+	// action, err := sys.Undelegate(candidate, stake, number)
 	// if err != nil {
 	// 	return fmt.Errorf("undelegate %v failed(%v)", q, err)
 	// }
@@ -228,7 +229,7 @@ func (sys *System) UnregCandidate(epoch uint64, candidate string, number uint64,
 	prod.Number = number
 
 	// stake := new(big.Int).Mul(prod.Quantity, sys.config.unitStake())
-	// action, err := sys.Undelegate(candidate, stake)
+	// action, err := sys.Undelegate(candidate, stake, number)
 	// if err != nil {
 	// 	return fmt.Errorf("undelegate %v failed(%v)", stake, err)
 	// }
@@ -318,7 +319,7 @@ func (sys *System) RefundCandidate(epoch uint64, candidate string, number uint64
 
 	// db
 	stake := new(big.Int).Mul(prod.Quantity, sys.config.unitStake())
-	action, err := sys.Undelegate(candidate, stake)
+	action, err := sys.Undelegate(candidate, stake, number)
 	if err != nil {
 		return fmt.Errorf("undelegate %v failed(%v)", stake, err)
 	}
@@ -453,7 +454,7 @@ func (sys *System) KickedCandidate(epoch uint64, candidate string, number uint64
 
 	// db
 	stake := new(big.Int).Mul(prod.Quantity, sys.config.unitStake())
-	action, err := sys.Undelegate(sys.config.SystemName, stake)
+	action, err := sys.Undelegate(sys.config.SystemName, stake, number)
 	if err != nil {
 		return fmt.Errorf("undelegate %v failed(%v)", stake, err)
 	}
@@ -477,6 +478,15 @@ func (sys *System) KickedCandidate(epoch uint64, candidate string, number uint64
 	// 	}
 	// }
 
+	if err := sys.kickCandidate(epoch, prod, number, fid); err != nil {
+		return err
+	}
+	return sys.SetCandidate(prod)
+}
+
+// kickCandidate removes the candidate from the total quantity and the
+// activated schedule and marks it kicked at the number.
+func (sys *System) kickCandidate(epoch uint64, prod *CandidateInfo, number uint64, fid uint64) error {
 	if !prod.invalid() {
 		gstate, err := sys.GetState(epoch)
 		if err != nil {
@@ -496,7 +506,7 @@ func (sys *System) KickedCandidate(epoch uint64, candidate string, number uint64
 
 	prod.Number = number
 	prod.Type = Black
-	return sys.SetCandidate(prod)
+	return nil
 }
 
 // RemoveKickedCandidate remove
@@ -535,6 +545,268 @@ func (sys *System) ExitTakeOver(epoch uint64, number uint64, fid uint64) error {
 	return sys.SetState(gstate)
 }
 
+// canMineAt checks the pubkey could sign blocks of the candidate in the epoch,
+// either the current pubkey or one replaced since the epoch
+func (sys *System) canMineAt(candidate string, pubkey []byte, epoch uint64) error {
+	err := sys.CanMine(candidate, pubkey)
+	if err == nil {
+		return nil
+	}
+	records, rerr := sys.GetCandidatePubKeyRecords(candidate)
+	if rerr != nil {
+		return rerr
+	}
+	for _, record := range records {
+		if record.Epoch < epoch {
+			continue
+		}
+		if record.PubKey.Compare(common.EmptyPubKey) == 0 {
+			if sys.IsValidSign(candidate, pubkey) == nil {
+				return nil
+			}
+		} else if bytes.Equal(record.PubKey.Bytes(), pubkey) {
+			return nil
+		}
+	}
+	return err
+}
+
+// RecordCandidatePubKey keeps the pubkey of the candidate replaced in the
+// epoch, so that double signs with it can still be reported
+func (sys *System) RecordCandidatePubKey(epoch uint64, prod *CandidateInfo) error {
+	records, err := sys.GetCandidatePubKeyRecords(prod.Name)
+	if err != nil {
+		return err
+	}
+	kept := make([]*CandidatePubKeyRecord, 0, len(records)+1)
+	for _, record := range records {
+		if record.Epoch+DoubleSignEvidenceEpochs >= epoch {
+			kept = append(kept, record)
+		}
+	}
+	kept = append(kept, &CandidatePubKeyRecord{Epoch: epoch, PubKey: prod.PubKey})
+	return sys.SetCandidatePubKeyRecords(prod.Name, kept)
+}
+
+// SlashDoubleSign slash a candidate signed two different headers for the same slot
+func (sys *System) SlashDoubleSign(epoch uint64, reporter string, headerA *types.Header, headerB *types.Header, chainID []byte, number uint64, fid uint64) error {
+	if headerA == nil || headerB == nil {
+		return fmt.Errorf("invalid double sign evidence(missing header)")
+	}
+	candidate := headerA.Coinbase.String()
+	if headerB.Coinbase.String() != candidate {
+		return fmt.Errorf("invalid double sign evidence(different candidate %v %v)", candidate, headerB.Coinbase)
+	}
+	timestamp := sys.config.slot(headerA.Time.Uint64())
+	if sys.config.slot(headerB.Time.Uint64()) != timestamp {
+		return fmt.Errorf("invalid double sign evidence(different slot %v %v)", headerA.Time, headerB.Time)
+	}
+	if timestamp < sys.config.ReferenceTime {
+		return fmt.Errorf("invalid double sign evidence(slot %v before reference time)", timestamp)
+	}
+	slotEpoch := sys.config.epoch(timestamp)
+	if slotEpoch > epoch || epoch-slotEpoch > DoubleSignEvidenceEpochs {
+		return fmt.Errorf("invalid double sign evidence(slot epoch %v out of %v epochs before %v)", slotEpoch, DoubleSignEvidenceEpochs, epoch)
+	}
+	hashA, hashB := headerA.Hash(), headerB.Hash()
+	if hashA == hashB {
+		return fmt.Errorf("invalid double sign evidence(same header %v)", hashA.Hex())
+	}
+	for _, header := range []*types.Header{headerA, headerB} {
+		pubkey, err := ecrecover(header, chainID)
+		if err != nil {
+			return err
+		}
+		if err := sys.canMineAt(candidate, pubkey, slotEpoch); err != nil {
+			return fmt.Errorf("invalid double sign evidence(%v)", err)
+		}
+	}
+
+	// name validity
+	prod, err := sys.GetCandidate(epoch, candidate)
+	if err != nil {
+		return err
+	}
+	if prod == nil {
+		return fmt.Errorf("invalid candidate %v(not exist)", candidate)
+	}
+	if prod.Type == Black {
+		return fmt.Errorf("invalid candidate %v(already kicked)", candidate)
+	}
+	records, err := sys.GetDoubleSignRecords(candidate)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.Timestamp == timestamp {
+			return fmt.Errorf("double sign of %v at %v already slashed", candidate, timestamp)
+		}
+	}
+
+	// slash
+	stake := new(big.Int).Mul(prod.Quantity, sys.config.unitStake())
+	slashed := new(big.Int).Div(new(big.Int).Mul(stake, big.NewInt(DoubleSignSlashRate)), big.NewInt(100))
+	reward := new(big.Int).Div(new(big.Int).Mul(slashed, big.NewInt(DoubleSignRewardRate)), big.NewInt(100))
+	record := &DoubleSignRecord{
+		Candidate: candidate,
+		Reporter:  reporter,
+		Epoch:     epoch,
+		Timestamp: timestamp,
+		HashA:     hashA,
+		HashB:     hashB,
+		Slashed:   slashed,
+		Reward:    reward,
+		Refund:    new(big.Int).Sub(stake, slashed),
+		Number:    number,
+	}
+	for _, transfer := range []struct {
+		to     string
+		amount *big.Int
+	}{
+		{reporter, reward},
+		{sys.config.SystemName, new(big.Int).Sub(slashed, reward)},
+		{candidate, record.Refund},
+	} {
+		if transfer.amount.Sign() == 0 {
+			continue
+		}
+		action, err := sys.Undelegate(transfer.to, transfer.amount, number)
+		if err != nil {
+			return fmt.Errorf("undelegate %v failed(%v)", transfer.amount, err)
+		}
+		sys.internalActions = append(sys.internalActions, &types.InternalAction{
+			Action: action.NewRPCAction(0),
+		})
+	}
+	if err := sys.AddDoubleSignRecord(record); err != nil {
+		return err
+	}
+
+	if err := sys.kickCandidate(epoch, prod, number, fid); err != nil {
+		return err
+	}
+	prod.Quantity = big.NewInt(0)
+	return sys.SetCandidate(prod)
+}
+
+// setCommission sets the commission rate of the candidate from the next epoch on,
+// a candidate without commission rate keeps the whole reward until then.
+func (sys *System) setCommission(epoch uint64, candidate string, commission []uint64, fid uint64) error {
+	if len(commission) == 0 {
+		return nil
+	}
+	if fid < params.ForkID5 || len(commission) > 1 {
+		return fmt.Errorf("invalid commission %v", commission)
+	}
+	if commission[0] > MaxCommissionRate {
+		return fmt.Errorf("invalid commission %v(max %v)", commission[0], MaxCommissionRate)
+	}
+	rate := uint64(MaxCommissionRate)
+	if prev, err := sys.GetCommission(candidate); err != nil {
+		return err
+	} else if prev != nil {
+		rate = prev.RateAt(epoch)
+	}
+	return sys.SetCommission(candidate, &Commission{Rate: rate, Next: commission[0], Epoch: epoch + 1})
+}
+
+// ShareBlockReward issue the block reward, the candidate keeps its commission of
+// the current epoch and the rest is shared to the voters which elected the candidate in the previous epoch.
+// Like before, failed issues are ignored, the pool only grows by the issued rewards.
+func (sys *System) ShareBlockReward(candidate string, reward *big.Int, fid uint64) error {
+	commission := reward
+	if strings.Compare(candidate, sys.config.SystemName) != 0 {
+		rates, err := sys.GetCommission(candidate)
+		if err != nil {
+			return err
+		}
+		epoch, err := sys.GetLastestEpoch()
+		if err != nil {
+			return err
+		}
+		if rates != nil && rates.RateAt(epoch) < MaxCommissionRate {
+			rate := rates.RateAt(epoch)
+			gstate, err := sys.GetState(epoch)
+			if err != nil {
+				return err
+			}
+			prod, err := sys.GetCandidate(gstate.PreEpoch, candidate)
+			if err != nil {
+				return err
+			}
+			if prod != nil && prod.TotalQuantity.Cmp(prod.Quantity) > 0 {
+				commission = new(big.Int).Div(new(big.Int).Mul(reward, new(big.Int).SetUint64(rate)), big.NewInt(MaxCommissionRate))
+				shared := new(big.Int).Sub(reward, commission)
+				if _, err := sys.IncAsset2Acct(sys.config.SystemName, sys.config.AccountName, shared, fid); err == nil {
+					pool, err := sys.GetVoterRewardPool(gstate.PreEpoch, candidate)
+					if err != nil {
+						return err
+					}
+					if err := sys.SetVoterRewardPool(gstate.PreEpoch, candidate, new(big.Int).Add(pool, shared)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	if commission.Sign() > 0 {
+		sys.IncAsset2Acct(sys.config.SystemName, candidate, commission, fid)
+	}
+	return nil
+}
+
+// GetVoterReward get the rewards shared to the voter of the candidate in the epoch
+func (sys *System) GetVoterReward(epoch uint64, voter string, candidate string) (*VoterReward, error) {
+	reward := &VoterReward{
+		Epoch:     epoch,
+		Candidate: candidate,
+		Quantity:  big.NewInt(0),
+		Reward:    big.NewInt(0),
+	}
+	voterInfo, err := sys.GetVoter(epoch, voter, candidate)
+	if err != nil {
+		return nil, err
+	}
+	prod, err := sys.GetCandidate(epoch, candidate)
+	if err != nil {
+		return nil, err
+	}
+	if voterInfo != nil && prod != nil {
+		reward.Quantity = voterInfo.Quantity
+		if votes := new(big.Int).Sub(prod.TotalQuantity, prod.Quantity); votes.Sign() > 0 {
+			pool, err := sys.GetVoterRewardPool(epoch, candidate)
+			if err != nil {
+				return nil, err
+			}
+			reward.Reward = new(big.Int).Div(new(big.Int).Mul(pool, voterInfo.Quantity), votes)
+		}
+	}
+	if reward.Claimed, err = sys.GetVoterRewardClaimed(epoch, candidate, voter); err != nil {
+		return nil, err
+	}
+	reward.Pending = new(big.Int).Sub(reward.Reward, reward.Claimed)
+	return reward, nil
+}
+
+// ClaimVoterReward pay the pending rewards shared to the voter of the candidate in the epoch
+func (sys *System) ClaimVoterReward(epoch uint64, voter string, candidate string, number uint64, fid uint64) error {
+	reward, err := sys.GetVoterReward(epoch, voter, candidate)
+	if err != nil {
+		return err
+	}
+	if reward.Pending.Sign() <= 0 {
+		return fmt.Errorf("no pending reward of %v for %v in epoch %v", voter, candidate, epoch)
+	}
+	action, err := sys.Undelegate(voter, reward.Pending, number)
+	if err != nil {
+		return fmt.Errorf("undelegate %v failed(%v)", reward.Pending, err)
+	}
+	sys.internalActions = append(sys.internalActions, &types.InternalAction{
+		Action: action.NewRPCAction(0),
+	})
+	return sys.SetVoterRewardClaimed(epoch, candidate, voter, reward.Reward)
+}
+
 // UpdateElectedCandidates0 update
 func (sys *System) UpdateElectedCandidates0(pepoch uint64, epoch uint64, number uint64, miner string) error {
 	if pepoch > epoch {
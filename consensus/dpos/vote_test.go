@@ -18,9 +18,15 @@ package dpos
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"strings"
 	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
 )
 
 var (
@@ -284,3 +290,215 @@ func TestCandidateVote(t *testing.T) {
 		}
 	}
 }
+
+type undelegateDB struct {
+	*levelDB
+	undelegated map[string]*big.Int
+	issued      map[string]*big.Int
+}
+
+func (db *undelegateDB) Undelegate(to string, amount *big.Int, number uint64) (*types.Action, error) {
+	db.undelegated[to] = amount
+	return types.NewAction(types.Transfer, common.StrToName(DefaultConfig.AccountName), common.StrToName(to), 0, DefaultConfig.AssetID, 0, amount, nil, nil), nil
+}
+
+func (db *undelegateDB) IncAsset2Acct(from string, to string, amount *big.Int, forkID uint64) (*types.Action, error) {
+	db.issued[to] = amount
+	return types.NewAction(types.IncreaseAsset, common.StrToName(DefaultConfig.AccountName), common.StrToName(to), 0, DefaultConfig.AssetID, 0, amount, nil, nil), nil
+}
+
+func TestSlashDoubleSign(t *testing.T) {
+	tldb, function := newTestLDB()
+	defer function()
+	udb := &undelegateDB{levelDB: tldb, undelegated: map[string]*big.Int{}, issued: map[string]*big.Int{}}
+	db, _ := NewLDB(udb)
+	sys := &System{
+		config: DefaultConfig,
+		IDB:    db,
+	}
+	candidate, reporter, chainID := candidates[0], "reporter", big1.Bytes()
+	for _, epoch := range []uint64{1, math.MaxUint64} {
+		if err := db.SetState(&GlobalState{Epoch: epoch, PreEpoch: 1, TotalQuantity: big.NewInt(0), ActivatedTotalQuantity: big.NewInt(0)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sys.RegCandidate(1, candidate, "www.candidate.com", minStakeCandidate, 1, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	prod, _ := sys.GetCandidate(1, candidate)
+	prod.PubKey = common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))
+	if err := sys.SetCandidate(prod); err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(number int64, timestamp uint64) *types.Header {
+		header := &types.Header{
+			Coinbase: common.StrToName(candidate),
+			Number:   big.NewInt(number),
+			Time:     new(big.Int).SetUint64(timestamp),
+			Extra:    make([]byte, extraSeal),
+		}
+		sig, err := crypto.Sign(signHash(header, chainID).Bytes(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		copy(header.Extra, sig)
+		return header
+	}
+	timestamp := DefaultConfig.ReferenceTime + 10*DefaultConfig.blockInterval()
+	headerA := sign(10, timestamp)
+	if err := sys.SlashDoubleSign(1, reporter, headerA, headerA, chainID, 20, params.ForkID5); err == nil || !strings.Contains(err.Error(), "same header") {
+		t.Fatalf("slash with the same header %v", err)
+	}
+	if err := sys.SlashDoubleSign(1, reporter, headerA, sign(11, timestamp+DefaultConfig.blockInterval()), chainID, 20, params.ForkID5); err == nil || !strings.Contains(err.Error(), "different slot") {
+		t.Fatalf("slash with different slots %v", err)
+	}
+	forged := sign(11, timestamp)
+	forged.Number = big.NewInt(12)
+	if err := sys.SlashDoubleSign(1, reporter, headerA, forged, chainID, 20, params.ForkID5); err == nil {
+		t.Fatal("slash with a forged header")
+	}
+
+	if err := sys.SlashDoubleSign(1+DoubleSignEvidenceEpochs+1, reporter, headerA, sign(11, timestamp), chainID, 20, params.ForkID5); err == nil || !strings.Contains(err.Error(), "slot epoch") {
+		t.Fatalf("slash with expired evidence %v", err)
+	}
+
+	// the candidate replaces its pubkey after the double sign
+	headerB := sign(11, timestamp)
+	prod, _ = sys.GetCandidate(1, candidate)
+	if err := sys.RecordCandidatePubKey(1, prod); err != nil {
+		t.Fatal(err)
+	}
+	newKey, _ := crypto.GenerateKey()
+	prod.PubKey = common.BytesToPubKey(crypto.FromECDSAPub(&newKey.PublicKey))
+	if err := sys.SetCandidate(prod); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.canMineAt(candidate, crypto.FromECDSAPub(&key.PublicKey), 1+DoubleSignEvidenceEpochs); err == nil {
+		t.Fatal("replaced pubkey can mine after the replacement epoch")
+	}
+	if err := sys.SlashDoubleSign(1, reporter, headerA, headerB, chainID, 20, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	slashed := new(big.Int).Div(new(big.Int).Mul(minStakeCandidate, big.NewInt(DoubleSignSlashRate)), big.NewInt(100))
+	reward := new(big.Int).Div(new(big.Int).Mul(slashed, big.NewInt(DoubleSignRewardRate)), big.NewInt(100))
+	if udb.undelegated[reporter].Cmp(reward) != 0 ||
+		udb.undelegated[DefaultConfig.SystemName].Cmp(new(big.Int).Sub(slashed, reward)) != 0 ||
+		udb.undelegated[candidate].Cmp(new(big.Int).Sub(minStakeCandidate, slashed)) != 0 {
+		t.Fatalf("undelegated mismatch %v", udb.undelegated)
+	}
+	if prod, _ := sys.GetCandidate(1, candidate); prod.Type != Black || prod.Quantity.Sign() != 0 {
+		t.Fatalf("candidate not kicked %v %v", prod.Type, prod.Quantity)
+	}
+	records, err := sys.GetDoubleSignRecords(candidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Reporter != reporter || records[0].Timestamp != timestamp || records[0].Slashed.Cmp(slashed) != 0 {
+		t.Fatalf("records mismatch %v", records)
+	}
+	if err := sys.SlashDoubleSign(1, reporter, headerA, sign(12, timestamp), chainID, 21, params.ForkID5); err == nil {
+		t.Fatal("slash a kicked candidate")
+	}
+}
+
+func TestVoterReward(t *testing.T) {
+	tldb, function := newTestLDB()
+	defer function()
+	udb := &undelegateDB{levelDB: tldb, undelegated: map[string]*big.Int{}, issued: map[string]*big.Int{}}
+	db, _ := NewLDB(udb)
+	sys := &System{
+		config: DefaultConfig,
+		IDB:    db,
+	}
+	candidate, voterA, voterB := candidates[0], "votera", "voterb"
+	if err := db.SetState(&GlobalState{Epoch: 1, PreEpoch: 1, TotalQuantity: big.NewInt(0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetLastestEpoch(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.RegCandidate(1, candidate, "www.candidate.com", minStakeCandidate, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.setCommission(1, candidate, []uint64{20}, 0); err == nil {
+		t.Fatal("set commission before fork")
+	}
+	if err := sys.setCommission(1, candidate, []uint64{MaxCommissionRate + 1}, params.ForkID5); err == nil {
+		t.Fatal("set commission over max rate")
+	}
+
+	// without commission rate the candidate keeps the whole reward
+	reward := big.NewInt(600)
+	if err := sys.ShareBlockReward(candidate, reward, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if udb.issued[candidate].Cmp(reward) != 0 {
+		t.Fatalf("candidate reward mismatch %v", udb.issued[candidate])
+	}
+
+	if err := sys.setCommission(1, candidate, []uint64{20}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.VoteCandidate(1, voterA, candidate, new(big.Int).Mul(big2, minStakeVote), 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.VoteCandidate(1, voterB, candidate, minStakeVote, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	// the commission rate applies from the next epoch
+	if err := sys.ShareBlockReward(candidate, reward, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if udb.issued[candidate].Int64() != 600 || udb.issued[DefaultConfig.AccountName] != nil {
+		t.Fatalf("reward shared before the commission applies %v", udb.issued)
+	}
+	if err := db.SetState(&GlobalState{Epoch: 2, PreEpoch: 1, TotalQuantity: big.NewInt(0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetLastestEpoch(2); err != nil {
+		t.Fatal(err)
+	}
+	// raising the rate in the epoch doesn't take the share of the voters
+	if err := sys.setCommission(2, candidate, []uint64{MaxCommissionRate}, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.ShareBlockReward(candidate, reward, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if udb.issued[candidate].Int64() != 120 || udb.issued[DefaultConfig.AccountName].Int64() != 480 {
+		t.Fatalf("shared reward mismatch %v", udb.issued)
+	}
+
+	rewardA, err := sys.GetVoterReward(1, voterA, candidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewardA.Reward.Int64() != 320 || rewardA.Pending.Int64() != 320 {
+		t.Fatalf("voter reward mismatch %v %v", rewardA.Reward, rewardA.Pending)
+	}
+	if err := sys.ClaimVoterReward(1, voterA, candidate, 3, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if udb.undelegated[voterA].Int64() != 320 {
+		t.Fatalf("claimed reward mismatch %v", udb.undelegated[voterA])
+	}
+	if err := sys.ClaimVoterReward(1, voterA, candidate, 3, params.ForkID5); err == nil {
+		t.Fatal("claim without pending reward")
+	}
+
+	// rewards keep accruing after a claim
+	if err := sys.ShareBlockReward(candidate, reward, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.ClaimVoterReward(1, voterA, candidate, 4, params.ForkID5); err != nil {
+		t.Fatal(err)
+	}
+	if udb.undelegated[voterA].Int64() != 320 {
+		t.Fatalf("claimed reward mismatch %v", udb.undelegated[voterA])
+	}
+	if rewardB, _ := sys.GetVoterReward(1, voterB, candidate); rewardB.Pending.Int64() != 320 {
+		t.Fatalf("voter reward mismatch %v", rewardB.Pending)
+	}
+}
@@ -17,6 +17,8 @@
 package miner
 
 import (
+	"time"
+
 	"github.com/fractalplatform/fractal/consensus"
 	"github.com/fractalplatform/fractal/rpc"
 )
@@ -52,6 +54,16 @@ func (api *API) SetCoinbase(name string, privKeys []string) error {
 	return api.miner.SetCoinbase(name, privKeys)
 }
 
+// SetCoinbaseFromKeyStore bind miner name & keys of node keystore unlocked by passphrase
+func (api *API) SetCoinbaseFromKeyStore(name string, pubKeys []string, passphrase string) error {
+	return api.miner.SetCoinbaseFromKeyStore(name, pubKeys, passphrase)
+}
+
+// SetCoinbaseFromSigner bind miner name & keys of the external signer at the endpoint
+func (api *API) SetCoinbaseFromSigner(name string, endpoint string) error {
+	return api.miner.SetCoinbaseFromSigner(name, endpoint)
+}
+
 // SetDelay delay broacast block when mint block
 func (api *API) SetDelay(delayDuration uint64) error {
 	return api.miner.SetDelayDuration(delayDuration)
@@ -62,6 +74,28 @@ func (api *API) SetExtra(extra string) error {
 	return api.miner.SetExtra([]byte(extra))
 }
 
+// LeaseAPI serves the producer leases of this node to the standby nodes,
+// it is not in the default http and ws modules.
+type LeaseAPI struct {
+	leases *MemoryLease
+}
+
+// Acquire acquire or renew the lease of the coinbase for ttl (unit:ms), return the lease holder
+func (api *LeaseAPI) Acquire(secret string, coinbase string, holder string, ttl uint64) (string, error) {
+	if err := api.leases.authorize(secret); err != nil {
+		return "", err
+	}
+	return api.leases.Acquire(coinbase, holder, time.Duration(ttl)*time.Millisecond)
+}
+
+// Release release the lease of the coinbase held by the holder
+func (api *LeaseAPI) Release(secret string, coinbase string, holder string) error {
+	if err := api.leases.authorize(secret); err != nil {
+		return err
+	}
+	return api.leases.Release(coinbase, holder)
+}
+
 // APIs provide the miner RPC API.
 func (miner *Miner) APIs(chain consensus.IChainReader) []rpc.API {
 	apis := []rpc.API{
@@ -73,6 +107,11 @@ func (miner *Miner) APIs(chain consensus.IChainReader) []rpc.API {
 				chain: chain,
 			},
 		},
+		{
+			Namespace: "lease",
+			Version:   "1.0",
+			Service:   &LeaseAPI{leases: miner.leases},
+		},
 	}
 	apis = append(apis, miner.worker.Engine().APIs(chain)...)
 	return apis
@@ -0,0 +1,257 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+const (
+	// leaseLockRetries retries to enter the lease file lock
+	leaseLockRetries = 100
+	// leaseLockStale age of a lease file lock left by a crashed node
+	leaseLockStale = 10 * time.Second
+	// leaseRemoteTimeout timeout of a request to the lease server
+	leaseRemoteTimeout = time.Second
+)
+
+// Lease is a lock with expiry per coinbase shared by the producer nodes of
+// one candidate, only the holder of the lease of the coinbase seals blocks.
+type Lease interface {
+	// Acquire acquires or renews the lease of the coinbase for the holder for at least ttl, returns the holder of the lease.
+	Acquire(coinbase string, holder string, ttl time.Duration) (string, error)
+	// Release releases the lease of the coinbase if it is held by the holder.
+	Release(coinbase string, holder string) error
+}
+
+// leaseState holder and expiry (unix nano) of a lease
+type leaseState struct {
+	Holder  string `json:"holder"`
+	Expires int64  `json:"expires"`
+}
+
+// acquire grants the lease to the holder if it is free, expired or already held by it,
+// the expiry of a lease already held by the holder is never shortened.
+func (state *leaseState) acquire(holder string, ttl time.Duration, now time.Time) bool {
+	if state.Holder != "" && state.Holder != holder && state.Expires > now.UnixNano() {
+		return false
+	}
+	if expires := now.Add(ttl).UnixNano(); state.Holder != holder || expires > state.Expires {
+		state.Expires = expires
+	}
+	state.Holder = holder
+	return true
+}
+
+// release frees the lease if it is held by the holder.
+func (state *leaseState) release(holder string) bool {
+	if state.Holder != holder {
+		return false
+	}
+	state.Holder = ""
+	state.Expires = 0
+	return true
+}
+
+// MemoryLease is a lease in memory, served to other nodes by lease_acquire.
+type MemoryLease struct {
+	mu     sync.Mutex
+	states map[string]*leaseState
+	secret string
+}
+
+// NewMemoryLease creates free leases.
+func NewMemoryLease() *MemoryLease {
+	return &MemoryLease{states: make(map[string]*leaseState)}
+}
+
+// Acquire acquires or renews the lease of the coinbase for the holder.
+func (lease *MemoryLease) Acquire(coinbase string, holder string, ttl time.Duration) (string, error) {
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+	state, ok := lease.states[coinbase]
+	if !ok {
+		state = &leaseState{}
+		lease.states[coinbase] = state
+	}
+	state.acquire(holder, ttl, time.Now())
+	return state.Holder, nil
+}
+
+// Release releases the lease of the coinbase held by the holder.
+func (lease *MemoryLease) Release(coinbase string, holder string) error {
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+	if state, ok := lease.states[coinbase]; ok && state.release(holder) {
+		delete(lease.states, coinbase)
+	}
+	return nil
+}
+
+// setSecret sets the secret the other nodes must present, the leases are not
+// served while it is empty.
+func (lease *MemoryLease) setSecret(secret string) {
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+	lease.secret = secret
+}
+
+// authorize checks the secret presented by a node.
+func (lease *MemoryLease) authorize(secret string) error {
+	lease.mu.Lock()
+	defer lease.mu.Unlock()
+	if lease.secret == "" {
+		return errors.New("lease server disabled")
+	}
+	if subtle.ConstantTimeCompare([]byte(lease.secret), []byte(secret)) != 1 {
+		return errors.New("invalid lease secret")
+	}
+	return nil
+}
+
+// FileLease is the leases of the coinbases kept in a file shared by the nodes.
+type FileLease struct {
+	path string
+}
+
+// NewFileLease creates a lease kept in the file.
+func NewFileLease(path string) *FileLease {
+	return &FileLease{path: path}
+}
+
+// Acquire acquires or renews the lease of the coinbase for the holder.
+func (lease *FileLease) Acquire(coinbase string, holder string, ttl time.Duration) (string, error) {
+	var current string
+	err := lease.update(func(states map[string]*leaseState) bool {
+		state, ok := states[coinbase]
+		if !ok {
+			state = &leaseState{}
+			states[coinbase] = state
+		}
+		ok = state.acquire(holder, ttl, time.Now())
+		current = state.Holder
+		return ok
+	})
+	return current, err
+}
+
+// Release releases the lease of the coinbase held by the holder.
+func (lease *FileLease) Release(coinbase string, holder string) error {
+	return lease.update(func(states map[string]*leaseState) bool {
+		state, ok := states[coinbase]
+		if !ok || !state.release(holder) {
+			return false
+		}
+		delete(states, coinbase)
+		return true
+	})
+}
+
+// update applies fn to the states in the file under the lock file, the states
+// are written back when fn returns true.
+func (lease *FileLease) update(fn func(states map[string]*leaseState) bool) error {
+	lock := lease.path + ".lock"
+	for i := 0; ; i++ {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > leaseLockStale {
+			os.Remove(lock)
+			continue
+		}
+		if i >= leaseLockRetries {
+			return fmt.Errorf("lease file %v is locked", lease.path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer os.Remove(lock)
+
+	states := make(map[string]*leaseState)
+	if bts, err := ioutil.ReadFile(lease.path); err == nil {
+		if err := json.Unmarshal(bts, &states); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if !fn(states) {
+		return nil
+	}
+	bts, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(lease.path), "."+filepath.Base(lease.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	return os.Rename(tmp.Name(), lease.path)
+}
+
+// RemoteLease is the leases served by another node over IPC or HTTP.
+type RemoteLease struct {
+	client *rpc.Client
+	secret string
+}
+
+// NewRemoteLease connects to the lease server at the IPC path or HTTP url,
+// the secret is shared with the lease server.
+func NewRemoteLease(endpoint string, secret string) (*RemoteLease, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteLease{client: client, secret: secret}, nil
+}
+
+// Acquire acquires or renews the lease of the coinbase for the holder.
+func (lease *RemoteLease) Acquire(coinbase string, holder string, ttl time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), leaseRemoteTimeout)
+	defer cancel()
+	var current string
+	err := lease.client.CallContext(ctx, &current, "lease_acquire", lease.secret, coinbase, holder, uint64(ttl/time.Millisecond))
+	return current, err
+}
+
+// Release releases the lease of the coinbase held by the holder.
+func (lease *RemoteLease) Release(coinbase string, holder string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), leaseRemoteTimeout)
+	defer cancel()
+	return lease.client.CallContext(ctx, nil, "lease_release", lease.secret, coinbase, holder)
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLeaseStateAcquireRelease(t *testing.T) {
+	now := time.Unix(1000, 0)
+	state := &leaseState{}
+
+	if !state.acquire("primary", 3*time.Second, now) {
+		t.Fatal("free lease not granted")
+	}
+	if state.acquire("standby", 3*time.Second, now.Add(time.Second)) {
+		t.Fatal("held lease granted to standby")
+	}
+	if !state.acquire("primary", time.Second, now.Add(time.Second)) {
+		t.Fatal("held lease not renewed")
+	}
+	if state.Expires != now.Add(3*time.Second).UnixNano() {
+		t.Fatalf("lease expiry shortened to %v", state.Expires)
+	}
+	if !state.acquire("primary", 5*time.Second, now.Add(time.Second)) || state.Expires != now.Add(6*time.Second).UnixNano() {
+		t.Fatalf("lease expiry %v not extended", state.Expires)
+	}
+
+	if state.release("standby") {
+		t.Fatal("lease released by standby")
+	}
+	if !state.release("primary") || state.Holder != "" {
+		t.Fatal("lease not released by primary")
+	}
+	if !state.acquire("standby", time.Second, now.Add(time.Second)) {
+		t.Fatal("released lease not granted")
+	}
+}
+
+func TestLeaseExpiryTakeover(t *testing.T) {
+	now := time.Unix(1000, 0)
+	state := &leaseState{}
+	if !state.acquire("primary", 3*time.Second, now) {
+		t.Fatal("free lease not granted")
+	}
+	if state.acquire("standby", time.Second, now.Add(3*time.Second-1)) {
+		t.Fatal("lease taken over before expiry")
+	}
+	if !state.acquire("standby", time.Second, now.Add(3*time.Second)) || state.Holder != "standby" {
+		t.Fatal("expired lease not taken over")
+	}
+	if state.acquire("primary", 3*time.Second, now.Add(3*time.Second)) {
+		t.Fatal("lease taken back by primary")
+	}
+
+	lease := NewMemoryLease()
+	if holder, err := lease.Acquire("miner", "primary", 20*time.Millisecond); err != nil || holder != "primary" {
+		t.Fatalf("acquire holder %v err %v", holder, err)
+	}
+	if holder, _ := lease.Acquire("miner", "standby", time.Second); holder != "primary" {
+		t.Fatalf("lease held by %v before expiry", holder)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if holder, _ := lease.Acquire("miner", "standby", time.Second); holder != "standby" {
+		t.Fatalf("lease held by %v after expiry", holder)
+	}
+}
+
+func TestLeasePerCoinbase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, lease := range []Lease{NewMemoryLease(), NewFileLease(filepath.Join(dir, "lease.json"))} {
+		if holder, err := lease.Acquire("miner0", "primary", time.Minute); err != nil || holder != "primary" {
+			t.Fatalf("acquire holder %v err %v", holder, err)
+		}
+		if holder, err := lease.Acquire("miner1", "other", time.Minute); err != nil || holder != "other" {
+			t.Fatalf("lease of another coinbase held by %v err %v", holder, err)
+		}
+		if err := lease.Release("miner1", "primary"); err != nil {
+			t.Fatal(err)
+		}
+		if holder, _ := lease.Acquire("miner1", "primary", time.Minute); holder != "other" {
+			t.Fatalf("lease of another coinbase released, held by %v", holder)
+		}
+	}
+}
+
+func TestLeaseAPISecret(t *testing.T) {
+	leases := NewMemoryLease()
+	api := &LeaseAPI{leases: leases}
+	if _, err := api.Acquire("", "miner", "primary", 1000); err == nil {
+		t.Fatal("lease served without secret")
+	}
+	leases.setSecret("secret")
+	if _, err := api.Acquire("wrong", "miner", "primary", 1000); err == nil {
+		t.Fatal("lease served with a wrong secret")
+	}
+	if holder, err := api.Acquire("secret", "miner", "primary", 1000); err != nil || holder != "primary" {
+		t.Fatalf("acquire holder %v err %v", holder, err)
+	}
+	if err := api.Release("wrong", "miner", "primary"); err == nil {
+		t.Fatal("lease released with a wrong secret")
+	}
+}
+
+func TestFileLease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lease.json")
+
+	primary, standby := NewFileLease(path), NewFileLease(path)
+	if holder, err := primary.Acquire("miner", "primary", time.Minute); err != nil || holder != "primary" {
+		t.Fatalf("acquire holder %v err %v", holder, err)
+	}
+	if holder, err := standby.Acquire("miner", "standby", time.Minute); err != nil || holder != "primary" {
+		t.Fatalf("standby acquire holder %v err %v", holder, err)
+	}
+	if err := standby.Release("miner", "standby"); err != nil {
+		t.Fatal(err)
+	}
+	if holder, _ := standby.Acquire("miner", "standby", time.Minute); holder != "primary" {
+		t.Fatalf("lease released by standby, held by %v", holder)
+	}
+	if err := primary.Release("miner", "primary"); err != nil {
+		t.Fatal(err)
+	}
+	if holder, _ := standby.Acquire("miner", "standby", time.Minute); holder != "standby" {
+		t.Fatalf("released lease held by %v", holder)
+	}
+}
+
+func TestFileLeaseContention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lease.json")
+
+	holders := []string{"node0", "node1", "node2", "node3"}
+	results := make([]string, len(holders))
+	var wg sync.WaitGroup
+	for i, name := range holders {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			holder, err := NewFileLease(path).Acquire("miner", name, time.Minute)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = holder
+		}(i, name)
+	}
+	wg.Wait()
+
+	winners := 0
+	for i, holder := range results {
+		if holder != results[0] {
+			t.Fatalf("holders %v disagree", results)
+		}
+		if holder == holders[i] {
+			winners++
+		}
+	}
+	if winners != 1 {
+		t.Fatalf("lease granted to %v nodes", winners)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("lock file left, err %v", err)
+	}
+}
+
+func TestFileLeaseStaleLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lease.json")
+	lock := path + ".lock"
+
+	// a fresh lock is kept by another node
+	if err := ioutil.WriteFile(lock, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileLease(path).Acquire("miner", "primary", time.Minute); err == nil {
+		t.Fatal("acquired under a fresh lock")
+	}
+
+	// a stale lock is left by a crashed node
+	old := time.Now().Add(-2 * leaseLockStale)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if holder, err := NewFileLease(path).Acquire("miner", "primary", time.Minute); err != nil || holder != "primary" {
+		t.Fatalf("acquire holder %v err %v", holder, err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Fatalf("stale lock file left, err %v", err)
+	}
+}
@@ -19,18 +19,28 @@ package miner
 import (
 	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"os"
 	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/consensus"
 	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/keystore"
 	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/signer"
 )
 
 // Miner creates blocks and searches for proof values.
 type Miner struct {
-	worker *Worker
+	worker   *Worker
+	keystore *keystore.KeyStore
+	leases   *MemoryLease // served to the producer nodes using this node as lease server
+
+	protection *signer.Protection // slashing protection of the keys signing in process
+	audit      *signer.AuditLog
 
 	mining      int32 // 0: stoped; 1: starting; 2: started; 3: stopping
 	canStart    int32 // can start indicates whether we can start the mining operation
@@ -41,6 +51,7 @@ type Miner struct {
 func NewMiner(consensus consensus.IConsensus) *Miner {
 	miner := &Miner{
 		worker:   newWorker(consensus),
+		leases:   NewMemoryLease(),
 		canStart: 1,
 	}
 	go miner.update()
@@ -130,10 +141,104 @@ func (miner *Miner) SetCoinbase(name string, privKeys []string) error {
 		privs = append(privs, priv)
 	}
 
-	miner.worker.setCoinbase(name, privs)
+	return miner.worker.setCoinbase(name, signer.NewKeySigner(privs, miner.protection, miner.audit))
+}
+
+// SetSignerProtection keeps the headers signed by the private keys of the
+// coinbase in the protection file and logs the sign requests to the audit
+// log file, as the external signer does. The protection is kept in memory
+// if the file is empty and no audit log is written if its file is empty.
+func (miner *Miner) SetSignerProtection(protection string, auditLog string) error {
+	p, err := signer.NewProtection(protection)
+	if err != nil {
+		return err
+	}
+	var audit *signer.AuditLog
+	if auditLog != "" {
+		if audit, err = signer.NewAuditLog(auditLog); err != nil {
+			return err
+		}
+	}
+	miner.protection, miner.audit = p, audit
+	return nil
+}
+
+// SetKeyStore set the keystore used to unlock coinbase keys
+func (miner *Miner) SetKeyStore(ks *keystore.KeyStore) {
+	miner.keystore = ks
+}
+
+// SetCoinbaseFromKeyStore coinbase name & private keys unlocked from keystore by passphrase
+func (miner *Miner) SetCoinbaseFromKeyStore(name string, pubKeys []string, passphrase string) error {
+	if miner.keystore == nil {
+		return errors.New("keystore not available")
+	}
+	privs := make([]*ecdsa.PrivateKey, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		if !common.IsHexPubKey(pubKey) {
+			return fmt.Errorf("invalid public key %v", pubKey)
+		}
+		key, err := miner.keystore.GetKey(common.HexToPubKey(pubKey), passphrase)
+		if err != nil {
+			return fmt.Errorf("unlock %v failed: %v", pubKey, err)
+		}
+		privs = append(privs, key.PrivateKey)
+	}
+
+	return miner.worker.setCoinbase(name, signer.NewKeySigner(privs, miner.protection, miner.audit))
+}
+
+// SetCoinbaseFromSigner coinbase name & keys of the external signer at the endpoint
+func (miner *Miner) SetCoinbaseFromSigner(name string, endpoint string) error {
+	remote, err := signer.NewRemoteSigner(endpoint)
+	if err != nil {
+		return err
+	}
+	if err := miner.worker.setCoinbase(name, remote); err != nil {
+		remote.Close()
+		return fmt.Errorf("signer %v: %v", endpoint, err)
+	}
+	return nil
+}
+
+// SetLease share the lease with the standby producer nodes of the coinbase,
+// the lease is kept in the file, or served by the node at the endpoint with
+// the secret if the endpoint is not empty. The lease expires after slots
+// without producing a block.
+func (miner *Miner) SetLease(file string, endpoint string, secret string, holder string, slots uint64) error {
+	if slots < 2 {
+		return fmt.Errorf("lease slots %v less than 2", slots)
+	}
+	if holder == "" {
+		hostname, _ := os.Hostname()
+		holder = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	var lease Lease
+	if endpoint != "" {
+		if secret == "" {
+			return errors.New("no lease secret")
+		}
+		remote, err := NewRemoteLease(endpoint, secret)
+		if err != nil {
+			return err
+		}
+		lease = remote
+	} else if file != "" {
+		lease = NewFileLease(file)
+	} else {
+		return errors.New("no lease file or server")
+	}
+	miner.worker.setLease(lease, holder, slots)
+	log.Info("Producer lease enabled", "holder", holder, "slots", slots)
 	return nil
 }
 
+// ServeLease serves the leases of this node to the nodes presenting the
+// secret, the leases are not served if it is empty.
+func (miner *Miner) ServeLease(secret string) {
+	miner.leases.setSecret(secret)
+}
+
 // SetDelayDuration delay broacast block when mint block (unit:ms)
 func (miner *Miner) SetDelayDuration(delayDuration uint64) error {
 	return miner.worker.setDelayDuration(delayDuration)
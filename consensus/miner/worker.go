@@ -17,7 +17,6 @@
 package miner
 
 import (
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math"
@@ -32,11 +31,11 @@ import (
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/consensus"
 	"github.com/fractalplatform/fractal/consensus/dpos"
-	"github.com/fractalplatform/fractal/crypto"
 	"github.com/fractalplatform/fractal/event"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/processor"
 	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/signer"
 	"github.com/fractalplatform/fractal/state"
 	"github.com/fractalplatform/fractal/types"
 )
@@ -55,9 +54,14 @@ type Worker struct {
 	mu            sync.Mutex
 	delayDuration uint64
 	coinbase      string
-	privKeys      []*ecdsa.PrivateKey
+	signer        signer.Signer
 	pubKeys       [][]byte
-	extra         []byte
+
+	lease       Lease
+	leaseHolder string
+	leaseSlots  uint64
+	leaseHeld   bool
+	extra       []byte
 
 	wg        sync.WaitGroup
 	mining    int32
@@ -124,11 +128,11 @@ func (worker *Worker) mintLoop() {
 	if !ok {
 		panic("only support dpos engine")
 	}
-	cdpos.SetSignFn(func(content []byte, state *state.StateDB) ([]byte, error) {
+	cdpos.SetSignFn(func(content []byte, header *types.Header, state *state.StateDB) ([]byte, error) {
 		sys := dpos.NewSystem(state, cdpos.Config())
-		for index, privKey := range worker.privKeys {
-			if err := sys.CanMine(worker.coinbase, worker.pubKeys[index]); err == nil {
-				return crypto.Sign(content, privKey)
+		for _, pubKey := range worker.pubKeys {
+			if err := sys.CanMine(worker.coinbase, pubKey); err == nil {
+				return worker.signer.SignHeader(common.BytesToPubKey(pubKey), header)
 			}
 		}
 		return nil, fmt.Errorf("not found match private key for sign")
@@ -227,6 +231,7 @@ func (worker *Worker) stop() {
 	}
 	close(worker.quit)
 	worker.wg.Wait()
+	worker.releaseLease()
 }
 func (worker *Worker) setDelayDuration(delay uint64) error {
 	worker.mu.Lock()
@@ -235,17 +240,24 @@ func (worker *Worker) setDelayDuration(delay uint64) error {
 	return nil
 }
 
-func (worker *Worker) setCoinbase(name string, privKeys []*ecdsa.PrivateKey) {
+func (worker *Worker) setCoinbase(name string, s signer.Signer) error {
+	pubKeys, err := s.PubKeys()
+	if err != nil {
+		return err
+	}
 	state, _ := worker.StateAt(worker.CurrentHeader().Root)
 	cdpos := worker.Engine().(*dpos.Dpos)
 	sys := dpos.NewSystem(state, cdpos.Config())
 	worker.mu.Lock()
 	defer worker.mu.Unlock()
+	if remote, ok := worker.signer.(*signer.RemoteSigner); ok && remote != s {
+		remote.Close()
+	}
 	worker.coinbase = name
-	worker.privKeys = privKeys
+	worker.signer = s
 	worker.pubKeys = nil
-	for index, privkey := range privKeys {
-		pubkey := crypto.FromECDSAPub(&privkey.PublicKey)
+	for index, key := range pubKeys {
+		pubkey := key.Bytes()
 		if err := sys.CanMine(name, pubkey); err == nil {
 			log.Info("setCoinbase[valid]", "coinbase", name, fmt.Sprintf("pubKey_%03d", index), common.BytesToPubKey(pubkey).String())
 		} else {
@@ -253,6 +265,60 @@ func (worker *Worker) setCoinbase(name string, privKeys []*ecdsa.PrivateKey) {
 		}
 		worker.pubKeys = append(worker.pubKeys, pubkey)
 	}
+	return nil
+}
+
+func (worker *Worker) setLease(lease Lease, holder string, slots uint64) {
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	worker.lease = lease
+	worker.leaseHolder = holder
+	worker.leaseSlots = slots
+	worker.leaseHeld = false
+}
+
+// acquireLease claims the lease for the current slot, or renews it for the lease
+// slots after a block is produced, nil when no lease configured.
+func (worker *Worker) acquireLease(renew bool) error {
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	if worker.lease == nil {
+		return nil
+	}
+	if atomic.LoadInt32(&worker.mining) == 0 {
+		return errors.New("worker stopped")
+	}
+	slots := uint64(1)
+	if renew {
+		slots = worker.leaseSlots
+	}
+	ttl := time.Duration(slots * worker.Engine().(*dpos.Dpos).BlockInterval())
+	holder, err := worker.lease.Acquire(worker.coinbase, worker.leaseHolder, ttl)
+	if err == nil && holder != worker.leaseHolder {
+		err = fmt.Errorf("lease held by %v", holder)
+	}
+	if held := err == nil; held != worker.leaseHeld {
+		if held {
+			log.Info("Acquired producer lease", "holder", worker.leaseHolder, "coinbase", worker.coinbase)
+		} else {
+			log.Warn("Lost producer lease", "holder", worker.leaseHolder, "coinbase", worker.coinbase, "err", err)
+		}
+		worker.leaseHeld = held
+	}
+	return err
+}
+
+// releaseLease releases the lease, so that the standby takes over at once.
+func (worker *Worker) releaseLease() {
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+	if worker.lease == nil || !worker.leaseHeld {
+		return
+	}
+	if err := worker.lease.Release(worker.coinbase, worker.leaseHolder); err != nil {
+		log.Warn("failed to release producer lease", "holder", worker.leaseHolder, "err", err)
+	}
+	worker.leaseHeld = false
 }
 
 func (worker *Worker) setExtra(extra []byte) {
@@ -335,6 +401,10 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 
 		work.currentBlock = blk
 
+		// claim the lease for this slot only, it is renewed once the block is written
+		if err := worker.acquireLease(false); err != nil {
+			return nil, fmt.Errorf("mint block, err: %v", err)
+		}
 		block, err := worker.Seal(worker.IConsensus, work.currentBlock, nil)
 		if err != nil {
 			return nil, fmt.Errorf("seal block, err: %v", err)
@@ -351,6 +421,10 @@ func (worker *Worker) commitNewWork(timestamp int64, parent *types.Header, quit
 		if _, err := worker.WriteBlockWithState(block, work.currentReceipts, work.currentState); err != nil {
 			return nil, fmt.Errorf("writing block to chain, err: %v", err)
 		}
+		// renew only after producing, so that the standby takes over when this node stops producing
+		if err := worker.acquireLease(true); err != nil {
+			log.Warn("failed to renew producer lease", "holder", worker.leaseHolder, "err", err)
+		}
 		time.Sleep(time.Duration(worker.delayDuration * uint64(time.Millisecond)))
 
 		event.SendEvent(&event.Event{Typecode: event.ChainHeadEv, Data: block})
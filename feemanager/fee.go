@@ -299,8 +299,8 @@ func (fm *FeeManager) getObjectFounder(objectName string, objectType uint64) (co
 	return founder, err
 }
 
-//WithdrawFeeFromSystem withdraw object fee in system, return withdraw info
-func (fm *FeeManager) WithdrawFeeFromSystem(objectName string, objectType uint64) (*WithdrawInfo, error) {
+//WithdrawFeeFromSystem withdraw object fee in system at the block number, return withdraw info
+func (fm *FeeManager) WithdrawFeeFromSystem(objectName string, objectType uint64, number uint64) (*WithdrawInfo, error) {
 	//get fee info from system
 	objectFee, err := fm.GetObjectFeeByName(objectName, objectType)
 
@@ -321,7 +321,7 @@ func (fm *FeeManager) WithdrawFeeFromSystem(objectName string, objectType uint64
 	//store fee to object, scan all asset
 	for _, assetFee := range objectFee.AssetFees {
 		if assetFee.RemainFee.Cmp(big.NewInt(0)) > 0 {
-			err = fm.accountDB.TransferAsset(common.Name(feeConfig.feeName), founder, assetFee.AssetID, assetFee.RemainFee)
+			err = fm.accountDB.TransferAsset(common.Name(feeConfig.feeName), founder, assetFee.AssetID, assetFee.RemainFee, number)
 			if err != nil {
 				return nil, fmt.Errorf("withdraw asset(%d) fee to founder(%s) err:%v", assetFee.AssetID, founder, err)
 			}
@@ -213,7 +213,7 @@ func TestWithdrawFeeFromSystem(t *testing.T) {
 	}
 
 	//withdraw fee from system
-	withdrawInfo, err := fm.WithdrawFeeFromSystem(testFeeInfo[0].objectName, testFeeInfo[0].objectType)
+	withdrawInfo, err := fm.WithdrawFeeFromSystem(testFeeInfo[0].objectName, testFeeInfo[0].objectType, 0)
 
 	if err != nil || withdrawInfo == nil {
 		t.Errorf("withdraw fee from system failed, err:%v", err)
@@ -292,7 +292,7 @@ func TestWithdrawFeeFromSystemNotExsit(t *testing.T) {
 	}
 
 	//withdraw fee from system
-	_, err := fm.WithdrawFeeFromSystem(testFeeInfo[0].objectName, testFeeInfo[0].objectType)
+	_, err := fm.WithdrawFeeFromSystem(testFeeInfo[0].objectName, testFeeInfo[0].objectType, 0)
 
 	if err == nil {
 		t.Errorf("withdraw not exsit fee from system case failed")
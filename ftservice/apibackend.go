@@ -18,6 +18,7 @@ package ftservice
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/math"
@@ -27,6 +28,7 @@ import (
 	"github.com/fractalplatform/fractal/consensus"
 	"github.com/fractalplatform/fractal/feemanager"
 	"github.com/fractalplatform/fractal/ftservice/gasprice"
+	"github.com/fractalplatform/fractal/keystore"
 	"github.com/fractalplatform/fractal/p2p/enode"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/processor"
@@ -70,6 +72,10 @@ func (b *APIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.
 	return logs, nil
 }
 
+func (b *APIBackend) KeyStore() *keystore.KeyStore {
+	return b.ftservice.keystore
+}
+
 func (b *APIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	return b.ftservice.txPool.AddLocal(signedTx)
 }
@@ -198,6 +204,18 @@ func (b *APIBackend) GetDetailTxByFilter(ctx context.Context, filterFn func(comm
 	return txdetails
 }
 
+func (b *APIBackend) GetAccountTxEntries(ctx context.Context, name common.Name, cursor []byte, limit int) ([]*rawdb.AccountTxEntry, []byte, error) {
+	return b.ftservice.blockchain.AccountTxEntries(name, cursor, limit)
+}
+
+func (b *APIBackend) AccountTxIndexTail() (uint64, error) {
+	return b.ftservice.blockchain.AccountTxIndexTail()
+}
+
+func (b *APIBackend) BackfillAccountTxIndex() error {
+	return b.ftservice.blockchain.BackfillAccountTxIndex()
+}
+
 func (b *APIBackend) GetBadBlocks(ctx context.Context) ([]*types.Block, error) {
 	return b.ftservice.blockchain.BadBlocks(), nil
 }
@@ -233,6 +251,31 @@ func (b *APIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.Blo
 	return stateDb, header, err
 }
 
+// StateAtBlock returns the state the transactions of the block are executed
+// on, which is the state of the parent block prepared by the consensus engine,
+// and the prepared header.
+func (b *APIBackend) StateAtBlock(ctx context.Context, block *types.Block) (*state.StateDB, *types.Header, error) {
+	bc := b.ftservice.blockchain
+	parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, nil, fmt.Errorf("parent block %#x not found", block.ParentHash())
+	}
+	statedb, err := bc.StateAt(parent.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+	header := block.Header()
+	if err := b.ftservice.engine.Prepare(bc, header, block.Transactions(), nil, statedb); err != nil {
+		return nil, nil, err
+	}
+	return statedb, header, nil
+}
+
+// Processor returns the processor used to apply transactions.
+func (b *APIBackend) Processor() processor.Processor {
+	return b.ftservice.blockchain.Processor()
+}
+
 func (b *APIBackend) GetEVM(ctx context.Context, account *accountmanager.AccountManager, state *state.StateDB, from common.Name, to common.Name, assetID uint64, gasPrice *big.Int, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
 	account.AddAccountBalanceByID(from, assetID, math.MaxBig256)
 	vmError := func() error { return nil }
@@ -46,6 +46,7 @@ type Config struct {
 
 	StatePruning    bool `mapstructure:"statepruning"`
 	ContractLogFlag bool `mapstructure:"contractlog"`
+	AccountTxIndex  bool `mapstructure:"accounttxindex"`
 
 	BadHashes   []string `mapstructure:"badhashes"`
 	StartNumber uint64   `mapstructure:"startnumber"`
@@ -58,4 +59,30 @@ type MinerConfig struct {
 	Name        string   `mapstructure:"name"`
 	PrivateKeys []string `mapstructure:"private"`
 	ExtraData   string   `mapstructure:"extra"`
+
+	// KeyStoreKeys are public keys of the node keystore used instead of
+	// PrivateKeys, they are unlocked with the passphrase in PasswordFile.
+	KeyStoreKeys []string `mapstructure:"keystorekeys"`
+	PasswordFile string   `mapstructure:"passwordfile"`
+
+	// Signer is the IPC path or HTTP url of an external signer used
+	// instead of the keys above.
+	Signer string `mapstructure:"signer"`
+
+	// Protection and AuditLog are the slashing protection file of signed
+	// headers and the audit log file of sign requests of the keys above,
+	// relative to the datadir.
+	Protection string `mapstructure:"protection"`
+	AuditLog   string `mapstructure:"auditlog"`
+
+	// LeaseFile or LeaseServer enables the lease shared with the standby
+	// nodes of the same name, only the lease holder seals blocks and the
+	// lease expires after LeaseSlots slots without being renewed. The lease
+	// server and its nodes share LeaseSecret, a node serves the leases over
+	// the lease rpc module only if it is set.
+	LeaseFile   string `mapstructure:"leasefile"`
+	LeaseServer string `mapstructure:"leaseserver"`
+	LeaseSecret string `mapstructure:"leasesecret"`
+	LeaseHolder string `mapstructure:"leaseholder"`
+	LeaseSlots  uint64 `mapstructure:"leaseslots"`
 }
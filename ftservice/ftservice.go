@@ -17,7 +17,9 @@
 package ftservice
 
 import (
+	"io/ioutil"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/fractalplatform/fractal/blockchain"
@@ -25,6 +27,7 @@ import (
 	"github.com/fractalplatform/fractal/consensus/dpos"
 	"github.com/fractalplatform/fractal/consensus/miner"
 	"github.com/fractalplatform/fractal/ftservice/gasprice"
+	"github.com/fractalplatform/fractal/keystore"
 	"github.com/fractalplatform/fractal/node"
 	"github.com/fractalplatform/fractal/p2p"
 	adaptor "github.com/fractalplatform/fractal/p2p/protoadaptor"
@@ -47,6 +50,7 @@ type FtService struct {
 	chainDb      fdb.Database // Block chain database
 	engine       consensus.IEngine
 	miner        *miner.Miner
+	keystore     *keystore.KeyStore
 	p2pServer    *adaptor.ProtoAdaptor
 	APIBackend   *APIBackend
 }
@@ -72,6 +76,7 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 		chainConfig:  chainCfg,
 		p2pServer:    ctx.P2P,
 		shutdownChan: make(chan bool),
+		keystore:     keystore.NewKeyStore(ctx.KeyStoreDir(), keystore.StandardScryptN, keystore.StandardScryptP),
 	}
 
 	//blockchain
@@ -83,6 +88,10 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 	if err != nil {
 		return nil, err
 	}
+	ftservice.blockchain.SetAccountTxIndex(config.AccountTxIndex)
+	if config.AccountTxIndex && !config.ContractLogFlag {
+		log.Warn("Account tx index without contractlog, internal actions are not indexed")
+	}
 
 	// txpool
 	if config.TxPool.Journal != "" {
@@ -117,8 +126,39 @@ func New(ctx *node.ServiceContext, config *Config) (*FtService, error) {
 	bcc.Processor = txProcessor
 	ftservice.miner = miner.NewMiner(bcc)
 	ftservice.miner.SetDelayDuration(config.Miner.Delay)
-	ftservice.miner.SetCoinbase(config.Miner.Name, config.Miner.PrivateKeys)
+	ftservice.miner.SetKeyStore(ftservice.keystore)
+	protection, auditLog := config.Miner.Protection, config.Miner.AuditLog
+	if protection != "" {
+		protection = ctx.ResolvePath(protection)
+	}
+	if auditLog != "" {
+		auditLog = ctx.ResolvePath(auditLog)
+	}
+	if err := ftservice.miner.SetSignerProtection(protection, auditLog); err != nil {
+		return nil, err
+	}
+	if len(config.Miner.Signer) > 0 {
+		if err := ftservice.miner.SetCoinbaseFromSigner(config.Miner.Name, config.Miner.Signer); err != nil {
+			return nil, err
+		}
+	} else if len(config.Miner.KeyStoreKeys) > 0 {
+		passphrase, err := ioutil.ReadFile(config.Miner.PasswordFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := ftservice.miner.SetCoinbaseFromKeyStore(config.Miner.Name, config.Miner.KeyStoreKeys, strings.TrimRight(string(passphrase), "\r\n")); err != nil {
+			return nil, err
+		}
+	} else {
+		ftservice.miner.SetCoinbase(config.Miner.Name, config.Miner.PrivateKeys)
+	}
 	ftservice.miner.SetExtra([]byte(config.Miner.ExtraData))
+	ftservice.miner.ServeLease(config.Miner.LeaseSecret)
+	if len(config.Miner.LeaseFile) > 0 || len(config.Miner.LeaseServer) > 0 {
+		if err := ftservice.miner.SetLease(config.Miner.LeaseFile, config.Miner.LeaseServer, config.Miner.LeaseSecret, config.Miner.LeaseHolder, config.Miner.LeaseSlots); err != nil {
+			return nil, err
+		}
+	}
 	if config.Miner.Start {
 		ftservice.miner.Start(false)
 	}
@@ -175,4 +215,5 @@ func (s *FtService) BlockChain() *blockchain.BlockChain { return s.blockchain }
 func (s *FtService) TxPool() *txpool.TxPool             { return s.txPool }
 func (s *FtService) Engine() consensus.IEngine          { return s.engine }
 func (s *FtService) ChainDb() fdb.Database              { return s.chainDb }
+func (s *FtService) KeyStore() *keystore.KeyStore       { return s.keystore }
 func (s *FtService) Protocols() []p2p.Protocol          { return nil }
@@ -0,0 +1,251 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package keystore implements encrypted storage of secp256k1 private keys.
+//
+// Keys are stored as one JSON file per key in the key directory. The private
+// key is encrypted with AES-128-CTR using a key derived from the passphrase
+// by scrypt, the integrity of the ciphertext is protected by a keccak256 MAC.
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+var (
+	// ErrDecrypt is returned when the passphrase can't decrypt the key.
+	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+	// ErrNoMatch is returned when no key file matches the public key.
+	ErrNoMatch = errors.New("no key for given public key")
+	// ErrLocked is returned when signing with a key that isn't unlocked.
+	ErrLocked = errors.New("key is locked")
+	// ErrKeyExists is returned when importing a key that is already stored.
+	ErrKeyExists = errors.New("key already exists")
+)
+
+// KeyInfo describes a key file in the key directory.
+type KeyInfo struct {
+	PubKey common.PubKey `json:"publicKey"`
+	Path   string        `json:"path"`
+}
+
+// KeyStore manages a key storage directory on disk.
+type KeyStore struct {
+	keydir  string
+	scryptN int
+	scryptP int
+
+	mu       sync.RWMutex
+	unlocked map[common.PubKey]*Key
+}
+
+// NewKeyStore creates a keystore for the given directory.
+func NewKeyStore(keydir string, scryptN, scryptP int) *KeyStore {
+	keydir, _ = filepath.Abs(keydir)
+	return &KeyStore{
+		keydir:   keydir,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		unlocked: make(map[common.PubKey]*Key),
+	}
+}
+
+// Dir returns the key directory.
+func (ks *KeyStore) Dir() string {
+	return ks.keydir
+}
+
+// NewKey generates a new key and stores it encrypted with the passphrase.
+func (ks *KeyStore) NewKey(passphrase string) (KeyInfo, error) {
+	key, err := newKey()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return ks.storeKey(key, passphrase)
+}
+
+// ImportECDSA stores the given private key encrypted with the passphrase.
+func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (KeyInfo, error) {
+	key := newKeyFromECDSA(priv)
+	if _, err := ks.Find(key.PubKey); err == nil {
+		return KeyInfo{}, ErrKeyExists
+	}
+	return ks.storeKey(key, passphrase)
+}
+
+// Keys returns all keys in the key directory, ordered by file name.
+func (ks *KeyStore) Keys() ([]KeyInfo, error) {
+	files, err := ioutil.ReadDir(ks.keydir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var infos []KeyInfo
+	for _, fi := range files {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || strings.HasSuffix(fi.Name(), "~") {
+			continue
+		}
+		path := filepath.Join(ks.keydir, fi.Name())
+		keyjson, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		k := new(encryptedKeyJSON)
+		if err := json.Unmarshal(keyjson, k); err != nil || k.PubKey == common.EmptyPubKey {
+			continue
+		}
+		infos = append(infos, KeyInfo{PubKey: k.PubKey, Path: path})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos, nil
+}
+
+// Find returns the key file of the public key.
+func (ks *KeyStore) Find(pubKey common.PubKey) (KeyInfo, error) {
+	infos, err := ks.Keys()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	for _, info := range infos {
+		if info.PubKey == pubKey {
+			return info, nil
+		}
+	}
+	return KeyInfo{}, ErrNoMatch
+}
+
+// GetKey decrypts the key of the public key with the passphrase.
+func (ks *KeyStore) GetKey(pubKey common.PubKey, passphrase string) (*Key, error) {
+	info, err := ks.Find(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	keyjson, err := ioutil.ReadFile(info.Path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := DecryptKey(keyjson, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if key.PubKey != pubKey {
+		return nil, ErrNoMatch
+	}
+	return key, nil
+}
+
+// Delete removes the key file of the public key if the passphrase is correct.
+func (ks *KeyStore) Delete(pubKey common.PubKey, passphrase string) error {
+	if _, err := ks.GetKey(pubKey, passphrase); err != nil {
+		return err
+	}
+	info, err := ks.Find(pubKey)
+	if err != nil {
+		return err
+	}
+	ks.Lock(pubKey)
+	return os.Remove(info.Path)
+}
+
+// Unlock decrypts the key and keeps it in memory until Lock is called.
+func (ks *KeyStore) Unlock(pubKey common.PubKey, passphrase string) error {
+	key, err := ks.GetKey(pubKey, passphrase)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	ks.unlocked[pubKey] = key
+	ks.mu.Unlock()
+	return nil
+}
+
+// Lock removes the decrypted key from memory.
+func (ks *KeyStore) Lock(pubKey common.PubKey) {
+	ks.mu.Lock()
+	delete(ks.unlocked, pubKey)
+	ks.mu.Unlock()
+}
+
+// Unlocked returns the public keys which are unlocked.
+func (ks *KeyStore) Unlocked() []common.PubKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	pubKeys := make([]common.PubKey, 0, len(ks.unlocked))
+	for pubKey := range ks.unlocked {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	sort.Slice(pubKeys, func(i, j int) bool { return pubKeys[i].Compare(pubKeys[j]) < 0 })
+	return pubKeys
+}
+
+// SignHash signs the hash with an unlocked key.
+func (ks *KeyStore) SignHash(pubKey common.PubKey, hash []byte) ([]byte, error) {
+	ks.mu.RLock()
+	key, ok := ks.unlocked[pubKey]
+	ks.mu.RUnlock()
+	if !ok {
+		return nil, ErrLocked
+	}
+	return crypto.Sign(hash, key.PrivateKey)
+}
+
+// SignHashWithPassphrase signs the hash with the key decrypted by the passphrase.
+func (ks *KeyStore) SignHashWithPassphrase(pubKey common.PubKey, passphrase string, hash []byte) ([]byte, error) {
+	key, err := ks.GetKey(pubKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash, key.PrivateKey)
+}
+
+func (ks *KeyStore) storeKey(key *Key, passphrase string) (KeyInfo, error) {
+	keyjson, err := EncryptKey(key, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	if err := os.MkdirAll(ks.keydir, 0700); err != nil {
+		return KeyInfo{}, err
+	}
+	path := filepath.Join(ks.keydir, keyFileName(key.PubKey))
+	// Write into a temporary file first so a partial key file is never left behind.
+	f, err := ioutil.TempFile(ks.keydir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	if _, err := f.Write(keyjson); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return KeyInfo{}, err
+	}
+	f.Close()
+	if err := os.Rename(f.Name(), path); err != nil {
+		return KeyInfo{}, err
+	}
+	return KeyInfo{PubKey: key.PubKey, Path: path}, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+)
+
+const (
+	veryLightScryptN = 2
+	veryLightScryptP = 1
+)
+
+var testPrivHex = "289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032"
+
+func tmpKeyStore(t *testing.T) (string, *KeyStore) {
+	d, err := ioutil.TempDir("", "ft-keystore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, NewKeyStore(d, veryLightScryptN, veryLightScryptP)
+}
+
+func TestEncryptDecryptKey(t *testing.T) {
+	key, err := newKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := EncryptKey(key, "foo", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptKey(keyjson, "bar"); err != ErrDecrypt {
+		t.Fatalf("wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	dkey, err := DecryptKey(keyjson, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dkey.PubKey != key.PubKey || dkey.PrivateKey.D.Cmp(key.PrivateKey.D) != 0 {
+		t.Fatal("decrypted key mismatch")
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	dir, ks := tmpKeyStore(t)
+	defer os.RemoveAll(dir)
+
+	info, err := ks.NewKey("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, _ := crypto.HexToECDSA(testPrivHex)
+	imported, err := ks.ImportECDSA(priv, "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.ImportECDSA(priv, "bar"); err != ErrKeyExists {
+		t.Fatalf("duplicate import: have %v, want %v", err, ErrKeyExists)
+	}
+
+	infos, err := ks.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("keys count mismatch: have %d, want 2", len(infos))
+	}
+
+	key, err := ks.GetKey(imported.PubKey, "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.PrivateKey.D.Cmp(priv.D) != 0 {
+		t.Fatal("exported key mismatch")
+	}
+
+	if err := ks.Delete(info.PubKey, "bar"); err != ErrDecrypt {
+		t.Fatalf("delete with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	if err := ks.Delete(info.PubKey, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Find(info.PubKey); err != ErrNoMatch {
+		t.Fatalf("find deleted key: have %v, want %v", err, ErrNoMatch)
+	}
+}
+
+func TestKeyStoreSign(t *testing.T) {
+	dir, ks := tmpKeyStore(t)
+	defer os.RemoveAll(dir)
+
+	info, err := ks.NewKey("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte("fractal"))
+
+	if _, err := ks.SignHash(info.PubKey, hash); err != ErrLocked {
+		t.Fatalf("sign with locked key: have %v, want %v", err, ErrLocked)
+	}
+	if err := ks.Unlock(info.PubKey, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ks.SignHash(info.PubKey, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToPubKey(pub) != info.PubKey {
+		t.Fatal("recovered public key mismatch")
+	}
+
+	ks.Lock(info.PubKey)
+	if _, err := ks.SignHash(info.PubKey, hash); err != ErrLocked {
+		t.Fatalf("sign after lock: have %v, want %v", err, ErrLocked)
+	}
+	if _, err := ks.SignHashWithPassphrase(info.PubKey, "foo", hash); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -37,6 +37,7 @@ const (
 	datadirBootNodes    = "bootnodes"    // Path within the datadir to the boot node list
 	datadirStaticNodes  = "staticnodes"  // Path within the datadir to the static node list
 	datadirTrustedNodes = "trustednodes" // Path within the datadir to the trusted node list
+	datadirKeyStore     = "keystore"     // Path within the datadir to the encrypted key files
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -46,6 +47,10 @@ type Config struct {
 	DataDir string `mapstructure:"datadir"`
 	IPCPath string `mapstructure:"ipcpath"`
 
+	// KeyStore is the directory of the encrypted key files, relative paths
+	// are resolved in the instance directory.
+	KeyStore string `mapstructure:"keystore"`
+
 	HTTPHost         string   `mapstructure:"httphost"`
 	HTTPPort         int      `mapstructure:"httpport"`
 	HTTPModules      []string `mapstructure:"httpmodules"`
@@ -130,6 +135,14 @@ func (c *Config) resolvePath(path string) string {
 	return filepath.Join(filepath.Join(c.DataDir, c.Name), path)
 }
 
+// KeyStoreDir returns the directory of the encrypted key files.
+func (c *Config) KeyStoreDir() string {
+	if c.KeyStore != "" {
+		return c.resolvePath(c.KeyStore)
+	}
+	return c.resolvePath(datadirKeyStore)
+}
+
 func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	// Use any specifically configured key.
 	if c.P2PConfig.PrivateKey != nil {
@@ -58,6 +58,11 @@ func (ctx *ServiceContext) ResolvePath(path string) string {
 	return ctx.config.resolvePath(path)
 }
 
+// KeyStoreDir returns the directory of the encrypted key files.
+func (ctx *ServiceContext) KeyStoreDir() string {
+	return ctx.config.KeyStoreDir()
+}
+
 func (ctx *ServiceContext) SetGenesisHash(genesisHash common.Hash) {
 	ctx.config.P2PConfig.GenesisHash = genesisHash
 }
@@ -154,7 +154,9 @@ const (
 	ForkID3 = uint64(3)
 	//ForkID4 miner pubkey separate
 	ForkID4 = uint64(4)
+	//ForkID5 account and asset action extensions, asset indexes, dpos slashing and voter rewards
+	ForkID5 = uint64(5)
 
 	// NextForkID is the id of next fork
-	NextForkID uint64 = ForkID4
+	NextForkID uint64 = ForkID5
 )
@@ -22,6 +22,7 @@ type GasTable struct {
 	ActionGasCallContract uint64
 	ActionGasCreation     uint64
 	ActionGasIssueAsset   uint64
+	AssetMetadataByteGas  uint64
 	SignGas               uint64
 	TxDataNonZeroGas      uint64
 	TxDataZeroGas         uint64
@@ -56,6 +57,14 @@ type GasTable struct {
 	GetCandidateNum uint64
 	GetCandidate    uint64
 	GetVoterStake   uint64
+	NFTOwner        uint64
+	MintNFT         uint64
+	MintNFTByte     uint64
+	TransferNFT     uint64
+	BurnNFT         uint64
+	Allowance       uint64
+	Approve         uint64
+	TransferFrom    uint64
 
 	Sha3Gas        uint64
 	Sha3WordGas    uint64
@@ -77,6 +86,7 @@ var (
 		ActionGasCallContract: 200000,
 		ActionGasCreation:     500000,
 		ActionGasIssueAsset:   10000000,
+		AssetMetadataByteGas:  625,
 		SignGas:               50000,
 
 		ExtcodeSize: 700,
@@ -104,6 +114,14 @@ var (
 		GetCandidateNum: 200,
 		GetCandidate:    200,
 		GetVoterStake:   200,
+		NFTOwner:        200,
+		MintNFT:         70000, // 3 new and 2 updated keys
+		MintNFTByte:     625,   // uri kept in the state
+		TransferNFT:     55000, // 2 new and 3 updated keys
+		BurnNFT:         200,
+		Allowance:       200,
+		Approve:         20000, // 1 new key
+		TransferFrom:    30000, // 1 new and 2 updated keys
 
 		TxDataNonZeroGas: 68,
 		TxDataZeroGas:    4,
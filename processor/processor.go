@@ -88,13 +88,22 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, uint64, error) {
+	return p.applyTransaction(author, gp, statedb, header, tx, usedGas, cfg, true)
+}
+
+// SimulateTransaction applies a transaction like ApplyTransaction but skips
+// the signature checks, so that unsigned transactions can be previewed.
+func (p *StateProcessor) SimulateTransaction(author *common.Name, gp *common.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, uint64, error) {
+	return p.applyTransaction(author, gp, statedb, header, tx, usedGas, cfg, false)
+}
+
+func (p *StateProcessor) applyTransaction(author *common.Name, gp *common.GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config, checkSign bool) (*types.Receipt, uint64, error) {
 	bc := p.bc
 	config := bc.Config()
 	accountDB, err := accountmanager.NewAccountManager(statedb)
 	if err != nil {
 		return nil, 0, err
 	}
-
 	// todo for the moment，only system asset
 	// assetID := tx.GasAssetID()
 	assetID := p.bc.Config().SysTokenID
@@ -109,11 +118,18 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 	detailTx := &types.DetailTx{}
 	var detailActions []*types.DetailAction
 	for i, action := range tx.GetActions() {
-		if needCheckSign(accountDB, action) {
+		if checkSign && needCheckSign(accountDB, action) {
 			if err := accountDB.RecoverTx(types.NewSigner(config.ChainID), tx); err != nil {
 				return nil, 0, err
 			}
 		}
+		chargeScopes := checkSign && header.CurForkID() >= params.ForkID5
+		epoch := accountmanager.AuthorScopeEpoch(config, header.Time.Uint64())
+		if chargeScopes {
+			if err := accountDB.CheckAuthorScopes(types.NewSigner(config.ChainID), tx, action, epoch); err != nil {
+				return nil, 0, err
+			}
+		}
 
 		nonce, err := accountDB.GetNonce(action.Sender())
 		if err != nil {
@@ -154,7 +170,12 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 			})
 		}
 
-		_, gas, failed, err, vmerr := ApplyMessage(accountDB, vmenv, action, gp, gasPrice, gasPayer, assetID, config, p.engine)
+		if cfg.Debug {
+			cfg.Tracer.CaptureStart(action.Sender(), action.Recipient(), action.Type() == types.CreateContract, action.Data(), action.Gas(), action.Value())
+		}
+		start := time.Now()
+
+		ret, gas, failed, err, vmerr := ApplyMessage(accountDB, vmenv, action, gp, gasPrice, gasPayer, assetID, config, p.engine)
 
 		if false == cfg.EndTime.IsZero() {
 			//close timer
@@ -162,6 +183,9 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 		}
 
 		if err != nil {
+			if cfg.Debug {
+				cfg.Tracer.CaptureEnd(ret, gas, time.Since(start), err)
+			}
 			return nil, 0, err
 		}
 
@@ -175,6 +199,12 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 			status = types.ReceiptStatusSuccessful
 
 		}
+		// only the value of succeeded actions is spent from the scoped author caps
+		if chargeScopes && !failed {
+			if err := accountDB.ChargeAuthorScopes(types.NewSigner(config.ChainID), tx, action, epoch); err != nil {
+				return nil, 0, err
+			}
+		}
 		vmerrstr := ""
 		if vmerr != nil {
 			vmerrstr = vmerr.Error()
@@ -184,6 +214,10 @@ func (p *StateProcessor) ApplyTransaction(author *common.Name, gp *common.GasPoo
 		for key, gas := range vmenv.FounderGasMap {
 			gasAllot = append(gasAllot, &types.GasDistribution{Account: key.ObjectName.String(), Gas: uint64(gas.Value), TypeID: gas.TypeID})
 		}
+		if cfg.Debug {
+			cfg.Tracer.CaptureGasDistribution(gasAllot)
+			cfg.Tracer.CaptureEnd(ret, gas, time.Since(start), vmerr)
+		}
 		ios = append(ios, &types.ActionResult{Status: status, Index: uint64(i), GasUsed: gas, GasAllot: gasAllot, Error: vmerrstr})
 
 		internalTxLog := make([]*types.InternalAction, 0, len(vmenv.InternalTxs))
@@ -103,7 +103,7 @@ func (st *StateTransition) buyGas() error {
 	}
 	st.gas += st.action.Gas()
 	st.initialGas = st.action.Gas()
-	return st.account.TransferAsset(st.gasPayer, common.Name(st.chainConfig.FeeName), st.assetID, mgval)
+	return st.account.TransferAsset(st.gasPayer, common.Name(st.chainConfig.FeeName), st.assetID, mgval, st.evm.Context.BlockNumber.Uint64())
 }
 
 // TransitionDb will transition the state by applying the current message and
@@ -159,7 +159,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 				ChainConfig: st.chainConfig,
 			})
 			vmerr = err
-			evm.InternalTxs = append(evm.InternalTxs, internalLogs...)
+			evm.AddInternalActions(internalLogs...)
 		} else {
 			internalLogs, err := st.account.Process(&types.AccountManagerContext{
 				Action:           st.action,
@@ -169,7 +169,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 				FromAccountExtra: []common.Name{fromExtra},
 			})
 			vmerr = err
-			evm.InternalTxs = append(evm.InternalTxs, internalLogs...)
+			evm.AddInternalActions(internalLogs...)
 		}
 
 	case actionType == types.RegCandidate:
@@ -188,11 +188,15 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		fallthrough
 	case actionType == types.RemoveKickedCandidate:
 		fallthrough
+	case actionType == types.ClaimVoterReward:
+		fallthrough
+	case actionType == types.ReportDoubleSign:
+		fallthrough
 	case actionType == types.ExitTakeOver:
 		internalLogs, err := st.engine.ProcessAction(st.evm.Context.ForkID, st.evm.Context.BlockNumber.Uint64(),
 			st.evm.ChainConfig(), st.evm.StateDB, st.action)
 		vmerr = err
-		evm.InternalTxs = append(evm.InternalTxs, internalLogs...)
+		evm.AddInternalActions(internalLogs...)
 	default:
 		internalLogs, err := st.account.Process(&types.AccountManagerContext{
 			Action:      st.action,
@@ -201,7 +205,7 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 			ChainConfig: st.chainConfig,
 		})
 		vmerr = err
-		evm.InternalTxs = append(evm.InternalTxs, internalLogs...)
+		evm.AddInternalActions(internalLogs...)
 	}
 	if vmerr != nil {
 		log.Debug("VM returned with error", "err", vmerr)
@@ -295,6 +299,34 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 	case types.DeleteAccount:
 		fallthrough
 	case types.UpdateAccountAuthor:
+		fallthrough
+	case types.SetAccountAuthorDelay:
+		fallthrough
+	case types.CancelAccountAuthor:
+		fallthrough
+	case types.ProposeAccountAction:
+		fallthrough
+	case types.ApproveAccountAction:
+		fallthrough
+	case types.ApproveAllowance:
+		fallthrough
+	case types.TransferFrom:
+		fallthrough
+	case types.LockHTLC:
+		fallthrough
+	case types.ClaimHTLC:
+		fallthrough
+	case types.RefundHTLC:
+		fallthrough
+	case types.DistributeDividend:
+		fallthrough
+	case types.ClaimDividend:
+		fallthrough
+	case types.ReclaimDividend:
+		fallthrough
+	case types.AtomicSwap:
+		fallthrough
+	case types.CancelSwapOrder:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.AccountName))
 		return
 	case types.IncreaseAsset:
@@ -307,6 +339,26 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 		fallthrough
 	case types.UpdateAssetContract:
 		fallthrough
+	case types.FreezeAsset:
+		fallthrough
+	case types.FreezeAccountAsset:
+		fallthrough
+	case types.VestingTransfer:
+		fallthrough
+	case types.RejectVestingTransfer:
+		fallthrough
+	case types.IssueNFTCollection:
+		fallthrough
+	case types.MintNFT:
+		fallthrough
+	case types.TransferNFT:
+		fallthrough
+	case types.BurnNFT:
+		fallthrough
+	case types.SetAssetMetadata:
+		fallthrough
+	case types.SetSubAssetPolicy:
+		fallthrough
 	case types.UpdateAsset:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.AssetName))
 		return
@@ -326,6 +378,10 @@ func (st *StateTransition) distributeGas(intrinsicGas uint64) {
 		fallthrough
 	case types.RemoveKickedCandidate:
 		fallthrough
+	case types.ClaimVoterReward:
+		fallthrough
+	case types.ReportDoubleSign:
+		fallthrough
 	case types.ExitTakeOver:
 		st.distributeToSystemAccount(common.Name(st.chainConfig.DposName))
 		return
@@ -405,7 +461,9 @@ func (st *StateTransition) distributeFee() error {
 
 func (st *StateTransition) refundGas() {
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.account.TransferAsset(common.Name(st.chainConfig.FeeName), st.gasPayer, st.assetID, remaining)
+	if err := st.account.TransferAsset(common.Name(st.chainConfig.FeeName), st.gasPayer, st.assetID, remaining, st.evm.Context.BlockNumber.Uint64()); err != nil {
+		log.Error("refund gas failed", "payer", st.gasPayer, "assetID", st.assetID, "amount", remaining, "err", err)
+	}
 	st.gp.AddGas(st.gas)
 }
 
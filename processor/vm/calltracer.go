@@ -0,0 +1,166 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// CallFrame is a call frame captured by the CallTracer. The top level frame
+// of every action also carries the gas distribution of the action.
+type CallFrame struct {
+	Type            string                   `json:"type"`
+	From            common.Name              `json:"from"`
+	To              common.Name              `json:"to"`
+	Value           *big.Int                 `json:"value,omitempty"`
+	Gas             uint64                   `json:"gas"`
+	GasUsed         uint64                   `json:"gasUsed"`
+	Input           hexutil.Bytes            `json:"input,omitempty"`
+	Output          hexutil.Bytes            `json:"output,omitempty"`
+	Error           string                   `json:"error,omitempty"`
+	Calls           []*CallFrame             `json:"calls,omitempty"`
+	InternalActions []*types.InternalAction  `json:"internalActions,omitempty"`
+	GasAllot        []*types.GasDistribution `json:"gasAllot,omitempty"`
+}
+
+func (f *CallFrame) finish(output []byte, gasUsed uint64, err error) {
+	f.GasUsed = gasUsed
+	if len(output) > 0 {
+		f.Output = common.CopyBytes(output)
+	}
+	if err != nil {
+		f.Error = err.Error()
+	}
+}
+
+// CallTracer is a Tracer which records the call frames of every action,
+// together with the internal actions and the gas distribution they cause.
+type CallTracer struct {
+	frames []*CallFrame // finished top level frames, one per action
+	stack  []*CallFrame // frames being executed
+}
+
+// NewCallTracer returns a new call tracer.
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+func (t *CallTracer) push(typ string, from common.Name, to common.Name, input []byte, gas uint64, value *big.Int) {
+	frame := &CallFrame{
+		Type:  typ,
+		From:  from,
+		To:    to,
+		Gas:   gas,
+		Input: common.CopyBytes(input),
+	}
+	if value != nil {
+		frame.Value = new(big.Int).Set(value)
+	}
+	t.stack = append(t.stack, frame)
+}
+
+func (t *CallTracer) pop() *CallFrame {
+	if len(t.stack) == 0 {
+		return nil
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	return frame
+}
+
+func (t *CallTracer) current() *CallFrame {
+	if len(t.stack) == 0 {
+		return nil
+	}
+	return t.stack[len(t.stack)-1]
+}
+
+// CaptureStart opens the top level frame of an action.
+func (t *CallTracer) CaptureStart(from common.Name, to common.Name, create bool, input []byte, gas uint64, value *big.Int) error {
+	typ := CALL.String()
+	if create {
+		typ = CREATE.String()
+	}
+	t.push(typ, from, to, input, gas, value)
+	return nil
+}
+
+func (t *CallTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *CallTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnter opens a nested call frame.
+func (t *CallTracer) CaptureEnter(typ OpCode, from common.Name, to common.Name, input []byte, gas uint64, value *big.Int) error {
+	t.push(typ.String(), from, to, input, gas, value)
+	return nil
+}
+
+// CaptureExit closes the nested call frame and attaches it to its caller.
+func (t *CallTracer) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	frame := t.pop()
+	if frame == nil {
+		return nil
+	}
+	frame.finish(output, gasUsed, err)
+	if parent := t.current(); parent != nil {
+		parent.Calls = append(parent.Calls, frame)
+	}
+	return nil
+}
+
+// CaptureInternalAction attaches the internal action to the running frame.
+func (t *CallTracer) CaptureInternalAction(action *types.InternalAction) error {
+	if frame := t.current(); frame != nil {
+		frame.InternalActions = append(frame.InternalActions, action)
+	}
+	return nil
+}
+
+// CaptureGasDistribution attaches the gas distribution to the running action.
+func (t *CallTracer) CaptureGasDistribution(gasAllot []*types.GasDistribution) error {
+	if len(t.stack) > 0 {
+		t.stack[0].GasAllot = gasAllot
+	}
+	return nil
+}
+
+// CaptureEnd closes the top level frame of an action.
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	// Unwind frames left open by an aborted execution.
+	for len(t.stack) > 1 {
+		t.CaptureExit(nil, 0, nil)
+	}
+	frame := t.pop()
+	if frame == nil {
+		return nil
+	}
+	frame.finish(output, gasUsed, err)
+	t.frames = append(t.frames, frame)
+	return nil
+}
+
+// Frames returns the top level frames of the traced actions.
+func (t *CallTracer) Frames() []*CallFrame { return t.frames }
@@ -0,0 +1,66 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func TestCallTracer(t *testing.T) {
+	var _ Tracer = (*CallTracer)(nil)
+	var _ Tracer = (*StructLogger)(nil)
+
+	tracer := NewCallTracer()
+	tracer.CaptureStart(common.Name("alice"), common.Name("contract"), false, []byte{1}, 100000, big.NewInt(1))
+	tracer.CaptureEnter(CALL, common.Name("contract"), common.Name("token"), nil, 50000, big.NewInt(0))
+	tracer.CaptureInternalAction(&types.InternalAction{ActionType: "addasset"})
+	tracer.CaptureExit([]byte{2}, 2000, nil)
+	tracer.CaptureEnter(STATICCALL, common.Name("contract"), common.Name("oracle"), nil, 40000, nil)
+	tracer.CaptureExit(nil, 40000, errors.New("out of gas"))
+	tracer.CaptureGasDistribution([]*types.GasDistribution{{Account: "contract", Gas: 21000}})
+	tracer.CaptureEnd(nil, 63000, 0, nil)
+
+	tracer.CaptureStart(common.Name("alice"), common.Name("bob"), false, nil, 30000, big.NewInt(5))
+	tracer.CaptureInternalAction(&types.InternalAction{ActionType: "transfer"})
+	tracer.CaptureEnd(nil, 21000, 0, nil)
+
+	frames := tracer.Frames()
+	if len(frames) != 2 {
+		t.Fatalf("frames mismatch: have %d, want 2", len(frames))
+	}
+	root := frames[0]
+	if root.Type != "CALL" || root.GasUsed != 63000 || len(root.GasAllot) != 1 {
+		t.Fatalf("root frame mismatch: %+v", root)
+	}
+	if len(root.Calls) != 2 {
+		t.Fatalf("calls mismatch: have %d, want 2", len(root.Calls))
+	}
+	if call := root.Calls[0]; call.To != common.Name("token") || len(call.InternalActions) != 1 || call.GasUsed != 2000 {
+		t.Fatalf("call frame mismatch: %+v", call)
+	}
+	if call := root.Calls[1]; call.Type != "STATICCALL" || call.Error != "out of gas" {
+		t.Fatalf("static call frame mismatch: %+v", call)
+	}
+	if len(frames[1].InternalActions) != 1 || len(frames[1].Calls) != 0 {
+		t.Fatalf("transfer frame mismatch: %+v", frames[1])
+	}
+}
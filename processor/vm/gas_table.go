@@ -506,6 +506,53 @@ func gasSetAssetOwner(gt params.GasTable, evm *EVM, contract *Contract, stack *S
 	return gt.SetOwner, nil
 }
 
+func gasNFTOwner(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.NFTOwner, nil
+}
+
+func gasMintNFT(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gas, err := memoryGasCost(gt, mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	var overflow bool
+	if gas, overflow = math.SafeAdd(gas, gt.MintNFT); overflow {
+		return 0, errGasUintOverflow
+	}
+	// the uri is kept in the state
+	size, overflow := bigUint64(stack.Back(1))
+	if overflow {
+		return 0, errGasUintOverflow
+	}
+	if size, overflow = math.SafeMul(size, gt.MintNFTByte); overflow {
+		return 0, errGasUintOverflow
+	}
+	if gas, overflow = math.SafeAdd(gas, size); overflow {
+		return 0, errGasUintOverflow
+	}
+	return gas, nil
+}
+
+func gasTransferNFT(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.TransferNFT, nil
+}
+
+func gasBurnNFT(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.BurnNFT, nil
+}
+
+func gasAllowance(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.Allowance, nil
+}
+
+func gasApprove(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.Approve, nil
+}
+
+func gasTransferFrom(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return gt.TransferFrom, nil
+}
+
 func gasWithdrawFee(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	return gt.WithdrawFee, nil
 }
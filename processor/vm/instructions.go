@@ -1243,12 +1243,12 @@ func execAddAsset(evm *EVM, contract *Contract, assetID uint64, toName common.Na
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "addasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.AddInternalActions(internalAction)
 		if len(internalActions) > 0 {
 			for _, iLog := range internalActions {
 				iLog.Depth = uint64(evm.depth)
 			}
-			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+			evm.AddInternalActions(internalActions...)
 		}
 	}
 	return err
@@ -1272,12 +1272,12 @@ func opDestroyAsset(pc *uint64, evm *EVM, contract *Contract, memory *Memory, st
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "destroyasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.AddInternalActions(internalAction)
 		if len(internalActions) > 0 {
 			for _, iLog := range internalActions {
 				iLog.Depth = uint64(evm.depth)
 			}
-			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+			evm.AddInternalActions(internalActions...)
 		}
 	}
 
@@ -1480,12 +1480,12 @@ func executeIssuseAsset(evm *EVM, contract *Contract, desc string) (uint64, erro
 					errmsg = err.Error()
 				}
 				internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "issueasset", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-				evm.InternalTxs = append(evm.InternalTxs, internalAction)
+				evm.AddInternalActions(internalAction)
 				if len(internalActions) > 0 {
 					for _, iLog := range internalActions {
 						iLog.Depth = uint64(evm.depth)
 					}
-					evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+					evm.AddInternalActions(internalActions...)
 				}
 			}
 			return assetInfo.AssetID, nil
@@ -1536,12 +1536,177 @@ func execSetAssetOwner(evm *EVM, contract *Contract, assetID uint64, owner commo
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "setassetowner", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.AddInternalActions(internalAction)
+		if len(internalActions) > 0 {
+			for _, iLog := range internalActions {
+				iLog.Depth = uint64(evm.depth)
+			}
+			evm.AddInternalActions(internalActions...)
+		}
+	}
+	return err
+}
+
+// opNFTOwner get the owner account ID of a non-fungible token
+func opNFTOwner(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	tokenID, collectionID := stack.pop(), stack.pop()
+	token, err := evm.AccountDB.GetNFToken(collectionID.Uint64(), tokenID.Uint64())
+	if err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else if acct, err := evm.AccountDB.GetAccountByName(token.Owner); err != nil || acct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(acct.GetAccountID()))
+	}
+	evm.interpreter.intPool.put(tokenID, collectionID)
+	return nil, nil
+}
+
+// opMintNFT mint a token of a collection owned by the contract, the uri is read from memory
+func opMintNFT(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	offset, size, to, collectionID := stack.pop(), stack.pop(), stack.pop(), stack.pop()
+	uri := string(bytes.TrimRight(memory.Get(offset.Int64(), size.Int64()), "\x00"))
+	evm.interpreter.intPool.put(offset, size)
+
+	acct, err := evm.AccountDB.GetAccountById(to.Uint64())
+	if err != nil || acct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	collection, err := evm.AccountDB.GetNFTCollection(collectionID.Uint64())
+	if err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	mint := &accountmanager.MintNFT{CollectionID: collection.CollectionID, To: acct.GetName(), URI: uri}
+	if err := execAction(evm, contract, types.MintNFT, common.Name(evm.chainConfig.AssetName), mint, "mintnft"); err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		// the minted token is the last one of the collection
+		stack.push(evm.interpreter.intPool.get().SetUint64(collection.Minted + 1))
+	}
+	return nil, nil
+}
+
+// opTransferNFT transfer a token owned by the contract
+func opTransferNFT(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	to, tokenID, collectionID := stack.pop(), stack.pop(), stack.pop()
+	acct, err := evm.AccountDB.GetAccountById(to.Uint64())
+	if err != nil || acct == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	transfer := &accountmanager.TransferNFT{CollectionID: collectionID.Uint64(), TokenID: tokenID.Uint64()}
+	if err := execAction(evm, contract, types.TransferNFT, acct.GetName(), transfer, "transfernft"); err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(1))
+	}
+	evm.interpreter.intPool.put(to, tokenID, collectionID)
+	return nil, nil
+}
+
+// opBurnNFT burn a token owned by the contract
+func opBurnNFT(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	tokenID, collectionID := stack.pop(), stack.pop()
+	burn := &accountmanager.BurnNFT{CollectionID: collectionID.Uint64(), TokenID: tokenID.Uint64()}
+	if err := execAction(evm, contract, types.BurnNFT, common.Name(evm.chainConfig.AssetName), burn, "burnnft"); err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(1))
+	}
+	evm.interpreter.intPool.put(tokenID, collectionID)
+	return nil, nil
+}
+
+// opAllowance get the allowance of the spender on the asset of the owner
+func opAllowance(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	assetID, spenderID, ownerID := stack.pop(), stack.pop(), stack.pop()
+	owner, err := evm.AccountDB.GetAccountById(ownerID.Uint64())
+	if err != nil || owner == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	spender, err := evm.AccountDB.GetAccountById(spenderID.Uint64())
+	if err != nil || spender == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	if allowance, err := evm.AccountDB.GetAllowance(owner.GetName(), spender.GetName(), assetID.Uint64()); err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().Set(allowance))
+	}
+	evm.interpreter.intPool.put(assetID, spenderID, ownerID)
+	return nil, nil
+}
+
+// opApprove set the allowance of the spender on the asset of the contract
+func opApprove(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	amount, assetID, spenderID := stack.pop(), stack.pop(), stack.pop()
+	spender, err := evm.AccountDB.GetAccountById(spenderID.Uint64())
+	if err != nil || spender == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	approve := &accountmanager.ApproveAllowanceAction{Spender: spender.GetName(), AssetID: assetID.Uint64(), Amount: math.U256(amount)}
+	if err := execAction(evm, contract, types.ApproveAllowance, common.Name(evm.chainConfig.AccountName), approve, "approve"); err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(1))
+	}
+	evm.interpreter.intPool.put(assetID, spenderID)
+	return nil, nil
+}
+
+// opTransferFrom transfer the asset of the owner within the allowance of the contract
+func opTransferFrom(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *Stack) ([]byte, error) {
+	amount, assetID, toID, ownerID := stack.pop(), stack.pop(), stack.pop(), stack.pop()
+	owner, err := evm.AccountDB.GetAccountById(ownerID.Uint64())
+	if err != nil || owner == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	to, err := evm.AccountDB.GetAccountById(toID.Uint64())
+	if err != nil || to == nil {
+		stack.push(evm.interpreter.intPool.getZero())
+		return nil, nil
+	}
+	transfer := &accountmanager.TransferFromAction{Owner: owner.GetName(), To: to.GetName(), AssetID: assetID.Uint64(), Amount: math.U256(amount)}
+	if err := execAction(evm, contract, types.TransferFrom, common.Name(evm.chainConfig.AccountName), transfer, "transferfrom"); err != nil {
+		stack.push(evm.interpreter.intPool.getZero())
+	} else {
+		stack.push(evm.interpreter.intPool.get().SetUint64(1))
+	}
+	evm.interpreter.intPool.put(assetID, toID, ownerID)
+	return nil, nil
+}
+
+func execAction(evm *EVM, contract *Contract, actionType types.ActionType, to common.Name, payload interface{}, logType string) error {
+	b, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return err
+	}
+
+	action := types.NewAction(actionType, contract.Name(), to, 0, evm.chainConfig.SysTokenID, 0, big.NewInt(0), b, nil)
+	internalActions, err := evm.AccountDB.Process(&types.AccountManagerContext{
+		Action:      action,
+		Number:      evm.Context.BlockNumber.Uint64(),
+		CurForkID:   evm.Context.ForkID,
+		ChainConfig: evm.chainConfig,
+	})
+	if evm.vmConfig.ContractLogFlag {
+		errmsg := ""
+		if err != nil {
+			errmsg = err.Error()
+		}
+		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: logType, GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth), Error: errmsg}
+		evm.AddInternalActions(internalAction)
 		if len(internalActions) > 0 {
 			for _, iLog := range internalActions {
 				iLog.Depth = uint64(evm.depth)
 			}
-			evm.InternalTxs = append(evm.InternalTxs, internalActions...)
+			evm.AddInternalActions(internalActions...)
 		}
 	}
 	return err
@@ -1588,7 +1753,7 @@ func opWithdrawFee(pc *uint64, evm *EVM, contract *Contract, memory *Memory, sta
 
 func execWithdrawFee(evm *EVM, contract *Contract, withdrawTo common.Name, objectType uint64) error {
 	fm := feemanager.NewFeeManager(evm.StateDB, evm.AccountDB)
-	withdrawInfo, err := fm.WithdrawFeeFromSystem(withdrawTo.String(), objectType)
+	withdrawInfo, err := fm.WithdrawFeeFromSystem(withdrawTo.String(), objectType, evm.Context.BlockNumber.Uint64())
 
 	if evm.vmConfig.ContractLogFlag {
 		if err != nil {
@@ -1602,7 +1767,7 @@ func execWithdrawFee(evm *EVM, contract *Contract, withdrawTo common.Name, objec
 		for _, assetInfo := range withdrawInfo.AssetInfo {
 			action := types.NewAction(types.Transfer, common.Name(evm.chainConfig.FeeName), withdrawInfo.Founder, 0, assetInfo.AssetID, 0, assetInfo.Amount, paload, nil)
 			internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transfer", GasUsed: 0, GasLimit: contract.Gas, Depth: uint64(evm.depth)}
-			evm.InternalTxs = append(evm.InternalTxs, internalAction)
+			evm.AddInternalActions(internalAction)
 		}
 
 	}
@@ -1646,7 +1811,7 @@ func opCallEx(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 		}
 	}
 
-	err = evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), fromExtra)
+	err = evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), evm.Context.BlockNumber.Uint64(), fromExtra)
 	//distribute gas
 	var assetName common.Name
 	assetFounder, _ := evm.AccountDB.GetAssetFounder(action.AssetID()) //get asset founder name
@@ -1668,7 +1833,7 @@ func opCallEx(pc *uint64, evm *EVM, contract *Contract, memory *Memory, stack *S
 			errmsg = err.Error()
 		}
 		internalAction := &types.InternalAction{Action: action.NewRPCAction(0), ActionType: "transferex", GasUsed: 0, GasLimit: 0, Depth: uint64(evm.depth), Error: errmsg}
-		evm.InternalTxs = append(evm.InternalTxs, internalAction)
+		evm.AddInternalActions(internalAction)
 	}
 	return nil, nil
 }
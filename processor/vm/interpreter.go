@@ -72,6 +72,8 @@ func NewInterpreter(evm *EVM, cfg Config) *Interpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		switch {
+		case evm.ForkID >= params.ForkID5:
+			cfg.JumpTable = forkID5InstructionSet
 		//case evm.ChainConfig().IsConstantinople(evm.BlockNumber):
 		//	cfg.JumpTable = constantinopleInstructionSet
 		//case evm.ChainConfig().IsByzantium(evm.BlockNumber):
@@ -55,8 +55,71 @@ var (
 	homesteadInstructionSet      = NewHomesteadInstructionSet()
 	byzantiumInstructionSet      = NewByzantiumInstructionSet()
 	constantinopleInstructionSet = NewConstantinopleInstructionSet()
+	forkID5InstructionSet        = NewForkID5InstructionSet()
 )
 
+// NewForkID5InstructionSet returns the constantinople instructions and the
+// non-fungible token and allowance instructions added by ForkID5.
+func NewForkID5InstructionSet() [256]operation {
+	instructionSet := NewConstantinopleInstructionSet()
+	instructionSet[NFTOWNER] = operation{
+		execute:       opNFTOwner,
+		gasCost:       gasNFTOwner,
+		validateStack: makeStackFunc(2, 1),
+		valid:         true,
+	}
+
+	instructionSet[MINTNFT] = operation{
+		execute:       opMintNFT,
+		gasCost:       gasMintNFT,
+		validateStack: makeStackFunc(4, 1),
+		memorySize:    memoryMintNFT,
+		valid:         true,
+		writes:        true,
+	}
+
+	instructionSet[TRANSFERNFT] = operation{
+		execute:       opTransferNFT,
+		gasCost:       gasTransferNFT,
+		validateStack: makeStackFunc(3, 1),
+		valid:         true,
+		writes:        true,
+	}
+
+	instructionSet[BURNNFT] = operation{
+		execute:       opBurnNFT,
+		gasCost:       gasBurnNFT,
+		validateStack: makeStackFunc(2, 1),
+		valid:         true,
+		writes:        true,
+	}
+
+	instructionSet[ALLOWANCE] = operation{
+		execute:       opAllowance,
+		gasCost:       gasAllowance,
+		validateStack: makeStackFunc(3, 1),
+		valid:         true,
+	}
+
+	instructionSet[APPROVE] = operation{
+		execute:       opApprove,
+		gasCost:       gasApprove,
+		validateStack: makeStackFunc(3, 1),
+		valid:         true,
+		writes:        true,
+	}
+
+	instructionSet[TRANSFERFROM] = operation{
+		execute:       opTransferFrom,
+		gasCost:       gasTransferFrom,
+		validateStack: makeStackFunc(4, 1),
+		valid:         true,
+		writes:        true,
+	}
+
+	return instructionSet
+}
+
 // NewConstantinopleInstructionSet returns the frontier, homestead
 // byzantium and contantinople instructions.
 func NewConstantinopleInstructionSet() [256]operation {
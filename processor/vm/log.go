@@ -75,14 +75,20 @@ func (s *StructLog) ErrorString() string {
 }
 
 // Tracer is used to collect execution traces from an EVM transaction
-// execution. CaptureState is called for each step of the VM with the
-// current VM state.
+// execution. CaptureStart and CaptureEnd wrap every action of the
+// transaction, CaptureEnter and CaptureExit wrap every nested call frame
+// and CaptureState is called for each step of the VM with the current
+// VM state.
 // Note that reference types are actual VM data structures; make copies
 // if you need to retain them beyond the current call.
 type Tracer interface {
-	CaptureStart(from common.Name, to common.Name, call bool, input []byte, gas uint64, value *big.Int) error
+	CaptureStart(from common.Name, to common.Name, create bool, input []byte, gas uint64, value *big.Int) error
 	CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
 	CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error
+	CaptureEnter(typ OpCode, from common.Name, to common.Name, input []byte, gas uint64, value *big.Int) error
+	CaptureExit(output []byte, gasUsed uint64, err error) error
+	CaptureInternalAction(action *types.InternalAction) error
+	CaptureGasDistribution(gasAllot []*types.GasDistribution) error
 	CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error
 }
 
@@ -111,7 +117,7 @@ func NewStructLogger(cfg *LogConfig) *StructLogger {
 	return logger
 }
 
-func (l *StructLogger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+func (l *StructLogger) CaptureStart(from common.Name, to common.Name, create bool, input []byte, gas uint64, value *big.Int) error {
 	return nil
 }
 
@@ -169,6 +175,22 @@ func (l *StructLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost ui
 	return nil
 }
 
+func (l *StructLogger) CaptureEnter(typ OpCode, from common.Name, to common.Name, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) error {
+	return nil
+}
+
+func (l *StructLogger) CaptureInternalAction(action *types.InternalAction) error {
+	return nil
+}
+
+func (l *StructLogger) CaptureGasDistribution(gasAllot []*types.GasDistribution) error {
+	return nil
+}
+
 func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
 	l.output = output
 	l.err = err
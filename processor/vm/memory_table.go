@@ -113,6 +113,10 @@ func memoryGetAccountID(stack *Stack) *big.Int {
 	return calcMemSize(stack.Back(0), big.NewInt(1))
 }
 
+func memoryMintNFT(stack *Stack) *big.Int {
+	return calcMemSize(stack.Back(0), stack.Back(1))
+}
+
 func memoryIssueAsset(stack *Stack) *big.Int {
 	return calcMemSize(stack.Back(0), big.NewInt(1))
 }
@@ -232,6 +232,17 @@ const (
 	CALLWITHPAY = 0xd5
 )
 
+const (
+	//0xe0 range  new add for non-fungible tokens and allowances
+	NFTOWNER OpCode = 0xe0 + iota
+	MINTNFT
+	TRANSFERNFT
+	BURNNFT
+	ALLOWANCE
+	APPROVE
+	TRANSFERFROM
+)
+
 const (
 	// 0xf0 range - closures
 	CREATE OpCode = 0xf0 + iota
@@ -425,6 +436,16 @@ var opCodeToString = map[OpCode]string{
 	RECIPIENT:       "RECIPIENT",
 	CALLWITHPAY:     "CALLWITHPAY",
 
+	//0xe0 range  new add for non-fungible tokens
+	NFTOWNER:    "NFTOWNER",
+	MINTNFT:     "MINTNFT",
+	TRANSFERNFT: "TRANSFERNFT",
+	BURNNFT:     "BURNNFT",
+
+	ALLOWANCE:    "ALLOWANCE",
+	APPROVE:      "APPROVE",
+	TRANSFERFROM: "TRANSFERFROM",
+
 	// 0xf0 range
 	CREATE:       "CREATE",
 	CALL:         "CALL",
@@ -614,6 +635,16 @@ var stringToOp = map[string]OpCode{
 	"RECIPIENT":       RECIPIENT,
 	"CALLWITHPAY":     CALLWITHPAY,
 
+	//0xe0 range  new add for non-fungible tokens
+	"NFTOWNER":    NFTOWNER,
+	"MINTNFT":     MINTNFT,
+	"TRANSFERNFT": TRANSFERNFT,
+	"BURNNFT":     BURNNFT,
+
+	"ALLOWANCE":    ALLOWANCE,
+	"APPROVE":      APPROVE,
+	"TRANSFERFROM": TRANSFERFROM,
+
 	//"CREATE":   CREATE,
 	"CALL":     CALL,
 	"RETURN":   RETURN,
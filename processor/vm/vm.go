@@ -20,7 +20,6 @@ package vm
 import (
 	"math/big"
 	"sync/atomic"
-	"time"
 
 	"github.com/fractalplatform/fractal/accountmanager"
 	"github.com/fractalplatform/fractal/common"
@@ -292,9 +291,13 @@ func (evm *EVM) Call(caller ContractRef, action *types.Action, gas uint64) (ret
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	if evm.vmConfig.Debug && evm.depth > 0 {
+		evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Name(), action.Recipient(), action.Data(), gas, action.Value())
+		defer func(startGas uint64) { evm.vmConfig.Tracer.CaptureExit(ret, startGas-leftOverGas, err) }(gas)
+	}
 	// Fail if we're trying to transfer more than the available balance
 
-	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), action.AssetID(), action.Value()); !ok || err != nil {
+	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), action.AssetID(), action.Value(), evm.Context.BlockNumber.Uint64()); !ok || err != nil {
 		return nil, gas, ErrInsufficientBalance
 	}
 
@@ -328,7 +331,7 @@ func (evm *EVM) Call(caller ContractRef, action *types.Action, gas uint64) (ret
 		}
 	}
 
-	if err := evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), fromExtra); err != nil {
+	if err := evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), action.AssetID(), action.Value(), evm.Context.BlockNumber.Uint64(), fromExtra); err != nil {
 		return nil, gas, err
 	}
 
@@ -401,8 +404,12 @@ func (evm *EVM) CallCode(caller ContractRef, action *types.Action, gas uint64) (
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(CALLCODE, caller.Name(), action.Recipient(), action.Data(), gas, action.Value())
+		defer func(startGas uint64) { evm.vmConfig.Tracer.CaptureExit(ret, startGas-leftOverGas, err) }(gas)
+	}
 	// Fail if we're trying to transfer more than the available balance
-	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), evm.AssetID, action.Value()); !ok || err != nil {
+	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), evm.AssetID, action.Value(), evm.Context.BlockNumber.Uint64()); !ok || err != nil {
 		return nil, gas, ErrInsufficientBalance
 	}
 
@@ -461,6 +468,10 @@ func (evm *EVM) DelegateCall(caller ContractRef, name common.Name, input []byte,
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(DELEGATECALL, caller.Name(), name, input, gas, nil)
+		defer func(startGas uint64) { evm.vmConfig.Tracer.CaptureExit(ret, startGas-leftOverGas, err) }(gas)
+	}
 
 	var (
 		snapshot = evm.StateDB.Snapshot()
@@ -513,6 +524,10 @@ func (evm *EVM) StaticCall(caller ContractRef, name common.Name, input []byte, g
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(STATICCALL, caller.Name(), name, input, gas, nil)
+		defer func(startGas uint64) { evm.vmConfig.Tracer.CaptureExit(ret, startGas-leftOverGas, err) }(gas)
+	}
 	// Make sure the readonly is only set if we aren't in readonly yet
 	// this makes also sure that the readonly flag isn't removed for
 	// child calls.
@@ -572,7 +587,7 @@ func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (re
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
 	}
-	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), evm.AssetID, action.Value()); !ok || err != nil {
+	if ok, err := evm.AccountDB.CanTransfer(caller.Name(), evm.AssetID, action.Value(), evm.Context.BlockNumber.Uint64()); !ok || err != nil {
 		return nil, gas, ErrInsufficientBalance
 	}
 
@@ -593,7 +608,7 @@ func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (re
 		}
 	}
 
-	if err := evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), evm.AssetID, action.Value()); err != nil {
+	if err := evm.AccountDB.TransferAsset(action.Sender(), action.Recipient(), evm.AssetID, action.Value(), evm.Context.BlockNumber.Uint64()); err != nil {
 		evm.StateDB.RevertToSnapshot(snapshot)
 		return nil, gas, err
 	}
@@ -608,11 +623,6 @@ func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (re
 		return nil, gas, nil
 	}
 
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureStart(caller.Name(), contractName, true, action.Data(), gas, action.Value())
-	}
-	start := time.Now()
-
 	ret, err = run(evm, contract, nil)
 
 	// check whether the max code size has been exceeded
@@ -645,9 +655,6 @@ func (evm *EVM) Create(caller ContractRef, action *types.Action, gas uint64) (re
 	if maxCodeSizeExceeded && err == nil {
 		err = errMaxCodeSizeExceeded
 	}
-	if evm.vmConfig.Debug && evm.depth == 0 {
-		evm.vmConfig.Tracer.CaptureEnd(ret, gas-contract.Gas, time.Since(start), err)
-	}
 
 	evm.distributeContractGas(gas-contract.Gas, contractName, contractName)
 	return ret, contract.Gas, err
@@ -658,6 +665,12 @@ func (evm *EVM) CanTransferContractAsset(caller ContractRef, gas uint64, assetID
 	if evm.depth > int(params.CallCreateDepth) {
 		return gas, false
 	}
+	// Frozen balances can't be moved by the asset contract either
+	if evm.ForkID >= params.ForkID5 {
+		if err := evm.AccountDB.CheckAssetFrozen(assetID, caller.Name()); err != nil {
+			return gas, false
+		}
+	}
 	var (
 		to       = AccountRef(assetContract)
 		snapshot = evm.StateDB.Snapshot()
@@ -680,6 +693,9 @@ func (evm *EVM) CanTransferContractAsset(caller ContractRef, gas uint64, assetID
 	code, _ := acct.GetCode()
 	contract.SetCallCode(&assetContract, codeHash, code)
 
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureEnter(CALL, caller.Name(), assetContract, contractAssetTransferable, gas, big.NewInt(0))
+	}
 	ret, err := run(evm, contract, contractAssetTransferable)
 	runGas := gas - contract.Gas
 
@@ -696,6 +712,9 @@ func (evm *EVM) CanTransferContractAsset(caller ContractRef, gas uint64, assetID
 	}
 	actualUsedGas := gas - contract.Gas
 	evm.distributeGasByScale(actualUsedGas, runGas)
+	if evm.vmConfig.Debug {
+		evm.vmConfig.Tracer.CaptureExit(ret, actualUsedGas, err)
+	}
 
 	if new(big.Int).SetBytes(ret).Cmp(big.NewInt(0)) > 0 && err == nil {
 		return contract.Gas, true
@@ -704,6 +723,17 @@ func (evm *EVM) CanTransferContractAsset(caller ContractRef, gas uint64, assetID
 	}
 }
 
+// AddInternalActions records the internal actions of the running action
+// and reports them to the tracer.
+func (evm *EVM) AddInternalActions(actions ...*types.InternalAction) {
+	evm.InternalTxs = append(evm.InternalTxs, actions...)
+	if evm.vmConfig.Debug {
+		for _, action := range actions {
+			evm.vmConfig.Tracer.CaptureInternalAction(action)
+		}
+	}
+}
+
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
@@ -17,10 +17,12 @@ package vm
 
 import (
 	"fmt"
+	"math/big"
 	"sort"
 	"testing"
 
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -122,3 +124,39 @@ func TestDistributeGasByScale(t *testing.T) {
 	evm.distributeGasByScale(0, 0)
 	return
 }
+
+func TestForkID5InstructionSet(t *testing.T) {
+	ops := []OpCode{NFTOWNER, MINTNFT, TRANSFERNFT, BURNNFT, ALLOWANCE, APPROVE, TRANSFERFROM}
+	before := NewInterpreter(&EVM{Context: Context{ForkID: params.ForkID4}}, Config{})
+	after := NewInterpreter(&EVM{Context: Context{ForkID: params.ForkID5}}, Config{})
+	for _, op := range ops {
+		if before.cfg.JumpTable[op].valid {
+			t.Errorf("%v valid before ForkID5", op)
+		}
+		if !after.cfg.JumpTable[op].valid {
+			t.Errorf("%v invalid after ForkID5", op)
+		}
+	}
+}
+
+func TestGasMintNFT(t *testing.T) {
+	gt := params.GasTableInstance
+	stack := newstack()
+	// size and offset of the uri
+	stack.push(big.NewInt(100))
+	stack.push(big.NewInt(0))
+	gas, err := gasMintNFT(gt, nil, nil, stack, NewMemory(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := gt.MintNFT + 100*gt.MintNFTByte; gas != want {
+		t.Fatalf("mint gas mismatch, want %d, got %d", want, gas)
+	}
+	stack.pop()
+	stack.pop()
+	stack.push(new(big.Int).Lsh(big.NewInt(1), 64))
+	stack.push(big.NewInt(0))
+	if _, err := gasMintNFT(gt, nil, nil, stack, NewMemory(), 0); err != errGasUintOverflow {
+		t.Fatalf("overflow error = %v, want %v", err, errGasUintOverflow)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// Roles of an account in an indexed action.
+const (
+	AccountTxSender    uint64 = 1 << iota // account is the sender of the action
+	AccountTxRecipient                    // account is the recipient of the action
+	AccountTxPayer                        // account pays the fee of the action
+	AccountTxInternal                     // account is involved in an internal action
+)
+
+// accountTxPositionLength is the length of the position suffix of an account tx key.
+const accountTxPositionLength = 16
+
+// AccountTxEntry is a positional metadata of an action an account is involved in.
+type AccountTxEntry struct {
+	BlockHash   common.Hash
+	BlockNumber uint64
+	TxHash      common.Hash
+	TxIndex     uint32
+	ActionIndex uint32
+	Roles       uint64
+}
+
+// accountTxValue is the stored part of an AccountTxEntry, the position is in the key.
+type accountTxValue struct {
+	BlockHash common.Hash
+	TxHash    common.Hash
+	Roles     uint64
+}
+
+type accountTxPosition struct {
+	name        common.Name
+	txIndex     uint32
+	actionIndex uint32
+}
+
+// accountTxRoles collects the accounts involved in every action of the block,
+// detailTxs holds the internal actions and may be empty.
+func accountTxRoles(block *types.Block, detailTxs []*types.DetailTx) (map[accountTxPosition]uint64, []accountTxPosition) {
+	var (
+		roles     = make(map[accountTxPosition]uint64)
+		positions []accountTxPosition
+	)
+	add := func(name common.Name, txIndex, actionIndex int, role uint64) {
+		if len(name) == 0 {
+			return
+		}
+		pos := accountTxPosition{name, uint32(txIndex), uint32(actionIndex)}
+		if _, ok := roles[pos]; !ok {
+			positions = append(positions, pos)
+		}
+		roles[pos] |= role
+	}
+	for i, tx := range block.Txs {
+		for j, action := range tx.GetActions() {
+			add(action.Sender(), i, j, AccountTxSender)
+			add(action.Recipient(), i, j, AccountTxRecipient)
+			if action.PayerIsExist() {
+				add(action.Payer(), i, j, AccountTxPayer)
+			}
+			if i >= len(detailTxs) || detailTxs[i] == nil || j >= len(detailTxs[i].Actions) {
+				continue
+			}
+			for _, internal := range detailTxs[i].Actions[j].InternalActions {
+				if internal.Action == nil {
+					continue
+				}
+				add(internal.Action.From, i, j, AccountTxInternal)
+				add(internal.Action.To, i, j, AccountTxInternal)
+			}
+		}
+	}
+	return roles, positions
+}
+
+// WriteAccountTxEntries stores a positional metadata for every account involved
+// in the actions of a block, as sender, recipient, fee payer or in an internal action.
+func WriteAccountTxEntries(db DatabaseWriter, block *types.Block, detailTxs []*types.DetailTx) {
+	roles, positions := accountTxRoles(block, detailTxs)
+	for _, pos := range positions {
+		data, err := rlp.EncodeToBytes(accountTxValue{
+			BlockHash: block.Hash(),
+			TxHash:    block.Txs[pos.txIndex].Hash(),
+			Roles:     roles[pos],
+		})
+		if err != nil {
+			log.Crit("Failed to encode account tx entry", "err", err)
+		}
+		if err := db.Put(accountTxKey(pos.name, block.NumberU64(), pos.txIndex, pos.actionIndex), data); err != nil {
+			log.Crit("Failed to store account tx entry", "err", err)
+		}
+	}
+}
+
+// DeleteAccountTxEntries removes the account tx entries written for a block.
+func DeleteAccountTxEntries(db DatabaseDeleter, block *types.Block, detailTxs []*types.DetailTx) {
+	_, positions := accountTxRoles(block, detailTxs)
+	for _, pos := range positions {
+		db.Delete(accountTxKey(pos.name, block.NumberU64(), pos.txIndex, pos.actionIndex))
+	}
+}
+
+// ReadAccountTxEntries retrieves at most limit entries of the account, newest
+// first, starting below the given cursor. The returned cursor is nil when
+// there are no more entries.
+func ReadAccountTxEntries(db DatabaseIteratee, name common.Name, cursor []byte, limit int) ([]*AccountTxEntry, []byte) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	prefix := accountTxPrefixKey(name)
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	var ok bool
+	if len(cursor) == 0 {
+		ok = it.Last()
+	} else if ok = it.Seek(append(common.CopyBytes(prefix), cursor...)); ok {
+		ok = it.Prev()
+	} else {
+		ok = it.Last()
+	}
+
+	var (
+		entries []*AccountTxEntry
+		next    []byte
+	)
+	for ; ok; ok = it.Prev() {
+		if len(entries) >= limit {
+			next = entries[len(entries)-1].position()
+			break
+		}
+		key := it.Key()
+		if len(key) != len(prefix)+accountTxPositionLength {
+			continue
+		}
+		var value accountTxValue
+		if err := rlp.DecodeBytes(it.Value(), &value); err != nil {
+			log.Error("Invalid account tx entry RLP", "name", name, "err", err)
+			continue
+		}
+		pos := key[len(prefix):]
+		entries = append(entries, &AccountTxEntry{
+			BlockHash:   value.BlockHash,
+			BlockNumber: binary.BigEndian.Uint64(pos),
+			TxHash:      value.TxHash,
+			TxIndex:     binary.BigEndian.Uint32(pos[8:]),
+			ActionIndex: binary.BigEndian.Uint32(pos[12:]),
+			Roles:       value.Roles,
+		})
+	}
+	return entries, next
+}
+
+// position returns the encoded position of the entry, used as pagination cursor.
+func (e *AccountTxEntry) position() []byte {
+	return encodeAccountTxPosition(e.BlockNumber, e.TxIndex, e.ActionIndex)
+}
+
+// ReadAccountTxIndexTail retrieves the oldest block number covered by the
+// account tx index, nil is returned if the index was never built.
+func ReadAccountTxIndexTail(db DatabaseReader) *uint64 {
+	data, _ := db.Get(accountTxIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := decodeBlockNumber(data)
+	return &number
+}
+
+// WriteAccountTxIndexTail stores the oldest block number covered by the account tx index.
+func WriteAccountTxIndexTail(db DatabaseWriter, number uint64) {
+	if err := db.Put(accountTxIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store account tx index tail", "err", err)
+	}
+}
+
+// DeleteAccountTxIndexTail removes the account tx index tail.
+func DeleteAccountTxIndexTail(db DatabaseDeleter) {
+	if err := db.Delete(accountTxIndexTailKey); err != nil {
+		log.Crit("Failed to delete account tx index tail", "err", err)
+	}
+}
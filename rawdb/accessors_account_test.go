@@ -0,0 +1,93 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// Tests that account tx entries can be stored, paged through and removed.
+func TestAccountTxStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+	iteratee := db.(DatabaseIteratee)
+
+	var blocks []*types.Block
+	for i := 0; i < 3; i++ {
+		action1 := types.NewAction(types.Transfer, common.Name("fromtest"), common.Name("tototest"), uint64(i), uint64(1), uint64(2000), big.NewInt(1000), nil, nil)
+		action2 := types.NewAction(types.CallContract, common.Name("tototest"), common.Name("contract"), uint64(i), uint64(1), uint64(2000), big.NewInt(0), nil, nil)
+		tx := types.NewTransaction(uint64(1), big.NewInt(1), action1, action2)
+		blocks = append(blocks, &types.Block{
+			Head: &types.Header{Number: big.NewInt(int64(i + 1)), Coinbase: "coinbase"},
+			Txs:  []*types.Transaction{tx},
+		})
+	}
+	detailTxs := []*types.DetailTx{{
+		Actions: []*types.DetailAction{{}, {InternalActions: []*types.InternalAction{
+			{Action: &types.RPCAction{From: common.Name("contract"), To: common.Name("fromtest")}},
+		}}},
+	}}
+	for _, block := range blocks {
+		WriteAccountTxEntries(db, block, detailTxs)
+	}
+
+	// fromtest sends the first action and receives an internal transfer in the second one
+	var (
+		entries []*AccountTxEntry
+		cursor  []byte
+	)
+	for {
+		page, next := ReadAccountTxEntries(iteratee, common.Name("fromtest"), cursor, 4)
+		entries = append(entries, page...)
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+	if len(entries) != 6 {
+		t.Fatalf("entries mismatch: have %d, want 6", len(entries))
+	}
+	if e := entries[0]; e.BlockNumber != 3 || e.ActionIndex != 1 || e.Roles != AccountTxInternal || e.BlockHash != blocks[2].Hash() {
+		t.Fatalf("newest entry mismatch: %+v", e)
+	}
+	if e := entries[5]; e.BlockNumber != 1 || e.ActionIndex != 0 || e.Roles != AccountTxSender || e.TxHash != blocks[0].Txs[0].Hash() {
+		t.Fatalf("oldest entry mismatch: %+v", e)
+	}
+	if page, _ := ReadAccountTxEntries(iteratee, common.Name("tototest"), nil, 10); len(page) != 6 || page[0].Roles != AccountTxSender || page[1].Roles != AccountTxRecipient {
+		t.Fatalf("tototest entries mismatch: %v", page)
+	}
+	if page, _ := ReadAccountTxEntries(iteratee, common.Name("fromtes"), nil, 10); len(page) != 0 {
+		t.Fatalf("prefix name returned entries: %v", page)
+	}
+
+	// Delete the newest block and check purge
+	DeleteAccountTxEntries(db, blocks[2], detailTxs)
+	if page, _ := ReadAccountTxEntries(iteratee, common.Name("fromtest"), nil, 10); len(page) != 4 || page[0].BlockNumber != 2 {
+		t.Fatalf("entries after delete mismatch: %v", page)
+	}
+
+	if tail := ReadAccountTxIndexTail(db); tail != nil {
+		t.Fatalf("non existent tail returned: %d", *tail)
+	}
+	WriteAccountTxIndexTail(db, 1)
+	if tail := ReadAccountTxIndexTail(db); tail == nil || *tail != 1 {
+		t.Fatalf("tail mismatch: %v", tail)
+	}
+}
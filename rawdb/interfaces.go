@@ -16,6 +16,8 @@
 
 package rawdb
 
+import "github.com/syndtr/goleveldb/leveldb/iterator"
+
 // DatabaseReader wraps the Has and Get method of a backing data store.
 type DatabaseReader interface {
 	Has(key []byte) (bool, error)
@@ -31,3 +33,8 @@ type DatabaseWriter interface {
 type DatabaseDeleter interface {
 	Delete(key []byte) error
 }
+
+// DatabaseIteratee wraps the NewIteratorWithPrefix method of a backing data store.
+type DatabaseIteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
@@ -57,6 +57,9 @@ var (
 	blockOptHash = []byte("LastOptHash")
 
 	blockSnapshotPrefix = []byte("sn")
+
+	accountTxPrefix       = []byte("A")                  // accountTxPrefix + name + 0x00 + num (uint64 big endian) + tx index + action index -> account tx entry
+	accountTxIndexTailKey = []byte("accountTxIndexTail") // accountTxIndexTailKey tracks the oldest block of the account tx index
 )
 
 // TxLookupEntry is a positional metadata to help looking up the data content of
@@ -122,6 +125,26 @@ func blockSnapshotKey(key []byte) []byte {
 	return append(blockSnapshotPrefix, key...)
 }
 
+// accountTxPrefixKey = accountTxPrefix + name + 0x00
+func accountTxPrefixKey(name common.Name) []byte {
+	return append(append(append([]byte{}, accountTxPrefix...), name.String()...), 0x00)
+}
+
+// accountTxKey = accountTxPrefix + name + 0x00 + num (uint64 big endian) + tx index (uint32 big endian) + action index (uint32 big endian)
+func accountTxKey(name common.Name, number uint64, txIndex, actionIndex uint32) []byte {
+	return append(accountTxPrefixKey(name), encodeAccountTxPosition(number, txIndex, actionIndex)...)
+}
+
+// encodeAccountTxPosition encodes the position of an action as num (uint64 big endian)
+// + tx index (uint32 big endian) + action index (uint32 big endian)
+func encodeAccountTxPosition(number uint64, txIndex, actionIndex uint32) []byte {
+	enc := make([]byte, 16)
+	binary.BigEndian.PutUint64(enc, number)
+	binary.BigEndian.PutUint32(enc[8:], txIndex)
+	binary.BigEndian.PutUint32(enc[12:], actionIndex)
+	return enc
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -19,11 +19,14 @@ package rpcapi
 import (
 	"context"
 	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/fractalplatform/fractal/accountmanager"
 	"github.com/fractalplatform/fractal/asset"
 	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
 )
 
 type RPCAccount struct {
@@ -43,6 +46,8 @@ type RPCAccount struct {
 	Suicide               bool                           `json:"suicide"`
 	Destroy               bool                           `json:"destroy"`
 	Description           string                         `json:"description"`
+	AuthorScopes          []*accountmanager.AuthorScope  `json:"authorScopes,omitempty"`
+	FrozenAssets          []uint64                       `json:"frozenAssets,omitempty"`
 }
 
 func NewRPCAccount(account *accountmanager.Account) *RPCAccount {
@@ -63,10 +68,22 @@ func NewRPCAccount(account *accountmanager.Account) *RPCAccount {
 		Suicide:               account.Suicide,
 		Destroy:               account.Destroy,
 		Description:           account.Description,
+		AuthorScopes:          account.AuthorScopes,
 	}
 	return &acctObject
 }
 
+// newRPCAccount returns the rpc account with the frozen assets of its balances.
+func newRPCAccount(am *accountmanager.AccountManager, account *accountmanager.Account) (*RPCAccount, error) {
+	frozen, err := am.GetFrozenAssets(account)
+	if err != nil {
+		return nil, err
+	}
+	acctObject := NewRPCAccount(account)
+	acctObject.FrozenAssets = frozen
+	return acctObject, nil
+}
+
 type AccountAPI struct {
 	b Backend
 }
@@ -97,7 +114,9 @@ func (api *AccountAPI) GetAccountExByID(accountID uint64) (*RPCAccount, error) {
 
 	var rpcAccountObj *RPCAccount
 	if accountObj != nil {
-		rpcAccountObj = NewRPCAccount(accountObj)
+		if rpcAccountObj, err = newRPCAccount(am, accountObj); err != nil {
+			return nil, err
+		}
 	}
 
 	return rpcAccountObj, nil
@@ -118,7 +137,9 @@ func (api *AccountAPI) GetAccountByID(accountID uint64) (*RPCAccount, error) {
 
 	var rpcAccountObj *RPCAccount
 	if accountObj != nil {
-		rpcAccountObj = NewRPCAccount(accountObj)
+		if rpcAccountObj, err = newRPCAccount(am, accountObj); err != nil {
+			return nil, err
+		}
 		balances := make([]*accountmanager.AssetBalance, 0, len(rpcAccountObj.Balances))
 		zero := big.NewInt(0)
 		for _, balance := range rpcAccountObj.Balances {
@@ -144,7 +165,9 @@ func (api *AccountAPI) GetAccountExByName(accountName common.Name) (*RPCAccount,
 
 	var rpcAccountObj *RPCAccount
 	if accountObj != nil {
-		rpcAccountObj = NewRPCAccount(accountObj)
+		if rpcAccountObj, err = newRPCAccount(am, accountObj); err != nil {
+			return nil, err
+		}
 	}
 
 	return rpcAccountObj, nil
@@ -164,7 +187,9 @@ func (api *AccountAPI) GetAccountByName(accountName common.Name) (*RPCAccount, e
 
 	var rpcAccountObj *RPCAccount
 	if accountObj != nil {
-		rpcAccountObj = NewRPCAccount(accountObj)
+		if rpcAccountObj, err = newRPCAccount(am, accountObj); err != nil {
+			return nil, err
+		}
 		balances := make([]*accountmanager.AssetBalance, 0, len(rpcAccountObj.Balances))
 		zero := big.NewInt(0)
 		for _, balance := range rpcAccountObj.Balances {
@@ -265,3 +290,481 @@ func (api *AccountAPI) GetSnapshotTime(ctx context.Context, m uint64, time uint6
 	}
 	return am.GetSnapshotTime(m, time)
 }
+
+const (
+	defaultAssetHolderPageSize = 100
+	maxAssetHolderPageSize     = 1000
+)
+
+// AssetHolderPage is a page of the holders of an asset sorted by balance in
+// descending order and by name.
+// Next is the cursor of the following page and zero on the last page.
+type AssetHolderPage struct {
+	Holders []*accountmanager.AssetHolder `json:"holders"`
+	Total   uint64                        `json:"total"`
+	Next    uint64                        `json:"next"`
+}
+
+// pageSize returns the page size of the limit, default if it's not set.
+func pageSize(limit *uint64) uint64 {
+	size := uint64(defaultAssetHolderPageSize)
+	if limit != nil && *limit > 0 {
+		size = maxAssetHolderPageSize
+		if *limit < maxAssetHolderPageSize {
+			size = *limit
+		}
+	}
+	return size
+}
+
+func newAssetHolderPage(holders []*accountmanager.AssetHolder, total uint64, start uint64, size uint64) *AssetHolderPage {
+	page := &AssetHolderPage{Holders: holders, Total: total}
+	if start+size < total {
+		page.Next = start + size
+	}
+	return page
+}
+
+// GetAssetHolders returns the holders of the asset sorted by balance, starting at the cursor
+func (api *AccountAPI) GetAssetHolders(assetID uint64, cursor *uint64, limit *uint64) (*AssetHolderPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	size := pageSize(limit)
+	holders, total, err := am.GetAssetHolders(assetID, start, size)
+	if err != nil {
+		return nil, err
+	}
+	return newAssetHolderPage(holders, total, start, size), nil
+}
+
+// GetAssetHoldersByTime returns the holders of the asset at the snapshot time sorted by balance, starting at the cursor
+func (api *AccountAPI) GetAssetHoldersByTime(assetID uint64, time uint64, cursor *uint64, limit *uint64) (*AssetHolderPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	size := pageSize(limit)
+	holders, total, err := am.GetAssetHoldersByTime(assetID, time, start, size)
+	if err != nil {
+		return nil, err
+	}
+	return newAssetHolderPage(holders, total, start, size), nil
+}
+
+// RPCPendingAuthorChange is a queued author change, Author or Delay is set
+// depending on the action type.
+type RPCPendingAuthorChange struct {
+	ID             uint64                              `json:"id"`
+	Account        common.Name                         `json:"account"`
+	Type           types.ActionType                    `json:"type"`
+	Number         uint64                              `json:"number"`
+	ActivateNumber uint64                              `json:"activateNumber"`
+	Author         *accountmanager.AccountAuthorAction `json:"author,omitempty"`
+	Delay          *uint64                             `json:"delay,omitempty"`
+}
+
+// GetAuthorDelay returns the number of blocks author changes of the account are delayed
+func (api *AccountAPI) GetAuthorDelay(accountName common.Name) (uint64, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return 0, err
+	}
+	return am.GetAuthorDelay(accountName)
+}
+
+// GetPendingAuthorChanges returns the queued author changes of the account
+func (api *AccountAPI) GetPendingAuthorChanges(accountName common.Name) ([]*RPCPendingAuthorChange, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := am.GetPendingAuthorChanges(accountName)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*RPCPendingAuthorChange, 0, len(changes))
+	for _, change := range changes {
+		c := &RPCPendingAuthorChange{
+			ID:             change.ID,
+			Account:        change.Account,
+			Type:           change.Type,
+			Number:         change.Number,
+			ActivateNumber: change.ActivateNumber,
+		}
+		switch change.Type {
+		case types.UpdateAccountAuthor:
+			c.Author = new(accountmanager.AccountAuthorAction)
+			if err := rlp.DecodeBytes(change.Payload, c.Author); err != nil {
+				return nil, err
+			}
+		case types.SetAccountAuthorDelay:
+			var delay accountmanager.AuthorDelayAction
+			if err := rlp.DecodeBytes(change.Payload, &delay); err != nil {
+				return nil, err
+			}
+			c.Delay = &delay.Delay
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// RPCProposal is a pending proposal of an account with its approved weight.
+type RPCProposal struct {
+	*accountmanager.Proposal
+	Weight    uint64 `json:"weight"`
+	Threshold uint64 `json:"threshold"`
+}
+
+func (api *AccountAPI) newRPCProposal(am *accountmanager.AccountManager, proposal *accountmanager.Proposal) (*RPCProposal, error) {
+	weight, threshold, err := am.GetProposalWeight(proposal)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCProposal{Proposal: proposal, Weight: weight, Threshold: threshold}, nil
+}
+
+// GetProposals returns the pending proposals of the account
+func (api *AccountAPI) GetProposals(accountName common.Name) ([]*RPCProposal, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	proposals, err := am.GetProposals(accountName, api.b.CurrentBlock().NumberU64()+1)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*RPCProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		p, err := api.newRPCProposal(am, proposal)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+// GetProposal returns a pending proposal by id
+func (api *AccountAPI) GetProposal(id uint64) (*RPCProposal, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := am.GetProposal(id)
+	if err != nil || proposal == nil {
+		return nil, err
+	}
+	return api.newRPCProposal(am, proposal)
+}
+
+// RPCVestingSchedule is a vesting schedule with its amount locked at the next block.
+type RPCVestingSchedule struct {
+	*accountmanager.VestingSchedule
+	Locked *big.Int `json:"locked"`
+}
+
+// GetVestingSchedules returns the vesting schedules of the account asset
+func (api *AccountAPI) GetVestingSchedules(accountName common.Name, assetID uint64) ([]*RPCVestingSchedule, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := am.GetVestingSchedules(accountName, assetID)
+	if err != nil {
+		return nil, err
+	}
+	number := api.b.CurrentBlock().NumberU64() + 1
+	result := make([]*RPCVestingSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, &RPCVestingSchedule{VestingSchedule: schedule, Locked: schedule.Locked(number)})
+	}
+	return result, nil
+}
+
+// GetLockedBalance returns the balance of the account asset locked at the next block
+func (api *AccountAPI) GetLockedBalance(accountName common.Name, assetID uint64) (*big.Int, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetLockedBalance(accountName, assetID, api.b.CurrentBlock().NumberU64()+1)
+}
+
+// GetAllowance returns the amount of the asset the spender can transfer from the owner account
+func (api *AccountAPI) GetAllowance(owner common.Name, spender common.Name, assetID uint64) (*big.Int, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAllowance(owner, spender, assetID)
+}
+
+// GetHTLC returns the hash time-locked value by id
+func (api *AccountAPI) GetHTLC(id uint64) (*accountmanager.HTLC, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetHTLC(id)
+}
+
+// HTLCPage is a page of the hash time-locked values locked under a hash in lock order.
+// Next is the cursor of the following page and zero on the last page.
+type HTLCPage struct {
+	HTLCs []*accountmanager.HTLC `json:"htlcs"`
+	Total uint64                 `json:"total"`
+	Next  uint64                 `json:"next"`
+}
+
+// GetHTLCsByHash returns the hash time-locked values locked under the hash, starting at the cursor
+func (api *AccountAPI) GetHTLCsByHash(hashLock common.Hash, cursor *uint64, limit *uint64) (*HTLCPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	size := pageSize(limit)
+	htlcs, total, err := am.GetHTLCsByHash(hashLock, start, size)
+	if err != nil {
+		return nil, err
+	}
+	page := &HTLCPage{HTLCs: htlcs, Total: total}
+	if start+size < total {
+		page.Next = start + size
+	}
+	return page, nil
+}
+
+// GetDividend returns the dividend by id
+func (api *AccountAPI) GetDividend(id uint64) (*accountmanager.Dividend, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetDividend(id)
+}
+
+// RPCDividendShare is the share of an account of a dividend.
+type RPCDividendShare struct {
+	Share   *big.Int `json:"share"`
+	Claimed bool     `json:"claimed"`
+}
+
+// GetDividendShare returns the share of the account of the dividend
+func (api *AccountAPI) GetDividendShare(id uint64, accountName common.Name) (*RPCDividendShare, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	share, claimed, err := am.GetDividendShare(id, accountName)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCDividendShare{Share: share, Claimed: claimed}, nil
+}
+
+// IsSwapNonceUsed returns whether the swap order nonce of the maker is used
+func (api *AccountAPI) IsSwapNonceUsed(maker common.Name, nonce uint64) (bool, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return false, err
+	}
+	return am.IsSwapNonceUsed(maker, nonce)
+}
+
+// GetNFTCollection returns the non-fungible token collection by id
+func (api *AccountAPI) GetNFTCollection(collectionID uint64) (*asset.NFTCollection, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetNFTCollection(collectionID)
+}
+
+// GetNFTCollectionByName returns the non-fungible token collection by name
+func (api *AccountAPI) GetNFTCollectionByName(name string) (*asset.NFTCollection, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetNFTCollectionByName(name)
+}
+
+// GetNFToken returns a non-fungible token of the collection
+func (api *AccountAPI) GetNFToken(collectionID uint64, tokenID uint64) (*asset.NFToken, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetNFToken(collectionID, tokenID)
+}
+
+// NFTokenPage is a page of non-fungible tokens, Next is the cursor of the
+// following page and zero on the last page. Total is the number of tokens
+// of the owner or the supply of the collection.
+type NFTokenPage struct {
+	Tokens []*asset.NFToken `json:"tokens"`
+	Total  uint64           `json:"total"`
+	Next   uint64           `json:"next"`
+}
+
+// GetNFTokensByOwner returns the non-fungible tokens of the account, starting at the cursor
+func (api *AccountAPI) GetNFTokensByOwner(owner common.Name, cursor *uint64, limit *uint64) (*NFTokenPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	size := pageSize(limit)
+	tokens, total, err := am.GetNFTokensByOwner(owner, start, size)
+	if err != nil {
+		return nil, err
+	}
+	page := &NFTokenPage{Tokens: tokens, Total: total}
+	if start+size < total {
+		page.Next = start + size
+	}
+	return page, nil
+}
+
+// GetNFTokensByCollection returns the non-fungible tokens of the collection by token id, starting at the cursor
+func (api *AccountAPI) GetNFTokensByCollection(collectionID uint64, cursor *uint64, limit *uint64) (*NFTokenPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	collection, err := am.GetNFTCollection(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	tokens, next, err := am.GetNFTokensByCollection(collectionID, start, pageSize(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &NFTokenPage{Tokens: tokens, Total: collection.Supply, Next: next}, nil
+}
+
+// AssetEventPage is a page of the history of an asset, oldest first. Next is
+// the cursor of the following page and zero on the last page.
+type AssetEventPage struct {
+	Events []*asset.AssetEvent `json:"events"`
+	Next   uint64              `json:"next"`
+}
+
+// GetAssetHistory returns the issue, increase, destroy and update events of the asset, starting at the cursor
+func (api *AccountAPI) GetAssetHistory(assetID uint64, cursor *uint64, limit *uint64) (*AssetEventPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	events, next, err := am.GetAssetEvents(assetID, start, pageSize(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &AssetEventPage{Events: events, Next: next}, nil
+}
+
+// AssetFilter selects the assets listed by GetAssets, empty fields match any asset.
+type AssetFilter struct {
+	Owner   common.Name `json:"owner"`
+	Founder common.Name `json:"founder"`
+	Prefix  string      `json:"prefix"`
+}
+
+// AssetPage is a page of assets, Next is the cursor of the following page, zero if there are none.
+// A page may hold less assets than the limit while Next is not zero, as a call scans a bounded number of assets.
+type AssetPage struct {
+	Assets []*asset.AssetObject `json:"assets"`
+	Next   uint64               `json:"next"`
+}
+
+// GetAssets returns the assets matched by the filter in id order, starting at the cursor
+func (api *AccountAPI) GetAssets(filter *AssetFilter, cursor *uint64, limit *uint64) (*AssetPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	var match func(*asset.AssetObject) bool
+	if filter != nil {
+		match = func(ao *asset.AssetObject) bool {
+			return (filter.Owner == "" || ao.Owner == filter.Owner) &&
+				(filter.Founder == "" || ao.Founder == filter.Founder) &&
+				strings.HasPrefix(ao.AssetName, filter.Prefix)
+		}
+	}
+	assets, next, err := am.GetAssetInfos(start, pageSize(limit), match)
+	if err != nil {
+		return nil, err
+	}
+	return &AssetPage{Assets: assets, Next: next}, nil
+}
+
+// GetAssetMetadata returns the key/value metadata of the asset
+func (api *AccountAPI) GetAssetMetadata(assetID uint64) ([]*asset.Metadata, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	return am.GetAssetMetadata(assetID)
+}
+
+// SubAssetPage is a page of the sub-assets of an asset, Next is the cursor of the following page, zero if there are none.
+type SubAssetPage struct {
+	Policy uint64               `json:"policy"`
+	Assets []*asset.AssetObject `json:"assets"`
+	Next   uint64               `json:"next"`
+}
+
+// GetSubAssets returns the sub-asset policy of the asset and its sub-assets, starting at the cursor
+func (api *AccountAPI) GetSubAssets(ctx context.Context, assetName string, cursor *uint64, limit *uint64) (*SubAssetPage, error) {
+	am, err := api.b.GetAccountManager()
+	if err != nil {
+		return nil, err
+	}
+	ao, err := am.GetAssetInfoByName(assetName)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := am.GetSubAssetPolicy(ao.AssetID)
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if cursor != nil {
+		start = *cursor
+	}
+	assets, next, err := am.GetSubAssets(ao.AssetID, start, pageSize(limit))
+	if err != nil {
+		return nil, err
+	}
+	return &SubAssetPage{Policy: policy, Assets: assets, Next: next}, nil
+}
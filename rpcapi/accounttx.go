@@ -0,0 +1,110 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rawdb"
+)
+
+const (
+	defaultAccountTxPageSize = 100
+	maxAccountTxPageSize     = 1000
+)
+
+var accountTxRoleNames = []struct {
+	role uint64
+	name string
+}{
+	{rawdb.AccountTxSender, "sender"},
+	{rawdb.AccountTxRecipient, "recipient"},
+	{rawdb.AccountTxPayer, "payer"},
+	{rawdb.AccountTxInternal, "internal"},
+}
+
+// RPCAccountTx is an action an account is involved in.
+type RPCAccountTx struct {
+	BlockHash   common.Hash `json:"blockHash"`
+	BlockNumber uint64      `json:"blockNumber"`
+	TxHash      common.Hash `json:"txHash"`
+	TxIndex     uint32      `json:"transactionIndex"`
+	ActionIndex uint32      `json:"actionIndex"`
+	Roles       []string    `json:"roles"`
+}
+
+// AccountTxPage is a page of the actions of an account, newest first. Next is
+// the cursor of the following page and empty on the last page, blocks below
+// IndexTail are not indexed yet.
+type AccountTxPage struct {
+	Txs       []*RPCAccountTx `json:"txs"`
+	Next      hexutil.Bytes   `json:"next"`
+	IndexTail uint64          `json:"indexTail"`
+}
+
+// GetAccountTxs returns the actions sent, received, paid or internally touched
+// by the account from the account tx index, starting below the cursor.
+func (s *PublicBlockChainAPI) GetAccountTxs(ctx context.Context, acctName common.Name, cursor *hexutil.Bytes, limit *uint64) (*AccountTxPage, error) {
+	size := defaultAccountTxPageSize
+	if limit != nil && *limit > 0 {
+		size = maxAccountTxPageSize
+		if *limit < maxAccountTxPageSize {
+			size = int(*limit)
+		}
+	}
+	var start []byte
+	if cursor != nil {
+		start = *cursor
+	}
+
+	tail, err := s.b.AccountTxIndexTail()
+	if err != nil {
+		return nil, err
+	}
+	entries, next, err := s.b.GetAccountTxEntries(ctx, acctName, start, size)
+	if err != nil {
+		return nil, err
+	}
+	page := &AccountTxPage{Txs: make([]*RPCAccountTx, 0, len(entries)), Next: next, IndexTail: tail}
+	for _, entry := range entries {
+		tx := &RPCAccountTx{
+			BlockHash:   entry.BlockHash,
+			BlockNumber: entry.BlockNumber,
+			TxHash:      entry.TxHash,
+			TxIndex:     entry.TxIndex,
+			ActionIndex: entry.ActionIndex,
+		}
+		for _, r := range accountTxRoleNames {
+			if entry.Roles&r.role != 0 {
+				tx.Roles = append(tx.Roles, r.name)
+			}
+		}
+		page.Txs = append(page.Txs, tx)
+	}
+	return page, nil
+}
+
+// BackfillAccountTxIndex starts indexing the blocks written before the account
+// tx index was enabled in the background, it returns the current index tail.
+func (s *PrivateBlockChainAPI) BackfillAccountTxIndex() (uint64, error) {
+	if err := s.b.BackfillAccountTxIndex(); err != nil {
+		return 0, err
+	}
+	return s.b.AccountTxIndexTail()
+}
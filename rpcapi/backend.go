@@ -28,8 +28,11 @@ import (
 	"github.com/fractalplatform/fractal/consensus"
 	"github.com/fractalplatform/fractal/debug"
 	"github.com/fractalplatform/fractal/feemanager"
+	"github.com/fractalplatform/fractal/keystore"
 	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/processor"
 	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/rawdb"
 	"github.com/fractalplatform/fractal/rpc"
 	"github.com/fractalplatform/fractal/rpcapi/filters"
 	"github.com/fractalplatform/fractal/state"
@@ -56,9 +59,14 @@ type Backend interface {
 	GetDetailTxsLog(ctx context.Context, hash common.Hash) ([]*types.DetailTx, error)
 	GetBlockDetailLog(ctx context.Context, blockNr rpc.BlockNumber) *types.BlockAndResult
 	GetTd(blockHash common.Hash) *big.Int
+	StateAtBlock(ctx context.Context, block *types.Block) (*state.StateDB, *types.Header, error)
+	Processor() processor.Processor
 	GetEVM(ctx context.Context, account *accountmanager.AccountManager, state *state.StateDB, from common.Name, to common.Name, assetID uint64, gasPrice *big.Int, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error)
 	GetDetailTxByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) []*types.DetailTx
 	GetTxsByFilter(ctx context.Context, filterFn func(common.Name) bool, blockNr, lookbackNum uint64) *types.AccountTxs
+	GetAccountTxEntries(ctx context.Context, name common.Name, cursor []byte, limit int) ([]*rawdb.AccountTxEntry, []byte, error)
+	AccountTxIndexTail() (uint64, error)
+	BackfillAccountTxIndex() error
 	GetBadBlocks(ctx context.Context) ([]*types.Block, error)
 	ForkStatus(statedb *state.StateDB) (*blockchain.ForkConfig, blockchain.ForkInfo, error)
 	SetStatePruning(enable bool) (bool, uint64)
@@ -72,6 +80,9 @@ type Backend interface {
 	//Account API
 	GetAccountManager() (*accountmanager.AccountManager, error)
 
+	//keystore
+	KeyStore() *keystore.KeyStore
+
 	//fee manager
 	GetFeeManager() (*feemanager.FeeManager, error)
 	GetFeeManagerByTime(time uint64) (*feemanager.FeeManager, error)
@@ -136,6 +147,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Service:   NewFeeAPI(apiBackend),
 			Public:    true,
 		},
+		{
+			Namespace: "keystore",
+			Version:   "1.0",
+			Service:   NewPrivateKeyStoreAPI(apiBackend),
+		},
 		{
 			Namespace: "p2p",
 			Version:   "1.0",
@@ -146,6 +162,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   debug.Handler,
 		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPrivateDebugAPI(apiBackend),
+		},
 	}
 	return append(apis, apiBackend.APIs()...)
 }
@@ -0,0 +1,80 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/keystore"
+)
+
+// PrivateKeyStoreAPI offers an API to manage the encrypted keys of the node.
+type PrivateKeyStoreAPI struct {
+	b Backend
+}
+
+// NewPrivateKeyStoreAPI creates a new keystore service.
+func NewPrivateKeyStoreAPI(b Backend) *PrivateKeyStoreAPI {
+	return &PrivateKeyStoreAPI{b}
+}
+
+// NewKey generates a new key and stores it encrypted with the passphrase.
+func (api *PrivateKeyStoreAPI) NewKey(passphrase string) (common.PubKey, error) {
+	info, err := api.b.KeyStore().NewKey(passphrase)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	return info.PubKey, nil
+}
+
+// ImportRawKey stores the hex encoded private key encrypted with the passphrase.
+func (api *PrivateKeyStoreAPI) ImportRawKey(privKey string, passphrase string) (common.PubKey, error) {
+	priv, err := crypto.HexToECDSA(privKey)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	info, err := api.b.KeyStore().ImportECDSA(priv, passphrase)
+	if err != nil {
+		return common.PubKey{}, err
+	}
+	return info.PubKey, nil
+}
+
+// ListKeys returns all keys of the keystore.
+func (api *PrivateKeyStoreAPI) ListKeys() ([]keystore.KeyInfo, error) {
+	return api.b.KeyStore().Keys()
+}
+
+// Unlock decrypts the key and keeps it in memory.
+func (api *PrivateKeyStoreAPI) Unlock(pubKey common.PubKey, passphrase string) error {
+	return api.b.KeyStore().Unlock(pubKey, passphrase)
+}
+
+// Lock removes the decrypted key from memory.
+func (api *PrivateKeyStoreAPI) Lock(pubKey common.PubKey) bool {
+	api.b.KeyStore().Lock(pubKey)
+	return true
+}
+
+// SignHash signs the hash, the unlocked key is used if passphrase is empty.
+func (api *PrivateKeyStoreAPI) SignHash(pubKey common.PubKey, hash hexutil.Bytes, passphrase string) (hexutil.Bytes, error) {
+	if passphrase == "" {
+		return api.b.KeyStore().SignHash(pubKey, hash)
+	}
+	return api.b.KeyStore().SignHashWithPassphrase(pubKey, passphrase, hash)
+}
@@ -0,0 +1,156 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// OverrideAuthor is an author of an overridden account. The owner is
+// a public key, an address or an account name.
+type OverrideAuthor struct {
+	Owner  string `json:"owner"`
+	Weight uint64 `json:"weight"`
+}
+
+// OverrideAccount specifies the fields of an account replaced before a call
+// is executed, fields left empty are kept. An empty code clears the code.
+type OverrideAccount struct {
+	Nonce     *uint64                     `json:"nonce"`
+	Code      *hexutil.Bytes              `json:"code"`
+	Balances  map[uint64]*big.Int         `json:"balances"`
+	Storage   map[common.Hash]common.Hash `json:"storage"`
+	Authors   []*OverrideAuthor           `json:"authors"`
+	Threshold *uint64                     `json:"threshold"`
+}
+
+// StateOverride is the set of accounts overridden before a call is executed.
+type StateOverride map[common.Name]OverrideAccount
+
+// Apply overrides the accounts in the given state.
+func (diff *StateOverride) Apply(am *accountmanager.AccountManager, statedb *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for name, override := range *diff {
+		acct, err := am.GetAccountByName(name)
+		if err != nil {
+			return err
+		}
+		if acct == nil {
+			return fmt.Errorf("override account %s not exist", name)
+		}
+		if override.Nonce != nil {
+			acct.SetNonce(*override.Nonce)
+		}
+		if override.Code != nil {
+			if len(*override.Code) == 0 {
+				// empty code clears the code of the account
+				acct.Code = nil
+				acct.CodeHash = crypto.Keccak256Hash(nil)
+				acct.CodeSize = 0
+			} else if err := acct.SetCode(*override.Code); err != nil {
+				return err
+			}
+		}
+		for assetID, balance := range override.Balances {
+			if _, err := am.GetAssetInfoByID(assetID); err != nil {
+				return fmt.Errorf("override balance of asset %d: %v", assetID, err)
+			}
+			if balance == nil || balance.Sign() < 0 {
+				return fmt.Errorf("override balance of asset %d invalid", assetID)
+			}
+			if err := acct.SetBalance(assetID, new(big.Int).Set(balance)); err != nil {
+				if _, err := acct.AddBalanceByID(assetID, new(big.Int).Set(balance)); err != nil {
+					return err
+				}
+			}
+		}
+		if override.Authors != nil {
+			authors := make([]*common.Author, 0, len(override.Authors))
+			for _, author := range override.Authors {
+				authors = append(authors, common.NewAuthor(parseOwner(author.Owner), author.Weight))
+			}
+			acct.Authors = authors
+		}
+		if override.Threshold != nil {
+			acct.SetThreshold(*override.Threshold)
+		}
+		if override.Authors != nil || override.Threshold != nil {
+			acct.SetAuthorVersion()
+		}
+		if err := am.SetAccount(acct); err != nil {
+			return err
+		}
+		for key, value := range override.Storage {
+			statedb.SetState(name.String(), key, value)
+		}
+	}
+	return nil
+}
+
+// BlockOverrides specifies the header fields replaced before a call is
+// executed, fields left empty are kept.
+type BlockOverrides struct {
+	Number     *big.Int     `json:"number"`
+	Time       *big.Int     `json:"time"`
+	GasLimit   *uint64      `json:"gasLimit"`
+	Coinbase   *common.Name `json:"coinbase"`
+	Difficulty *big.Int     `json:"difficulty"`
+}
+
+// Apply returns a copy of the header with the overridden fields.
+func (diff *BlockOverrides) Apply(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	header = types.CopyHeader(header)
+	if diff.Number != nil {
+		header.Number = new(big.Int).Set(diff.Number)
+	}
+	if diff.Time != nil {
+		header.Time = new(big.Int).Set(diff.Time)
+	}
+	if diff.GasLimit != nil {
+		header.GasLimit = *diff.GasLimit
+	}
+	if diff.Coinbase != nil {
+		header.Coinbase = *diff.Coinbase
+	}
+	if diff.Difficulty != nil {
+		header.Difficulty = new(big.Int).Set(diff.Difficulty)
+	}
+	return header
+}
+
+func parseOwner(owner string) common.Owner {
+	if common.IsHexPubKey(owner) {
+		return common.HexToPubKey(owner)
+	}
+	if common.IsHexAddress(owner) {
+		return common.HexToAddress(owner)
+	}
+	return common.Name(owner)
+}
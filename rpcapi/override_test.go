@@ -0,0 +1,200 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/asset"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func newOverrideState(t *testing.T) (*accountmanager.AccountManager, *state.StateDB, uint64) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))
+	if err := am.CreateAccount(common.Name("fractal.founder"), common.Name("overrideacct"), common.Name(""), 0, 0, pubKey, ""); err != nil {
+		t.Fatal(err)
+	}
+	assetID, err := asset.NewAsset(statedb).IssueAsset("overrideasset", 0, 0, "oa", big.NewInt(1000), 0, common.Name("overrideacct"), common.Name("overrideacct"), big.NewInt(1000), common.Name(""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return am, statedb, assetID
+}
+
+func TestStateOverride(t *testing.T) {
+	name := common.Name("overrideacct")
+	nonce := uint64(7)
+	code := hexutil.Bytes{0x60, 0x00}
+	empty := hexutil.Bytes{}
+	key, value := common.BytesToHash([]byte{1}), common.BytesToHash([]byte{2})
+
+	tests := []struct {
+		name     string
+		code     []byte // code set before the override
+		override func(assetID uint64) OverrideAccount
+		check    func(t *testing.T, am *accountmanager.AccountManager, statedb *state.StateDB, assetID uint64)
+		fail     bool
+	}{
+		{
+			name: "balance",
+			override: func(assetID uint64) OverrideAccount {
+				return OverrideAccount{Balances: map[uint64]*big.Int{assetID: big.NewInt(500)}}
+			},
+			check: func(t *testing.T, am *accountmanager.AccountManager, statedb *state.StateDB, assetID uint64) {
+				if balance, err := am.GetAccountBalanceByID(name, assetID, 0); err != nil || balance.Cmp(big.NewInt(500)) != 0 {
+					t.Fatalf("balance mismatch: have %v %v, want 500", balance, err)
+				}
+			},
+		},
+		{
+			name: "negative balance",
+			override: func(assetID uint64) OverrideAccount {
+				return OverrideAccount{Balances: map[uint64]*big.Int{assetID: big.NewInt(-1)}}
+			},
+			fail: true,
+		},
+		{
+			name: "unknown asset balance",
+			override: func(assetID uint64) OverrideAccount {
+				return OverrideAccount{Balances: map[uint64]*big.Int{assetID + 1: big.NewInt(1)}}
+			},
+			fail: true,
+		},
+		{
+			name:     "nonce",
+			override: func(uint64) OverrideAccount { return OverrideAccount{Nonce: &nonce} },
+			check: func(t *testing.T, am *accountmanager.AccountManager, statedb *state.StateDB, assetID uint64) {
+				if have, _ := am.GetNonce(name); have != nonce {
+					t.Fatalf("nonce mismatch: have %d, want %d", have, nonce)
+				}
+			},
+		},
+		{
+			name:     "code",
+			override: func(uint64) OverrideAccount { return OverrideAccount{Code: &code} },
+			check: func(t *testing.T, am *accountmanager.AccountManager, statedb *state.StateDB, assetID uint64) {
+				if have, _ := am.GetCode(name); !bytes.Equal(have, code) {
+					t.Fatalf("code mismatch: have %x, want %x", have, code)
+				}
+			},
+		},
+		{
+			name:     "empty code",
+			code:     code,
+			override: func(uint64) OverrideAccount { return OverrideAccount{Code: &empty} },
+			check: func(t *testing.T, am *accountmanager.AccountManager, statedb *state.StateDB, assetID uint64) {
+				if size, _ := am.GetCodeSize(name); size != 0 {
+					t.Fatalf("code not cleared: size %d", size)
+				}
+				acct, _ := am.GetAccountByName(name)
+				if hash, _ := acct.GetCodeHash(); hash != crypto.Keccak256Hash(nil) {
+					t.Fatalf("code hash not cleared: %x", hash)
+				}
+			},
+		},
+		{
+			name:     "storage",
+			override: func(uint64) OverrideAccount { return OverrideAccount{Storage: map[common.Hash]common.Hash{key: value}} },
+			check: func(t *testing.T, am *accountmanager.AccountManager, statedb *state.StateDB, assetID uint64) {
+				if have := statedb.GetState(name.String(), key); have != value {
+					t.Fatalf("storage mismatch: have %x, want %x", have, value)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			am, statedb, assetID := newOverrideState(t)
+			if test.code != nil {
+				if _, err := am.SetCode(name, test.code); err != nil {
+					t.Fatal(err)
+				}
+			}
+			override := &StateOverride{name: test.override(assetID)}
+			err := override.Apply(am, statedb)
+			if test.fail {
+				if err == nil {
+					t.Fatal("invalid override applied")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, am, statedb, assetID)
+		})
+	}
+
+	am, statedb, _ := newOverrideState(t)
+	if err := (&StateOverride{common.Name("overridenone"): OverrideAccount{Nonce: &nonce}}).Apply(am, statedb); err == nil {
+		t.Fatal("override of unknown account applied")
+	}
+	var none *StateOverride
+	if err := none.Apply(am, statedb); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockOverrides(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(10), Time: big.NewInt(100), GasLimit: 1000, Coinbase: common.Name("miner"), Difficulty: big.NewInt(1)}
+	gasLimit := uint64(2000)
+	coinbase := common.Name("othermine")
+
+	tests := []struct {
+		name     string
+		override *BlockOverrides
+		want     types.Header
+	}{
+		{"none", nil, *header},
+		{"empty", &BlockOverrides{}, *header},
+		{"number", &BlockOverrides{Number: big.NewInt(20)}, types.Header{Number: big.NewInt(20), Time: big.NewInt(100), GasLimit: 1000, Coinbase: "miner", Difficulty: big.NewInt(1)}},
+		{"time", &BlockOverrides{Time: big.NewInt(200)}, types.Header{Number: big.NewInt(10), Time: big.NewInt(200), GasLimit: 1000, Coinbase: "miner", Difficulty: big.NewInt(1)}},
+		{"gas limit", &BlockOverrides{GasLimit: &gasLimit}, types.Header{Number: big.NewInt(10), Time: big.NewInt(100), GasLimit: 2000, Coinbase: "miner", Difficulty: big.NewInt(1)}},
+		{"coinbase", &BlockOverrides{Coinbase: &coinbase}, types.Header{Number: big.NewInt(10), Time: big.NewInt(100), GasLimit: 1000, Coinbase: coinbase, Difficulty: big.NewInt(1)}},
+		{"difficulty", &BlockOverrides{Difficulty: big.NewInt(3)}, types.Header{Number: big.NewInt(10), Time: big.NewInt(100), GasLimit: 1000, Coinbase: "miner", Difficulty: big.NewInt(3)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			have := test.override.Apply(header)
+			if have.Number.Cmp(test.want.Number) != 0 || have.Time.Cmp(test.want.Time) != 0 || have.GasLimit != test.want.GasLimit ||
+				have.Coinbase != test.want.Coinbase || have.Difficulty.Cmp(test.want.Difficulty) != 0 {
+				t.Fatalf("header mismatch: have %+v, want %+v", have, test.want)
+			}
+			// the original header is kept
+			if header.Number.Int64() != 10 || header.Time.Int64() != 100 || header.GasLimit != 1000 || header.Coinbase != "miner" || header.Difficulty.Int64() != 1 {
+				t.Fatalf("header changed: %+v", header)
+			}
+		})
+	}
+}
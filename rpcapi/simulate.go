@@ -0,0 +1,130 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// SimulateActionArgs represents an unsigned action of a simulated transaction.
+// The current nonce of the sender is used when nonce is empty.
+type SimulateActionArgs struct {
+	ActionType    types.ActionType `json:"actionType"`
+	From          common.Name      `json:"from"`
+	To            common.Name      `json:"to"`
+	Nonce         *uint64          `json:"nonce"`
+	AssetID       uint64           `json:"assetId"`
+	Gas           uint64           `json:"gas"`
+	Value         *big.Int         `json:"value"`
+	Data          hexutil.Bytes    `json:"data"`
+	Remark        hexutil.Bytes    `json:"remark"`
+	Payer         common.Name      `json:"payer"`
+	PayerGasPrice *big.Int         `json:"payerGasPrice"`
+}
+
+// SimulateArgs represents an unsigned transaction to simulate.
+type SimulateArgs struct {
+	GasAssetID uint64               `json:"gasAssetId"`
+	GasPrice   *big.Int             `json:"gasPrice"`
+	Actions    []SimulateActionArgs `json:"actions"`
+}
+
+// SimulateResult is the outcome of a simulated transaction.
+type SimulateResult struct {
+	Receipt  *types.RPCReceiptWithPayer `json:"receipt"`
+	DetailTx *types.DetailTx            `json:"detailTx"`
+}
+
+// SimulateTransaction executes the unsigned transaction on the state for the
+// given block number and returns the receipt, gas distribution, internal
+// actions and logs it would produce. The optional overrides replace account
+// fields and header fields before execution.
+func (s *PublicBlockChainAPI) SimulateTransaction(ctx context.Context, args SimulateArgs, blockNr rpc.BlockNumber, overrides *StateOverride, blockOverrides *BlockOverrides) (*SimulateResult, error) {
+	if len(args.Actions) == 0 {
+		return nil, errors.New("transaction has no action")
+	}
+	statedb, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	account, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(account, statedb); err != nil {
+		return nil, err
+	}
+	header = blockOverrides.Apply(header)
+
+	nonces := make(map[common.Name]uint64)
+	actions := make([]*types.Action, 0, len(args.Actions))
+	for _, a := range args.Actions {
+		var nonce uint64
+		if a.Nonce != nil {
+			nonce = *a.Nonce
+		} else {
+			if _, ok := nonces[a.From]; !ok {
+				if nonces[a.From], err = account.GetNonce(a.From); err != nil {
+					return nil, err
+				}
+			}
+			nonce = nonces[a.From]
+		}
+		nonces[a.From] = nonce + 1
+
+		action := types.NewAction(a.ActionType, a.From, a.To, nonce, a.AssetID, a.Gas, a.Value, a.Data, a.Remark)
+		if len(a.Payer) != 0 {
+			payerGasPrice := a.PayerGasPrice
+			if payerGasPrice == nil {
+				payerGasPrice = new(big.Int)
+			}
+			action.SetFeePayer(&types.FeePayer{GasPrice: payerGasPrice, Payer: a.Payer, Sign: &types.Signature{SignData: make([]*types.SignData, 0)}})
+		}
+		actions = append(actions, action)
+	}
+	gasPrice := args.GasPrice
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	tx := types.NewTransaction(args.GasAssetID, gasPrice, actions...)
+
+	var (
+		usedGas = new(uint64)
+		gp      = new(common.GasPool).AddGas(math.MaxUint64)
+		cfg     = vm.Config{ContractLogFlag: true, EndTime: time.Now().Add(5 * time.Second)}
+	)
+	statedb.Prepare(tx.Hash(), header.Hash(), 0)
+	receipt, _, err := s.b.Processor().SimulateTransaction(nil, gp, statedb, header, tx, usedGas, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SimulateResult{
+		Receipt:  receipt.NewRPCReceiptWithPayer(header.Hash(), header.Number.Uint64(), 0, tx),
+		DetailTx: receipt.GetInternalTxsLog(),
+	}, nil
+}
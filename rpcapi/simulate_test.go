@@ -0,0 +1,141 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/blockchain"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/consensus"
+	"github.com/fractalplatform/fractal/consensus/dpos"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/processor"
+	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/state"
+	"github.com/fractalplatform/fractal/txpool"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// simulateBackend serves the state of a genesis chain to SimulateTransaction,
+// under a header of the given fork.
+type simulateBackend struct {
+	Backend
+	chain     *blockchain.BlockChain
+	processor processor.Processor
+	forkID    uint64
+}
+
+func (b *simulateBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	statedb, err := b.chain.State()
+	header := types.CopyHeader(b.chain.CurrentHeader())
+	header.WithForkID(b.forkID, b.forkID)
+	return statedb, header, err
+}
+
+func (b *simulateBackend) Processor() processor.Processor {
+	return b.processor
+}
+
+func newSimulateBackend(t *testing.T) *simulateBackend {
+	chainDb := rawdb.NewMemoryDatabase()
+	chainCfg, dposCfg, _, err := blockchain.SetupGenesisBlock(chainDb, blockchain.DefaultGenesis())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := blockchain.NewBlockChain(chainDb, false, vm.Config{}, chainCfg, nil, 0, txpool.SenderCacher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := dpos.New(dposCfg, chain)
+	bc := struct {
+		*blockchain.BlockChain
+		consensus.IEngine
+	}{chain, engine}
+	return &simulateBackend{chain: chain, processor: processor.NewStateProcessor(&bc, engine), forkID: params.ForkID5}
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	backend := newSimulateBackend(t)
+	api := NewPublicBlockChainAPI(backend)
+	cfg := params.DefaultChainconfig
+	founder, user := common.Name(cfg.SysName), common.Name("simulateuser")
+	key, _ := crypto.GenerateKey()
+	data, err := rlp.EncodeToBytes(&accountmanager.CreateAccountAction{AccountName: user, PublicKey: common.BytesToPubKey(crypto.FromECDSAPub(&key.PublicKey))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An unsigned transaction creates an account and transfers to it
+	result, err := api.SimulateTransaction(context.Background(), SimulateArgs{
+		GasPrice: big.NewInt(1),
+		Actions: []SimulateActionArgs{
+			{ActionType: types.CreateAccount, From: founder, To: common.Name(cfg.AccountName), Gas: 1000000, Value: big.NewInt(0), Data: data},
+			{ActionType: types.Transfer, From: founder, To: user, Gas: 1000000, Value: big.NewInt(100)},
+		},
+	}, rpc.LatestBlockNumber, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Receipt.ActionResults) != 2 || len(result.DetailTx.Actions) != 2 {
+		t.Fatalf("results mismatch: %v %v", result.Receipt.ActionResults, result.DetailTx.Actions)
+	}
+	for i, r := range result.Receipt.ActionResults {
+		if r.Status != types.ReceiptStatusSuccessful || r.Payer != founder || r.GasUsed == 0 {
+			t.Fatalf("action %d result mismatch: %+v", i, r)
+		}
+	}
+
+	// Nothing is committed to the chain state
+	statedb, _, _ := backend.StateAndHeaderByNumber(context.Background(), rpc.LatestBlockNumber)
+	am, err := accountmanager.NewAccountManager(statedb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct, err := am.GetAccountByName(user); err != nil || acct != nil {
+		t.Fatalf("simulated account committed: %v %v", acct, err)
+	}
+	if nonce, _ := am.GetNonce(founder); nonce != 0 {
+		t.Fatalf("simulated nonce committed: %d", nonce)
+	}
+
+	// The fee payer pays the gas of the action
+	payer := common.Name(cfg.AssetName)
+	transfer := SimulateActionArgs{ActionType: types.Transfer, From: founder, To: common.Name(cfg.DposName), Gas: 1000000, Value: big.NewInt(1), Payer: payer, PayerGasPrice: big.NewInt(1)}
+	if _, err := api.SimulateTransaction(context.Background(), SimulateArgs{GasPrice: big.NewInt(0), Actions: []SimulateActionArgs{transfer}}, rpc.LatestBlockNumber, nil, nil); err == nil {
+		t.Fatal("simulated with a fee payer without balance")
+	}
+	overrides := &StateOverride{payer: OverrideAccount{Balances: map[uint64]*big.Int{cfg.SysTokenID: big.NewInt(1e18)}}}
+	result, err = api.SimulateTransaction(context.Background(), SimulateArgs{GasPrice: big.NewInt(0), Actions: []SimulateActionArgs{transfer}}, rpc.LatestBlockNumber, overrides, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.Receipt.ActionResults[0]; r.Status != types.ReceiptStatusSuccessful || r.Payer != payer || r.PayerGasPrice.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("fee payer result mismatch: %+v", r)
+	}
+
+	if _, err := api.SimulateTransaction(context.Background(), SimulateArgs{}, rpc.LatestBlockNumber, nil, nil); err == nil {
+		t.Fatal("simulated a transaction without actions")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package rpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/processor/vm"
+	"github.com/fractalplatform/fractal/rawdb"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// TxTraceResult is the call trace of a transaction, one top level frame per action.
+type TxTraceResult struct {
+	TxHash  common.Hash     `json:"txHash"`
+	Actions []*vm.CallFrame `json:"actions"`
+}
+
+// PrivateDebugAPI offers an API to trace the execution of transactions.
+type PrivateDebugAPI struct {
+	b Backend
+}
+
+// NewPrivateDebugAPI creates a new trace service.
+func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
+	return &PrivateDebugAPI{b}
+}
+
+// TraceTransaction re-executes the transaction on the state it was originally
+// executed on and returns its call trace.
+func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Hash) (*TxTraceResult, error) {
+	tx, blockHash, _, index := rawdb.ReadTransaction(api.b.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	block, err := api.b.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	results, err := api.traceBlock(ctx, block, int(index))
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// TraceBlockByNumber re-executes all transactions of the block and returns
+// their call traces.
+func (api *PrivateDebugAPI) TraceBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) ([]*TxTraceResult, error) {
+	block := api.b.BlockByNumber(ctx, blockNr)
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	return api.traceBlock(ctx, block, -1)
+}
+
+// traceBlock replays the transactions of the block on the parent state, the
+// transaction at txIndex or every transaction if txIndex is negative is traced.
+func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block, txIndex int) ([]*TxTraceResult, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis block is not traceable")
+	}
+	statedb, header, err := api.b.StateAtBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results []*TxTraceResult
+		usedGas = new(uint64)
+		gp      = new(common.GasPool).AddGas(block.GasLimit())
+	)
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		if txIndex >= 0 && i != txIndex {
+			if _, _, err := api.b.Processor().ApplyTransaction(nil, gp, statedb, header, tx, usedGas, vm.Config{}); err != nil {
+				return nil, fmt.Errorf("replay transaction %#x failed: %v", tx.Hash(), err)
+			}
+			continue
+		}
+
+		tracer := vm.NewCallTracer()
+		cfg := vm.Config{Debug: true, Tracer: tracer, ContractLogFlag: true}
+		if _, _, err := api.b.Processor().ApplyTransaction(nil, gp, statedb, header, tx, usedGas, cfg); err != nil {
+			return nil, fmt.Errorf("trace transaction %#x failed: %v", tx.Hash(), err)
+		}
+		results = append(results, &TxTraceResult{TxHash: tx.Hash(), Actions: tracer.Frames()})
+		if i == txIndex {
+			break
+		}
+	}
+	return results, nil
+}
@@ -78,3 +78,24 @@ func (api *API) BalanceByAssetID(name string, id uint64, typeID uint64) (*big.In
 	err := api.client.Call(balance, "account_getAccountBalanceByID", name, id, typeID)
 	return balance, err
 }
+
+// AssetHolders get asset holders sorted by balance
+func (api *API) AssetHolders(id uint64, cursor uint64, limit uint64) (*rpcapi.AssetHolderPage, error) {
+	page := &rpcapi.AssetHolderPage{}
+	err := api.client.Call(page, "account_getAssetHolders", id, cursor, limit)
+	return page, err
+}
+
+// AssetHoldersByTime get asset holders at the snapshot time sorted by balance
+func (api *API) AssetHoldersByTime(id uint64, time uint64, cursor uint64, limit uint64) (*rpcapi.AssetHolderPage, error) {
+	page := &rpcapi.AssetHolderPage{}
+	err := api.client.Call(page, "account_getAssetHoldersByTime", id, time, cursor, limit)
+	return page, err
+}
+
+// AssetHistory get asset issue, increase, destroy and update events
+func (api *API) AssetHistory(id uint64, cursor uint64, limit uint64) (*rpcapi.AssetEventPage, error) {
+	page := &rpcapi.AssetEventPage{}
+	err := api.client.Call(page, "account_getAssetHistory", id, cursor, limit)
+	return page, err
+}
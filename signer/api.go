@@ -0,0 +1,54 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+)
+
+// API exposes a signer for the RPC interface of an external signer.
+type API struct {
+	signer Signer
+}
+
+// PubKeys returns the public keys of the signer.
+func (api *API) PubKeys() ([]common.PubKey, error) {
+	return api.signer.PubKeys()
+}
+
+// SignHeader signs the header of the request.
+func (api *API) SignHeader(req *SignRequest) (hexutil.Bytes, error) {
+	header, err := req.DecodeHeader()
+	if err != nil {
+		return nil, err
+	}
+	return api.signer.SignHeader(req.PubKey, header)
+}
+
+// APIs provide the signer RPC API.
+func APIs(signer Signer) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "signer",
+			Version:   "1.0",
+			Service:   &API{signer: signer},
+			Public:    true,
+		},
+	}
+}
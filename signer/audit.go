@@ -0,0 +1,95 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// AuditEntry a sign request recorded in the audit log
+type AuditEntry struct {
+	Time      string        `json:"time"`
+	PubKey    common.PubKey `json:"publicKey"`
+	Coinbase  string        `json:"coinbase"`
+	Number    uint64        `json:"number"`
+	Timestamp uint64        `json:"timestamp"`
+	Hash      common.Hash   `json:"hash"`
+	Signed    bool          `json:"signed"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// AuditLog appends every sign request as a JSON line to the writer.
+type AuditLog struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditLog creates an audit log appending to the file.
+func NewAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{w: f}, nil
+}
+
+// NewAuditLogWriter creates an audit log appending to the writer.
+func NewAuditLogWriter(w io.Writer) *AuditLog {
+	return &AuditLog{w: w}
+}
+
+// Log records the result of a sign request.
+func (a *AuditLog) Log(pubKey common.PubKey, header *types.Header, hash common.Hash, err error) {
+	entry := &AuditEntry{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		PubKey:    pubKey,
+		Coinbase:  header.Coinbase.String(),
+		Number:    header.Number.Uint64(),
+		Timestamp: header.Time.Uint64(),
+		Hash:      hash,
+		Signed:    err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	bts, merr := json.Marshal(entry)
+	if merr != nil {
+		log.Error("audit log", "err", merr)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, werr := a.w.Write(append(bts, '\n')); werr != nil {
+		log.Error("audit log", "err", werr)
+	}
+}
+
+// Close closes the underlying writer if it is closable.
+func (a *AuditLog) Close() error {
+	if c, ok := a.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/fractalplatform/fractal/common"
+)
+
+// maxSignedHeaders max signed headers remembered of each public key
+const maxSignedHeaders = 1024
+
+var (
+	// ErrDoubleSign is returned when another header was signed at the same slot.
+	ErrDoubleSign = errors.New("refuse to sign another header at the same slot")
+	// ErrStaleHeader is returned when the header is not after the pruned slot.
+	ErrStaleHeader = errors.New("refuse to sign header before the protected slot")
+)
+
+// SignedHeader a header signed by the key
+type SignedHeader struct {
+	Number    uint64      `json:"number"`
+	Timestamp uint64      `json:"timestamp"`
+	Hash      common.Hash `json:"hash"`
+}
+
+// signedHeaders headers signed by the key, Pruned is the latest slot forgotten
+type signedHeaders struct {
+	Pruned  uint64          `json:"prunedTimestamp"`
+	Headers []*SignedHeader `json:"headers"`
+}
+
+// Protection remembers the headers signed by each key and refuses to sign a
+// different header at the same slot, a header at the height of a signed
+// header but at another slot is signed after a reorg. Records are kept in
+// the file when path is not empty, so that the protection survives restarts.
+type Protection struct {
+	path string
+
+	mu      sync.Mutex
+	records map[common.PubKey]*signedHeaders
+}
+
+// NewProtection loads the protection records of the file.
+func NewProtection(path string) (*Protection, error) {
+	p := &Protection{
+		path:    path,
+		records: make(map[common.PubKey]*signedHeaders),
+	}
+	if path == "" {
+		return p, nil
+	}
+	bts, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bts, &p.records); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Check checks the header can be signed by the key and records it.
+func (p *Protection) Check(pubKey common.PubKey, number uint64, timestamp uint64, hash common.Hash) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	record, ok := p.records[pubKey]
+	if !ok {
+		record = &signedHeaders{}
+	}
+	if timestamp <= record.Pruned {
+		return ErrStaleHeader
+	}
+	for _, header := range record.Headers {
+		if header.Timestamp != timestamp {
+			continue
+		}
+		if header.Hash != hash {
+			return ErrDoubleSign
+		}
+		// signing the same header again is harmless
+		return nil
+	}
+
+	nrecord := &signedHeaders{Pruned: record.Pruned}
+	nrecord.Headers = append(append(nrecord.Headers, record.Headers...), &SignedHeader{Number: number, Timestamp: timestamp, Hash: hash})
+	sort.Slice(nrecord.Headers, func(i, j int) bool { return nrecord.Headers[i].Timestamp < nrecord.Headers[j].Timestamp })
+	if len(nrecord.Headers) > maxSignedHeaders {
+		pruned := nrecord.Headers[:len(nrecord.Headers)-maxSignedHeaders]
+		nrecord.Pruned = pruned[len(pruned)-1].Timestamp
+		nrecord.Headers = nrecord.Headers[len(pruned):]
+	}
+	p.records[pubKey] = nrecord
+	if err := p.save(); err != nil {
+		// not signed unless recorded
+		if ok {
+			p.records[pubKey] = record
+		} else {
+			delete(p.records, pubKey)
+		}
+		return err
+	}
+	return nil
+}
+
+// SignedHeaders returns the remembered headers signed by the key.
+func (p *Protection) SignedHeaders(pubKey common.PubKey) []*SignedHeader {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if record, ok := p.records[pubKey]; ok {
+		return append([]*SignedHeader{}, record.Headers...)
+	}
+	return nil
+}
+
+// save writes the records to a temporary file and renames it over the file.
+func (p *Protection) save() error {
+	if p.path == "" {
+		return nil
+	}
+	bts, err := json.Marshal(p.records)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p.path), "."+filepath.Base(p.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	return os.Rename(tmp.Name(), p.path)
+}
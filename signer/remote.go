@@ -0,0 +1,85 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+)
+
+// remoteTimeout timeout of a request to the remote signer
+const remoteTimeout = 2 * time.Second
+
+// RemoteSigner forwards sign requests to an external signer.
+type RemoteSigner struct {
+	endpoint string
+	client   *rpc.Client
+}
+
+// NewRemoteSigner connects to the signer at the IPC path or HTTP url.
+func NewRemoteSigner(endpoint string) (*RemoteSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{endpoint: endpoint, client: client}, nil
+}
+
+// Endpoint returns the endpoint of the signer.
+func (signer *RemoteSigner) Endpoint() string {
+	return signer.endpoint
+}
+
+// PubKeys returns the public keys of the remote signer.
+func (signer *RemoteSigner) PubKeys() ([]common.PubKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	var pubKeys []common.PubKey
+	if err := signer.client.CallContext(ctx, &pubKeys, "signer_pubKeys"); err != nil {
+		return nil, err
+	}
+	return pubKeys, nil
+}
+
+// SignHeader requests the remote signer to sign the header, the returned
+// signature is checked against the key.
+func (signer *RemoteSigner) SignHeader(pubKey common.PubKey, header *types.Header) ([]byte, error) {
+	req, err := NewSignRequest(pubKey, header)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	var sig hexutil.Bytes
+	if err := signer.client.CallContext(ctx, &sig, "signer_signHeader", req); err != nil {
+		return nil, err
+	}
+	if err := verifySignature(pubKey, header, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// Close closes the connection to the signer.
+func (signer *RemoteSigner) Close() {
+	signer.client.Close()
+}
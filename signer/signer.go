@@ -0,0 +1,167 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+// Package signer implements block header signers of the producer.
+//
+// KeySigner signs with keys held in process, RemoteSigner forwards the
+// requests to an external signer over IPC or HTTP JSON-RPC. An external signer
+// serves the "signer" namespace:
+//
+//	signer_pubKeys()          returns the public keys it can sign for
+//	signer_signHeader(req)    returns the 65 bytes seal signature
+//
+// where req is {"publicKey": "0x04...", "header": "0x<rlp of block header>"}.
+// The signer derives the seal hash, height and slot from the header itself,
+// refuses to sign a second header of a public key at the same slot
+// (see Protection) and records every request in the audit log.
+package signer
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+// extraSeal fixed number of extra-data suffix bytes reserved for signer seal
+const extraSeal = 65
+
+var (
+	// ErrUnknownKey is returned when the signer doesn't hold the public key.
+	ErrUnknownKey = errors.New("unknown public key")
+	// ErrInvalidHeader is returned when the header has no room for the seal.
+	ErrInvalidHeader = errors.New("invalid header extra")
+)
+
+// Signer signs the seal hash of block headers.
+type Signer interface {
+	// PubKeys returns the public keys the signer can sign for.
+	PubKeys() ([]common.PubKey, error)
+	// SignHeader returns the seal signature of the header by the key.
+	SignHeader(pubKey common.PubKey, header *types.Header) ([]byte, error)
+}
+
+// SignRequest is the request of signer_signHeader.
+type SignRequest struct {
+	PubKey common.PubKey `json:"publicKey"`
+	Header hexutil.Bytes `json:"header"`
+}
+
+// NewSignRequest encodes the header into a sign request.
+func NewSignRequest(pubKey common.PubKey, header *types.Header) (*SignRequest, error) {
+	bts, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	return &SignRequest{PubKey: pubKey, Header: bts}, nil
+}
+
+// DecodeHeader decodes the header of the request.
+func (req *SignRequest) DecodeHeader() (*types.Header, error) {
+	header := &types.Header{}
+	if err := rlp.DecodeBytes(req.Header, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// SealHash returns the hash signed by the producer, the header without seal.
+func SealHash(header *types.Header) (common.Hash, error) {
+	if len(header.Extra) < extraSeal {
+		return common.Hash{}, ErrInvalidHeader
+	}
+	theader := types.CopyHeader(header)
+	theader.Extra = theader.Extra[:len(theader.Extra)-extraSeal]
+	return theader.Hash(), nil
+}
+
+// KeySigner signs headers with private keys held in process.
+type KeySigner struct {
+	keys       map[common.PubKey]*ecdsa.PrivateKey
+	pubKeys    []common.PubKey
+	protection *Protection
+	audit      *AuditLog
+}
+
+// NewKeySigner creates a signer of the keys, protection and audit are optional.
+func NewKeySigner(privKeys []*ecdsa.PrivateKey, protection *Protection, audit *AuditLog) *KeySigner {
+	signer := &KeySigner{
+		keys:       make(map[common.PubKey]*ecdsa.PrivateKey),
+		protection: protection,
+		audit:      audit,
+	}
+	for _, privKey := range privKeys {
+		pubKey := common.BytesToPubKey(crypto.FromECDSAPub(&privKey.PublicKey))
+		if _, ok := signer.keys[pubKey]; !ok {
+			signer.pubKeys = append(signer.pubKeys, pubKey)
+		}
+		signer.keys[pubKey] = privKey
+	}
+	return signer
+}
+
+// PubKeys returns the public keys of the signer.
+func (signer *KeySigner) PubKeys() ([]common.PubKey, error) {
+	return signer.pubKeys, nil
+}
+
+// SignHeader signs the seal hash of the header.
+func (signer *KeySigner) SignHeader(pubKey common.PubKey, header *types.Header) ([]byte, error) {
+	sig, hash, err := signer.signHeader(pubKey, header)
+	if signer.audit != nil {
+		signer.audit.Log(pubKey, header, hash, err)
+	}
+	return sig, err
+}
+
+func (signer *KeySigner) signHeader(pubKey common.PubKey, header *types.Header) ([]byte, common.Hash, error) {
+	privKey, ok := signer.keys[pubKey]
+	if !ok {
+		return nil, common.Hash{}, ErrUnknownKey
+	}
+	hash, err := SealHash(header)
+	if err != nil {
+		return nil, hash, err
+	}
+	if signer.protection != nil {
+		if err := signer.protection.Check(pubKey, header.Number.Uint64(), header.Time.Uint64(), hash); err != nil {
+			return nil, hash, err
+		}
+	}
+	sig, err := crypto.Sign(hash.Bytes(), privKey)
+	return sig, hash, err
+}
+
+// verifySignature checks the signature of the header is signed by the key.
+func verifySignature(pubKey common.PubKey, header *types.Header, sig []byte) error {
+	hash, err := SealHash(header)
+	if err != nil {
+		return err
+	}
+	rpubKey, err := crypto.Ecrecover(hash.Bytes(), sig)
+	if err != nil {
+		return err
+	}
+	if common.BytesToPubKey(rpubKey).Compare(pubKey) != 0 {
+		return fmt.Errorf("signature not match %v", pubKey.String())
+	}
+	return nil
+}
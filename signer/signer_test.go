@@ -0,0 +1,173 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/crypto"
+	"github.com/fractalplatform/fractal/rpc"
+	"github.com/fractalplatform/fractal/types"
+)
+
+func testHeader(number, timestamp uint64, extra string) *types.Header {
+	return &types.Header{
+		Coinbase: common.Name("producer"),
+		Number:   new(big.Int).SetUint64(number),
+		Time:     new(big.Int).SetUint64(timestamp),
+		Extra:    append([]byte(extra), make([]byte, extraSeal)...),
+	}
+}
+
+func testKey(t *testing.T) (*ecdsa.PrivateKey, common.PubKey) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privKey, common.BytesToPubKey(crypto.FromECDSAPub(&privKey.PublicKey))
+}
+
+func TestKeySigner(t *testing.T) {
+	privKey, pubKey := testKey(t)
+	p, _ := NewProtection("")
+	audit := &bytes.Buffer{}
+	signer := NewKeySigner([]*ecdsa.PrivateKey{privKey}, p, NewAuditLogWriter(audit))
+
+	header := testHeader(10, 100, "a")
+	sig, err := signer.SignHeader(pubKey, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySignature(pubKey, header, sig); err != nil {
+		t.Fatal(err)
+	}
+	// the same header again
+	if _, err := signer.SignHeader(pubKey, header); err != nil {
+		t.Fatal(err)
+	}
+	// another header at the same height in a later slot after a reorg
+	if _, err := signer.SignHeader(pubKey, testHeader(10, 103, "b")); err != nil {
+		t.Fatal(err)
+	}
+	// another header at the same slot
+	if _, err := signer.SignHeader(pubKey, testHeader(11, 100, "b")); err != ErrDoubleSign {
+		t.Fatalf("same slot: have %v, want %v", err, ErrDoubleSign)
+	}
+	if _, err := signer.SignHeader(pubKey, testHeader(11, 106, "b")); err != nil {
+		t.Fatal(err)
+	}
+	_, unknown := testKey(t)
+	if _, err := signer.SignHeader(unknown, testHeader(12, 109, "c")); err != ErrUnknownKey {
+		t.Fatalf("unknown key: have %v, want %v", err, ErrUnknownKey)
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("audit entries: have %v, want %v", len(lines), 6)
+	}
+	if !strings.Contains(lines[2], `"signed":true`) || !strings.Contains(lines[3], `"signed":false`) {
+		t.Fatalf("audit entries mismatch %v", lines)
+	}
+}
+
+func TestProtection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ft-signer-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "protection.json")
+
+	_, pubKey := testKey(t)
+	p, err := NewProtection(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= maxSignedHeaders+2; i++ {
+		if err := p.Check(pubKey, i, i*3, common.BigToHash(new(big.Int).SetUint64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(p.SignedHeaders(pubKey)) != maxSignedHeaders {
+		t.Fatalf("signed headers: have %v, want %v", len(p.SignedHeaders(pubKey)), maxSignedHeaders)
+	}
+
+	// reload from file
+	p, err = NewProtection(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(pubKey, 2, 2*3, common.BigToHash(big.NewInt(2))); err != ErrStaleHeader {
+		t.Fatalf("pruned height: have %v, want %v", err, ErrStaleHeader)
+	}
+	if err := p.Check(pubKey, 10, 10*3, common.HexToHash("0x01")); err != ErrDoubleSign {
+		t.Fatalf("reload: have %v, want %v", err, ErrDoubleSign)
+	}
+	if err := p.Check(pubKey, 10, 10*3, common.BigToHash(big.NewInt(10))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ft-signer-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	endpoint := filepath.Join(dir, "signer.ipc")
+
+	privKey, pubKey := testKey(t)
+	p, _ := NewProtection("")
+	listener, handler, err := rpc.StartIPCEndpoint(endpoint, APIs(NewKeySigner([]*ecdsa.PrivateKey{privKey}, p, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Stop()
+	defer listener.Close()
+
+	remote, err := NewRemoteSigner(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	if pubKeys, err := remote.PubKeys(); err != nil {
+		t.Fatal(err)
+	} else if len(pubKeys) != 1 || pubKeys[0] != pubKey {
+		t.Fatalf("public keys mismatch %v", pubKeys)
+	}
+	header := testHeader(10, 100, "a")
+	sig, err := remote.SignHeader(pubKey, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _ := SealHash(header)
+	if want, _ := crypto.Sign(hash.Bytes(), privKey); !bytes.Equal(sig, want) {
+		t.Fatal("signature mismatch")
+	}
+	if _, err := remote.SignHeader(pubKey, testHeader(11, 100, "b")); err == nil || err.Error() != ErrDoubleSign.Error() {
+		t.Fatalf("double sign: have %v, want %v", err, ErrDoubleSign)
+	}
+}
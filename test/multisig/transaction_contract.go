@@ -187,7 +187,7 @@ func addAuthorsForAca() {
 	authorAction := make([]*accountmanager.AuthorAction, 0)
 	authorAction = append(authorAction, a_authorAct_0, a_authorAct_1, a_authorAct_2, a_authorAct_3)
 
-	action := &accountmanager.AccountAuthorAction{1000, 0, authorAction}
+	action := &accountmanager.AccountAuthorAction{1000, 0, authorAction, nil}
 	input, err := rlp.EncodeToBytes(action)
 	if err != nil {
 		jww.INFO.Println("addAuthors for accounta error ... ", err)
@@ -210,7 +210,7 @@ func addAuthorsForAcb() {
 	b_author_2 := common.NewAuthor(b_author_2_addr, 40)
 	b_authorAct_2 := &accountmanager.AuthorAction{0, b_author_2}
 
-	action := &accountmanager.AccountAuthorAction{100, 0, []*accountmanager.AuthorAction{b_authorAct_0, b_authorAct_1, b_authorAct_2}}
+	action := &accountmanager.AccountAuthorAction{100, 0, []*accountmanager.AuthorAction{b_authorAct_0, b_authorAct_1, b_authorAct_2}, nil}
 	input, err := rlp.EncodeToBytes(action)
 	if err != nil {
 		jww.INFO.Println("addAuthors for accountb error ... ", err)
@@ -234,7 +234,7 @@ func addAuthorsForAcc() {
 	c_author_2 := common.NewAuthor(c_author_2_pub, 4)
 	c_authorAct_2 := &accountmanager.AuthorAction{0, c_author_2}
 
-	action := &accountmanager.AccountAuthorAction{10, 0, []*accountmanager.AuthorAction{c_authorAct_0, c_authorAct_1, c_authorAct_2}}
+	action := &accountmanager.AccountAuthorAction{10, 0, []*accountmanager.AuthorAction{c_authorAct_0, c_authorAct_1, c_authorAct_2}, nil}
 	input, err := rlp.EncodeToBytes(action)
 	if err != nil {
 		jww.INFO.Println("addAuthors for accountc error ... ", err)
@@ -267,7 +267,7 @@ func modifyAUpdateAUthorThreshold() {
 	key_1_2 := types.MakeKeyPair(b_author_2_priv, []uint64{1, 2})
 	key_0 := types.MakeKeyPair(a_author_0_priv, []uint64{0})
 
-	action := &accountmanager.AccountAuthorAction{0, 2, []*accountmanager.AuthorAction{}}
+	action := &accountmanager.AccountAuthorAction{0, 2, []*accountmanager.AuthorAction{}, nil}
 	input, err := rlp.EncodeToBytes(action)
 	if err != nil {
 		jww.INFO.Println("addAuthors for accountc error ... ", err)
@@ -286,7 +286,7 @@ func TestTransactionQueue(t *testing.T) {
 	auther := common.NewAuthor(tpubkey, 1)
 	authorAction := &am.AuthorAction{ActionType: am.AddAuthor, Author: auther}
 	acctAuth := &am.AccountAuthorAction{AuthorActions: []*am.AuthorAction{authorAction}}
-	if err := pool.curAccountManager.UpdateAccountAuthor(fname, acctAuth); err != nil {
+	if err := pool.curAccountManager.UpdateAccountAuthor(fname, acctAuth, 0); err != nil {
 		t.Fatal(err)
 	}
 
@@ -295,7 +295,7 @@ func TestTransactionQueue(t *testing.T) {
 	auther = common.NewAuthor(fpubkey, 1)
 	authorAction = &am.AuthorAction{ActionType: am.DeleteAuthor, Author: auther}
 	acctAuth = &am.AccountAuthorAction{AuthorActions: []*am.AuthorAction{authorAction}}
-	if err := pool.curAccountManager.UpdateAccountAuthor(fname, acctAuth); err != nil {
+	if err := pool.curAccountManager.UpdateAccountAuthor(fname, acctAuth, 0); err != nil {
 		t.Fatal(err)
 	}
 
@@ -24,6 +24,7 @@ import (
 	"github.com/fractalplatform/fractal/accountmanager"
 	"github.com/fractalplatform/fractal/params"
 	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
 )
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
@@ -73,16 +74,9 @@ func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action
 		return 0
 	}
 
-	var gas uint64
-
-	if action.Type() == types.CreateContract || action.Type() == types.CreateAccount {
-		gas += gasTable.ActionGasCreation
-	} else if action.Type() == types.IssueAsset {
-		gas += gasTable.ActionGasIssueAsset
-	} else if action.Type() == types.CallContract {
-		gas += gasTable.ActionGasCallContract
-	} else {
-		gas += gasTable.ActionGas
+	gas, err := actionTypeGas(action.Type(), action.Data(), 0)
+	if err != nil {
+		return 0, err
 	}
 
 	dataGas, err := dataGasFunc(action.Data())
@@ -91,6 +85,17 @@ func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action
 	}
 	gas += dataGas
 
+	// the proposed action is paid when it is proposed, an approval executing
+	// it doesn't know the action up front
+	if action.Type() == types.ProposeAccountAction {
+		var propose accountmanager.ProposeAction
+		if err := rlp.DecodeBytes(action.Data(), &propose); err == nil {
+			if gas, err = actionTypeGas(propose.Type, propose.Payload, gas); err != nil {
+				return 0, err
+			}
+		}
+	}
+
 	remarkGas, err := dataGasFunc(action.Remark())
 	if err != nil {
 		return 0, err
@@ -110,6 +115,34 @@ func IntrinsicGas(accountDB *accountmanager.AccountManager, action *types.Action
 	return gas, nil
 }
 
+// actionTypeGas adds the gas of the action type to gas, asset metadata is kept
+// in the state so its payload is priced as storage.
+func actionTypeGas(actionType types.ActionType, data []byte, gas uint64) (uint64, error) {
+	gasTable := params.GasTableInstance
+	var typeGas uint64
+	if actionType == types.CreateContract || actionType == types.CreateAccount {
+		typeGas = gasTable.ActionGasCreation
+	} else if actionType == types.IssueAsset || actionType == types.IssueNFTCollection {
+		typeGas = gasTable.ActionGasIssueAsset
+	} else if actionType == types.CallContract {
+		typeGas = gasTable.ActionGasCallContract
+	} else {
+		typeGas = gasTable.ActionGas
+	}
+	if math.MaxUint64-gas < typeGas {
+		return 0, ErrOutOfGas
+	}
+	gas += typeGas
+
+	if actionType == types.SetAssetMetadata {
+		if (math.MaxUint64-gas)/gasTable.AssetMetadataByteGas < uint64(len(data)) {
+			return 0, ErrOutOfGas
+		}
+		gas += uint64(len(data)) * gasTable.AssetMetadataByteGas
+	}
+	return gas, nil
+}
+
 func printLog(level log.Lvl) {
 	glogger := log.NewGlogHandler(log.StreamHandler(os.Stdout, log.TerminalFormat(false)))
 	glogger.Verbosity(level)
@@ -0,0 +1,59 @@
+// Copyright 2018 The Fractal Team Authors
+// This file is part of the fractal project.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/fractalplatform/fractal/accountmanager"
+	"github.com/fractalplatform/fractal/common"
+	"github.com/fractalplatform/fractal/params"
+	"github.com/fractalplatform/fractal/types"
+	"github.com/fractalplatform/fractal/utils/rlp"
+)
+
+func TestIntrinsicGasProposal(t *testing.T) {
+	gasTable := params.GasTableInstance
+	payload := bytes.Repeat([]byte{1}, 32)
+	actionGas := func(actionType types.ActionType, data []byte) uint64 {
+		action := types.NewAction(actionType, common.Name("multisigacct"), common.Name("fractal.account"), 0, 0, 0, big.NewInt(0), data, nil)
+		gas, err := IntrinsicGas(nil, action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gas
+	}
+
+	// the proposal pays the wrapped action type on top of its own gas
+	for actionType, want := range map[types.ActionType]uint64{
+		types.Transfer:           gasTable.ActionGas,
+		types.IssueAsset:         gasTable.ActionGasIssueAsset,
+		types.CreateAccount:      gasTable.ActionGasCreation,
+		types.IssueNFTCollection: gasTable.ActionGasIssueAsset,
+		types.SetAssetMetadata:   gasTable.ActionGas + uint64(len(payload))*gasTable.AssetMetadataByteGas,
+	} {
+		data, err := rlp.EncodeToBytes(&accountmanager.ProposeAction{Type: actionType, Value: big.NewInt(0), Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := actionGas(types.ProposeAccountAction, data) - actionGas(types.Transfer, data); got != want {
+			t.Fatalf("proposed action %d gas mismatch, want %d, got %d", actionType, want, got)
+		}
+	}
+}
@@ -51,6 +51,34 @@ const (
 	DeleteAccount
 	// UpdateAccountAuthor represents the update account author.
 	UpdateAccountAuthor
+	// SetAccountAuthorDelay represents set the delay of the account author changes.
+	SetAccountAuthorDelay
+	// CancelAccountAuthor represents cancel a queued account author change.
+	CancelAccountAuthor
+	// ProposeAccountAction represents propose an action of a multisig account.
+	ProposeAccountAction
+	// ApproveAccountAction represents approve a proposed action of a multisig account.
+	ApproveAccountAction
+	// ApproveAllowance represents approve a spender to transfer asset of the account.
+	ApproveAllowance
+	// TransferFrom represents transfer asset of another account within the allowance.
+	TransferFrom
+	// LockHTLC represents lock asset under a hash and a timeout.
+	LockHTLC
+	// ClaimHTLC represents claim locked asset with the preimage of the hash.
+	ClaimHTLC
+	// RefundHTLC represents refund expired locked asset to the sender.
+	RefundHTLC
+	// DistributeDividend represents distribute asset to the holders of an asset at a snapshot.
+	DistributeDividend
+	// ClaimDividend represents claim the share of a dividend.
+	ClaimDividend
+	// AtomicSwap represents exchange assets with an account which signed the swap order.
+	AtomicSwap
+	// CancelSwapOrder represents cancel the swap orders signed by the account with a nonce.
+	CancelSwapOrder
+	// ReclaimDividend represents reclaim the outstanding amount of an expired dividend.
+	ReclaimDividend
 )
 
 const (
@@ -67,6 +95,26 @@ const (
 	// Transfer repesents transfer asset action.
 	Transfer
 	UpdateAssetContract
+	// FreezeAsset represents freeze or unfreeze the asset.
+	FreezeAsset
+	// FreezeAccountAsset represents freeze or unfreeze the asset balance of an account.
+	FreezeAccountAsset
+	// VestingTransfer represents transfer asset locked by a vesting schedule.
+	VestingTransfer
+	// IssueNFTCollection represents issue a non-fungible token collection.
+	IssueNFTCollection
+	// MintNFT represents mint a token of a non-fungible token collection.
+	MintNFT
+	// TransferNFT represents transfer a non-fungible token.
+	TransferNFT
+	// BurnNFT represents burn a non-fungible token.
+	BurnNFT
+	// SetAssetMetadata represents set the key/value metadata of an asset.
+	SetAssetMetadata
+	// SetSubAssetPolicy represents set who can issue sub assets of an asset.
+	SetSubAssetPolicy
+	// RejectVestingTransfer represents return the vesting schedules sent by the recipient.
+	RejectVestingTransfer
 )
 
 const (
@@ -83,6 +131,10 @@ const (
 
 	// UpdateCandidatePubKey repesents update candidate action.
 	UpdateCandidatePubKey
+	// ReportDoubleSign represents report a candidate signed two blocks for the same slot.
+	ReportDoubleSign
+	// ClaimVoterReward represents claim the block rewards shared by a candidate to its voters.
+	ClaimVoterReward
 )
 
 const (
@@ -180,6 +232,11 @@ func (a *Action) GetSignParent() uint64 {
 	return a.data.Sign.ParentIndex
 }
 
+// SetFeePayer sets the fee payer of the action without a payer signature.
+func (a *Action) SetFeePayer(fp *FeePayer) {
+	a.fp = fp
+}
+
 func (a *Action) PayerIsExist() bool {
 	return a.fp != nil
 }
@@ -222,6 +279,37 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 		}
 	case CallContract:
 	//account
+	case ApproveAllowance:
+		fallthrough
+	case TransferFrom:
+		fallthrough
+	case LockHTLC:
+		fallthrough
+	case ClaimHTLC:
+		fallthrough
+	case RefundHTLC:
+		fallthrough
+	case DistributeDividend:
+		fallthrough
+	case ClaimDividend:
+		fallthrough
+	case ReclaimDividend:
+		fallthrough
+	case AtomicSwap:
+		fallthrough
+	case CancelSwapOrder:
+		fallthrough
+	case ProposeAccountAction:
+		fallthrough
+	case ApproveAccountAction:
+		fallthrough
+	case SetAccountAuthorDelay:
+		fallthrough
+	case CancelAccountAuthor:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		fallthrough
 	case CreateAccount:
 		fallthrough
 	case UpdateAccount:
@@ -233,6 +321,23 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 			return fmt.Errorf("Receipt should is %v", conf.AccountName)
 		}
 	//asset
+	case IssueNFTCollection:
+		fallthrough
+	case MintNFT:
+		fallthrough
+	case BurnNFT:
+		fallthrough
+	case SetAssetMetadata:
+		fallthrough
+	case SetSubAssetPolicy:
+		fallthrough
+	case FreezeAsset:
+		fallthrough
+	case FreezeAccountAsset:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		fallthrough
 	case IncreaseAsset:
 		fallthrough
 	case IssueAsset:
@@ -247,8 +352,23 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 		if a.data.To.String() != conf.AssetName {
 			return fmt.Errorf("Receipt should is %v", conf.AssetName)
 		}
+	case TransferNFT:
+		fallthrough
+	case RejectVestingTransfer:
+		fallthrough
+	case VestingTransfer:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
 	case Transfer:
 		//dpos
+	case ClaimVoterReward:
+		fallthrough
+	case ReportDoubleSign:
+		if fid < params.ForkID5 {
+			return fmt.Errorf("Receipt undefined")
+		}
+		fallthrough
 	case UpdateCandidatePubKey:
 		if fid < params.ForkID4 {
 			return fmt.Errorf("Receipt undefined")
@@ -287,6 +407,12 @@ func (a *Action) Check(fid uint64, conf *params.ChainConfig) error {
 		fallthrough
 	case Transfer:
 		fallthrough
+	case VestingTransfer:
+		fallthrough
+	case LockHTLC:
+		fallthrough
+	case DistributeDividend:
+		fallthrough
 	case CreateAccount:
 		fallthrough
 	case DestroyAsset:
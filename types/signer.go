@@ -130,6 +130,23 @@ func RecoverPayerMultiKey(signer Signer, a *Action, tx *Transaction) ([]common.P
 	return pubKeys, nil
 }
 
+// SignHashWithMultiKey signs the hash with every key in the signature layout of the actions.
+func SignHashWithMultiKey(h common.Hash, s Signer, parentIndex uint64, keys []*KeyPair) (*Signature, error) {
+	sign := &Signature{ParentIndex: parentIndex}
+	for _, key := range keys {
+		sig, err := crypto.Sign(h[:], key.priv)
+		if err != nil {
+			return nil, err
+		}
+		R, S, V, err := s.SignatureValues(sig)
+		if err != nil {
+			return nil, err
+		}
+		sign.SignData = append(sign.SignData, &SignData{V: V, R: R, S: S, Index: key.index})
+	}
+	return sign, nil
+}
+
 func StoreAuthorCache(a *Action, authorVersion map[common.Name]common.Hash) {
 	a.author.Store(authorVersion)
 }
@@ -208,6 +225,24 @@ func (s Signer) PayerPubKeys(a *Action, tx *Transaction) ([]common.PubKey, error
 	return pubKeys, nil
 }
 
+// HashPubKeys recovers the public keys of a signature of the hash.
+func (s Signer) HashPubKeys(h common.Hash, sign *Signature) ([]common.PubKey, error) {
+	if sign == nil || len(sign.SignData) == 0 {
+		return nil, ErrSignEmpty
+	}
+	var pubKeys []common.PubKey
+	for _, data := range sign.SignData {
+		V := new(big.Int).Sub(data.V, s.chainIDMul)
+		V.Sub(V, big8)
+		pub, err := recoverPlain(h, data.R, data.S, V)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, common.BytesToPubKey(pub))
+	}
+	return pubKeys, nil
+}
+
 // SignatureValues returns a new transaction with the given signature. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (s Signer) SignatureValues(sig []byte) (R, S, V *big.Int, err error) {
@@ -17,11 +17,15 @@
 package memdb
 
 import (
+	"bytes"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/fractalplatform/fractal/common"
 	"github.com/fractalplatform/fractal/utils/fdb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
 var (
@@ -85,6 +89,23 @@ func (db *MemDatabase) Keys() [][]byte {
 	return keys
 }
 
+// NewIteratorWithPrefix returns an iterator over a snapshot of the database
+// content with a particular prefix.
+func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	snapshot := &kvArray{}
+	for key, value := range db.db {
+		if strings.HasPrefix(key, string(prefix)) {
+			snapshot.keys = append(snapshot.keys, []byte(key))
+			snapshot.values = append(snapshot.values, common.CopyBytes(value))
+		}
+	}
+	sort.Sort(snapshot)
+	return iterator.NewArrayIterator(snapshot)
+}
+
 func (db *MemDatabase) Delete(key []byte) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
@@ -113,6 +134,25 @@ func (db *MemDatabase) Len() int { return len(db.db) }
 
 type kv struct{ k, v []byte }
 
+// kvArray is a sorted key/value array implementing iterator.Array.
+type kvArray struct {
+	keys   [][]byte
+	values [][]byte
+}
+
+func (a *kvArray) Len() int           { return len(a.keys) }
+func (a *kvArray) Less(i, j int) bool { return bytes.Compare(a.keys[i], a.keys[j]) < 0 }
+func (a *kvArray) Swap(i, j int) {
+	a.keys[i], a.keys[j] = a.keys[j], a.keys[i]
+	a.values[i], a.values[j] = a.values[j], a.values[i]
+}
+
+func (a *kvArray) Search(key []byte) int {
+	return sort.Search(len(a.keys), func(i int) bool { return bytes.Compare(a.keys[i], key) >= 0 })
+}
+
+func (a *kvArray) Index(i int) ([]byte, []byte) { return a.keys[i], a.values[i] }
+
 type memBatch struct {
 	db     *MemDatabase
 	writes []kv